@@ -0,0 +1,83 @@
+//go:build cgo
+
+// Command libviesquery builds viesquery as a C shared library
+// (go build -buildmode=c-shared, see `make c-shared`) for ERP plugins in PHP,
+// Python, .NET and other languages with a C FFI. Results use the JSON
+// envelope documented in docs/json-output.md.
+//
+//	char *viesquery_check_vat(char *vat_number, int timeout_seconds);
+//	char *viesquery_validate_format(char *vat_number);
+//	void viesquery_free(char *s);
+//
+// Every non-NULL string returned is allocated with malloc and must be
+// released with viesquery_free.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"time"
+	"unsafe"
+
+	"l22.io/viesquery/pkg/output"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Version is set during build time
+var Version = "dev"
+
+// viesquery_check_vat checks a VAT number against VIES and returns the JSON
+// envelope with either the result or the error. A timeout of 0 or less uses
+// the client default of 30 seconds.
+//
+//export viesquery_check_vat
+func viesquery_check_vat(vatNumber *C.char, timeoutSeconds C.int) *C.char {
+	options := []vies.ClientOption{vies.WithUserAgent("viesquery/" + Version)}
+	if timeoutSeconds > 0 {
+		options = append(options, vies.WithTimeout(time.Duration(timeoutSeconds)*time.Second))
+	}
+	client := vies.NewClient(options...)
+
+	formatter := output.NewJSONFormatter()
+	// A panic must not unwind into the host process
+	result, err := vies.CheckRecovered(context.Background(), client, C.GoString(vatNumber))
+	if err != nil {
+		return formatJSON(formatter.FormatError(err))
+	}
+	return formatJSON(formatter.Format(result))
+}
+
+// viesquery_validate_format checks the format and check digits of a VAT
+// number offline. It returns NULL if the number is well-formed, and the JSON
+// error envelope otherwise.
+//
+//export viesquery_validate_format
+func viesquery_validate_format(vatNumber *C.char) *C.char {
+	err := vies.ValidateFormat(C.GoString(vatNumber))
+	if err == nil {
+		return nil
+	}
+	return formatJSON(output.NewJSONFormatter().FormatError(err))
+}
+
+// viesquery_free releases a string returned by this library
+//
+//export viesquery_free
+func viesquery_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// formatJSON copies formatted output to C memory
+func formatJSON(out string, err error) *C.char {
+	if err != nil {
+		out = `{"schemaVersion":"` + output.SchemaVersion + `","error":{"message":"formatting failed"}}`
+	}
+	return C.CString(out)
+}
+
+// main is required by -buildmode=c-shared but never runs
+func main() {}
@@ -0,0 +1,99 @@
+//go:build js && wasm
+
+// Command viesquery-wasm exposes the offline VAT number checks to JavaScript,
+// so web forms can pre-validate numbers with the same rules as the CLI.
+// It registers a global viesquery object:
+//
+//	viesquery.validateFormat("de 266 201 128")
+//	// {valid: true, normalized: "DE266201128"}
+//	viesquery.validateFormat("AT12345678")
+//	// {valid: false, normalized: "AT12345678", code: "INVALID_FORMAT",
+//	//  message: "...", suggestions: ["ATU12345678"], hint: "..."}
+//	viesquery.checksum("DE266201128")
+//	// {country: "DE", supported: true, valid: true}
+//
+// Build with `make wasm` and load bin/viesquery.wasm with Go's wasm_exec.js.
+package main
+
+import (
+	"errors"
+	"sort"
+	"syscall/js"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func main() {
+	js.Global().Set("viesquery", js.ValueOf(map[string]any{
+		"validateFormat": js.FuncOf(validateFormat),
+		"checksum":       js.FuncOf(checksum),
+		"countries":      js.FuncOf(countries),
+	}))
+	// Keep the exported functions alive
+	select {}
+}
+
+// validateFormat checks a VAT number's format and check digits
+func validateFormat(this js.Value, args []js.Value) any {
+	input := argString(args)
+	result := map[string]any{
+		"valid":      true,
+		"normalized": vies.NormalizeInput(input),
+	}
+	err := vies.ValidateFormat(input)
+	if err == nil {
+		return result
+	}
+	result["valid"] = false
+	result["message"] = err.Error()
+	var validationErr *vies.ValidationError
+	if errors.As(err, &validationErr) {
+		result["code"] = validationErr.Code
+		if len(validationErr.Suggestions) > 0 {
+			suggestions := make([]any, len(validationErr.Suggestions))
+			for i, s := range validationErr.Suggestions {
+				suggestions[i] = s
+			}
+			result["suggestions"] = suggestions
+		}
+		if validationErr.Hint != "" {
+			result["hint"] = validationErr.Hint
+		}
+	}
+	return result
+}
+
+// checksum reports whether the number's check digits are verified for its
+// country and, if so, whether they are correct
+func checksum(this js.Value, args []js.Value) any {
+	normalized := vies.NormalizeInput(argString(args))
+	if len(normalized) < 2 {
+		return map[string]any{"country": "", "supported": false, "valid": false}
+	}
+	country := normalized[:2]
+	supported := vies.HasCheckDigits(country)
+	return map[string]any{
+		"country":   country,
+		"supported": supported,
+		"valid":     supported && vies.ValidateFormat(normalized) == nil,
+	}
+}
+
+// countries returns the supported country codes, sorted
+func countries(this js.Value, args []js.Value) any {
+	codes := vies.GetSupportedCountries()
+	sort.Strings(codes)
+	list := make([]any, len(codes))
+	for i, code := range codes {
+		list[i] = code
+	}
+	return list
+}
+
+// argString returns the first argument as a string, or "" if there is none
+func argString(args []js.Value) string {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return ""
+	}
+	return args[0].String()
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"l22.io/viesquery/internal/batch"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// runBatch implements the "batch" subcommand, validating every VAT number in
+// a CSV file and writing the rows back with result columns appended
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	inputPath := fs.String("input", "", "CSV or .xlsx file to check: a path, - for stdin, or an http(s) URL (also accepted as the argument)")
+	var inputHeader headerList
+	fs.Var(&inputHeader, "input-header", "Header sent when the input is an http(s) URL, e.g. \"Authorization: Bearer TOKEN\" (repeatable; VIESQUERY_INPUT_AUTHORIZATION sets Authorization)")
+	column := fs.String("column", "", "VAT number column, by header name or 1-based index (default: a column named vat/vat_number/vatNumber, else the first)")
+	noHeader := fs.Bool("no-header", false, "Treat the first row as data instead of a header")
+	delimiter := fs.String("delimiter", ",", "Field delimiter")
+	sheet := fs.String("sheet", "", "Worksheet of .xlsx input, by name or 1-based position (default: the first)")
+	workers := fs.Int("workers", 1, "Number of concurrent VIES requests (VIES recommends about 1 request per second)")
+	timeout := fs.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 30), "Request timeout in seconds")
+	verbose := fs.Bool("verbose", getEnvBool("VIESQUERY_VERBOSE", false), "Enable verbose logging")
+	redact := fs.Bool("redact", getEnvBool("VIESQUERY_REDACT", false), "Mask trader names and addresses in the report and verbose logs")
+	rateLimit := fs.Float64("rate-limit", 0, "Maximum requests per second to each member state (0: unlimited)")
+	countryLimits := fs.String("country-rate-limit", "", "Per-member-state overrides of --rate-limit, e.g. DE=2,FR=0.5")
+	maxPerDay := fs.Int("max-requests-per-day", getEnvInt("VIESQUERY_MAX_REQUESTS_PER_DAY", 0), "Refuse to send more than this many requests per UTC day, across invocations (0: unlimited)")
+	outputPath := fs.String("output", "", "Write the report to this file (atomically replaced) instead of stdout")
+	appendOut := fs.Bool("append", false, "Append rows to the --output file; the header is skipped if the file has content")
+	compress := fs.Bool("compress", false, "Gzip the report (implied by an --output name ending in .gz)")
+	fields := fs.String("fields", "", "Comma-separated result columns to append, in order (default: all but durationMs; requestIdentifier only with --requester)")
+	requester := fs.String("requester", "", "VAT number of the party on whose behalf the checks are made; adds the VIES consultation number as a requestIdentifier column")
+	itemTimeout := fs.Duration("item-timeout", 0, "Maximum time spent on one row, retries included; such rows are reported as ITEM_TIMEOUT (0: no limit)")
+	deadline := fs.Duration("batch-deadline", 0, "Maximum duration of the whole run; rows not checked by then are reported as BATCH_DEADLINE (0: no limit)")
+	window := fs.String("window", getEnvString("VIESQUERY_BATCH_WINDOW", ""), "Only send requests during this daily period, e.g. 02:00-05:00@Europe/Brussels, pausing outside of it (default: batchWindow from the config file)")
+	defaultCountry := fs.String("default-country", getEnvString("VIESQUERY_DEFAULT_COUNTRY", ""), "Country code prepended to numbers without a country prefix, e.g. IT (default: defaultCountry from the config file)")
+	configPath := fs.String("config", getEnvString("VIESQUERY_CONFIG", ""), "Path to config file (JSON)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s batch [flags] FILE|URL\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Validate the VAT numbers in a CSV or .xlsx file (- for stdin, or an http(s) URL).\n")
+		fmt.Fprintf(os.Stderr, "The CSV report is written to stdout (or --output) with the columns valid, name,\n")
+		fmt.Fprintf(os.Stderr, "address, errorCode and errorMessage appended to each row. Exits with 2 if any row\n")
+		fmt.Fprintf(os.Stderr, "has an error.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *inputPath == "" && fs.NArg() == 1 {
+		*inputPath = fs.Arg(0)
+	}
+	if *inputPath == "" || fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "Error: input file required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *workers < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid workers '%d'. Must be greater than 0\n", *workers)
+		os.Exit(1)
+	}
+	if *timeout < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", *timeout)
+		os.Exit(1)
+	}
+	if *itemTimeout < 0 || *deadline < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --item-timeout and --batch-deadline must not be negative\n")
+		os.Exit(1)
+	}
+	if *requester != "" {
+		if err := vies.ValidateFormat(*requester); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid requester: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *rateLimit < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid rate limit '%g'. Must not be negative\n", *rateLimit)
+		os.Exit(1)
+	}
+	countryRates, err := parseCountryRates(*countryLimits)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg := loadConfig(resolveConfigPath(*configPath))
+	windowSpec := *window
+	if windowSpec == "" {
+		windowSpec = cfg.BatchWindow
+	}
+	if *defaultCountry == "" {
+		*defaultCountry = cfg.DefaultCountry
+	}
+	if err := validateDefaultCountry(*defaultCountry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var batchWindow *batch.Window
+	if windowSpec != "" {
+		if batchWindow, err = batch.ParseWindow(windowSpec); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid delimiter '%s'. Must be a single character\n", *delimiter)
+		os.Exit(1)
+	}
+
+	input, err := openInput(context.Background(), *inputPath, inputHeaders(inputHeader))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	if *appendOut && *outputPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --append requires --output\n")
+		os.Exit(1)
+	}
+	report, err := openReport(*outputPath, *appendOut, *compress)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	clientOptions := []vies.ClientOption{
+		vies.WithTimeout(time.Duration(*timeout) * time.Second),
+		vies.WithVerbose(*verbose),
+		vies.WithRedact(*redact),
+		vies.WithRateLimit(*rateLimit),
+	}
+	for country, rate := range countryRates {
+		clientOptions = append(clientOptions, vies.WithCountryRateLimit(country, rate))
+	}
+	if *maxPerDay > 0 {
+		clientOptions = append(clientOptions, vies.WithRequestBudget(dailyBudget(*maxPerDay)))
+	}
+	if *defaultCountry != "" {
+		clientOptions = append(clientOptions, vies.WithDefaultCountry(*defaultCountry))
+	}
+	client := vies.NewClient(clientOptions...)
+
+	// SIGINT/SIGTERM end the run cleanly, keeping the rows checked so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := batch.Run(ctx, client, input, report, batch.Options{
+		Column:   *column,
+		NoHeader: *noHeader,
+		Comma:    comma,
+		Sheet:    *sheet,
+		Workers:  *workers,
+		Redact:   *redact,
+		Fields:   splitList(*fields),
+
+		Requester: strings.TrimSpace(*requester),
+
+		ItemTimeout: *itemTimeout,
+		Deadline:    *deadline,
+
+		Window: batchWindow,
+		OnPause: func(until time.Time) {
+			fmt.Fprintf(os.Stderr, "Outside the batch window %s, pausing until %s\n", batchWindow, until.Format("2006-01-02 15:04 MST"))
+		},
+
+		OmitReportHeader: report.existing(),
+	})
+	interrupted := errors.Is(err, context.Canceled)
+	if err != nil && !interrupted {
+		report.Abort()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := report.Commit(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: writing output: %v\n", err)
+		os.Exit(1)
+	}
+	if interrupted {
+		fmt.Fprintf(os.Stderr, "Interrupted: %d rows checked, %d rows not processed\n", summary.Rows, summary.NotProcessed)
+		os.Exit(130)
+	}
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "Checked %d rows: %d valid, %d invalid, %d errors (%d timed out, %d past the deadline), %d throttled\n",
+			summary.Rows, summary.Valid, summary.Invalid, summary.Errors, summary.TimedOut, summary.Unchecked, summary.Throttled)
+	}
+	if summary.Errors > 0 {
+		os.Exit(2)
+	}
+}
+
+// batchReport is the destination of a batch report: stdout or an
+// atomically replaced file, gzipped if requested
+type batchReport struct {
+	io.Writer
+	file *atomicFile
+	gz   *gzip.Writer
+}
+
+// openReport opens the report destination; path "" means stdout. The report
+// is gzipped with compress or a path ending in .gz. Gzip members may be
+// concatenated, so appending a new member to an existing .gz report yields
+// a valid gzip file.
+func openReport(path string, appendMode, compress bool) (*batchReport, error) {
+	report := &batchReport{Writer: os.Stdout}
+	if path != "" {
+		f, err := createOutput(path, appendMode)
+		if err != nil {
+			return nil, err
+		}
+		report.Writer, report.file = f, f
+	}
+	if compress || strings.HasSuffix(path, ".gz") {
+		report.gz = gzip.NewWriter(report.Writer)
+		report.Writer = report.gz
+	}
+	return report, nil
+}
+
+// existing reports whether rows are appended to a report with content
+func (r *batchReport) existing() bool {
+	return r.file != nil && r.file.existing
+}
+
+// Commit completes the gzip stream and puts the report file in place
+func (r *batchReport) Commit() error {
+	if r.gz != nil {
+		if err := r.gz.Close(); err != nil {
+			r.Abort()
+			return err
+		}
+	}
+	if r.file != nil {
+		return r.file.Commit()
+	}
+	return nil
+}
+
+// Abort discards the report file, leaving an existing report untouched
+func (r *batchReport) Abort() {
+	if r.file != nil {
+		r.file.Abort()
+	}
+}
+
+// parseCountryRates parses per-country rate limits such as "DE=2,FR=0.5"
+func parseCountryRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, item := range splitList(s) {
+		country, value, ok := strings.Cut(item, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid country rate limit '%s'. Expected COUNTRY=REQUESTS_PER_SECOND", item)
+		}
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if country == "GR" {
+			country = "EL"
+		}
+		rates[country] = rate
+	}
+	return rates, nil
+}
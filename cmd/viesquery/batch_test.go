@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/internal/batch"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestOpenReportCompression(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		wantGzip bool
+	}{
+		{"report.csv", false, false},
+		{"report.csv", true, true},
+		{"report.csv.gz", false, true},
+		{"report.csv.gz", true, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		report, err := openReport(path, false, tt.compress)
+		if err != nil {
+			t.Fatalf("openReport() error = %v", err)
+		}
+		io.WriteString(report, "vat,valid\n")
+		if err := report.Commit(); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+		data, _ := os.ReadFile(path)
+		if gzipped := bytes.HasPrefix(data, []byte{0x1f, 0x8b}); gzipped != tt.wantGzip {
+			t.Errorf("%s with compress = %t: gzipped = %t, want %t", tt.name, tt.compress, gzipped, tt.wantGzip)
+		}
+	}
+}
+
+func TestOpenReportAppendGzip(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+	path := filepath.Join(t.TempDir(), "report.csv.gz")
+
+	for _, input := range []string{"vat\nDE266201128\n", "vat\nFR12345678901\n"} {
+		report, err := openReport(path, true, false)
+		if err != nil {
+			t.Fatalf("openReport() error = %v", err)
+		}
+		_, err = batch.Run(context.Background(), checker, strings.NewReader(input), report, batch.Options{
+			Fields:           []string{"valid"},
+			OmitReportHeader: report.existing(),
+		})
+		if err != nil {
+			report.Abort()
+			t.Fatalf("batch.Run() error = %v", err)
+		}
+		if err := report.Commit(); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f) // reads all concatenated members
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("reading the report: %v", err)
+	}
+	want := "vat,valid\nDE266201128,true\nFR12345678901,false\n"
+	if string(data) != want {
+		t.Errorf("report =\n%s\nwant\n%s", data, want)
+	}
+}
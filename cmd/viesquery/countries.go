@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// countryEntry is the JSON form of a supported member state
+type countryEntry struct {
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	LocalName    string   `json:"localName"`
+	Format       string   `json:"format"`
+	Examples     []string `json:"examples"`
+	CheckDigits  bool     `json:"checkDigits"`
+	AuthorityURL string   `json:"authorityUrl"`
+}
+
+// runCountries implements the "countries" subcommand, listing the supported
+// member states with their number formats
+func runCountries(args []string) {
+	fs := flag.NewFlagSet("countries", flag.ExitOnError)
+	format := fs.String("format", "plain", "Output format (plain, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s countries [--format plain|json] [CODE...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "List the supported member states with the local name and format of their\n")
+		fmt.Fprintf(os.Stderr, "VAT numbers, examples and the website of the tax authority. Without codes\n")
+		fmt.Fprintf(os.Stderr, "all member states are listed.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *format != "plain" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+		os.Exit(1)
+	}
+
+	codes := fs.Args()
+	if len(codes) == 0 {
+		codes = vies.GetSupportedCountries()
+		sort.Strings(codes)
+	}
+	entries := make([]countryEntry, 0, len(codes))
+	for _, code := range codes {
+		info, err := vies.GetCountryInfo(strings.ToUpper(code))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		entries = append(entries, countryEntry{
+			Code:         info.Code,
+			Name:         info.Name,
+			LocalName:    info.LocalName,
+			Format:       info.Description,
+			Examples:     info.Examples,
+			CheckDigits:  vies.HasCheckDigits(info.Code),
+			AuthorityURL: info.AuthorityURL,
+		})
+	}
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	case "plain":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tCOUNTRY\tLOCAL NAME\tFORMAT\tEXAMPLE\tTAX AUTHORITY")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Code, e.Name, e.LocalName, e.Format, strings.Join(e.Examples, ", "), e.AuthorityURL)
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"l22.io/viesquery/internal/batch"
+)
+
+// runDuplicates implements the "duplicates" subcommand, reporting VAT numbers
+// that occur in several rows of a CSV file, and those mapped to different
+// customers, without contacting VIES
+func runDuplicates(args []string) {
+	fs := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	inputPath := fs.String("input", "", "CSV or .xlsx file to check: a path, - for stdin, or an http(s) URL")
+	column := fs.String("column", "", "VAT number column, by header name or 1-based index (default: a column named vat/vat_number/vatNumber, else the first)")
+	idColumn := fs.String("id-column", "", "Customer ID column, by header name or 1-based index (default: a column named customer_id/customer/id, if any)")
+	nameColumn := fs.String("name-column", "", "Company name column, by header name or 1-based index (default: a column named name/company/company_name, if any)")
+	noHeader := fs.Bool("no-header", false, "Treat the first row as data instead of a header")
+	delimiter := fs.String("delimiter", ",", "Field delimiter")
+	sheet := fs.String("sheet", "", "Worksheet of .xlsx input, by name or 1-based position (default: the first)")
+	format := fs.String("format", "plain", "Report format (plain, json)")
+	var inputHeader headerList
+	fs.Var(&inputHeader, "input-header", "Header sent when the input is an http(s) URL, e.g. \"Authorization: Bearer TOKEN\" (repeatable; VIESQUERY_INPUT_AUTHORIZATION sets Authorization)")
+	conflictsOnly := fs.Bool("conflicts-only", false, "Only report numbers mapped to different customer IDs or names")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s duplicates --input FILE [flags]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Report VAT numbers occurring in more than one row of a CSV file, without\n")
+		fmt.Fprintf(os.Stderr, "network calls. A number is a conflict when its rows have different customer\n")
+		fmt.Fprintf(os.Stderr, "IDs or company names. Exits with 3 if any conflict is found.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *inputPath == "" && fs.NArg() == 1 {
+		*inputPath = fs.Arg(0)
+	}
+	if *inputPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --input required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *format != "plain" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+		os.Exit(1)
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid delimiter '%s'. Must be a single character\n", *delimiter)
+		os.Exit(1)
+	}
+
+	input, err := openInput(context.Background(), *inputPath, inputHeaders(inputHeader))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	all, rows, err := batch.Duplicates(input, batch.Options{
+		Column:   *column,
+		NoHeader: *noHeader,
+		Comma:    comma,
+		Sheet:    *sheet,
+	}, batch.DuplicateColumns{ID: *idColumn, Name: *nameColumn})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	duplicates := []batch.Duplicate{}
+	conflicts := 0
+	for _, d := range all {
+		if d.Conflict {
+			conflicts++
+		}
+		if d.Conflict || !*conflictsOnly {
+			duplicates = append(duplicates, d)
+		}
+	}
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(duplicates, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	case "plain":
+		if len(duplicates) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VAT NUMBER\tROWS\tCONFLICT\tCUSTOMER IDS\tNAMES")
+			for _, d := range duplicates {
+				rowNumbers := make([]string, len(d.Rows))
+				for i, row := range d.Rows {
+					rowNumbers[i] = strconv.Itoa(row)
+				}
+				conflict := "no"
+				if d.Conflict {
+					conflict = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.VATNumber, strings.Join(rowNumbers, ","), conflict,
+					strings.Join(d.CustomerIDs, ", "), strings.Join(d.Names, " | "))
+			}
+			w.Flush()
+		}
+		fmt.Fprintf(os.Stderr, "%d rows checked, %d duplicated VAT numbers, %d conflicts\n", rows, len(all), conflicts)
+	}
+
+	if conflicts > 0 {
+		os.Exit(3)
+	}
+}
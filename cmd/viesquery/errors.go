@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// runErrors implements the "errors" subcommand, listing the error catalog
+func runErrors(args []string) {
+	fs := flag.NewFlagSet("errors", flag.ExitOnError)
+	format := fs.String("format", "plain", "Output format (plain, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s errors [--format plain|json]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "List all error codes with descriptions, retryability and exit codes\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	catalog := vies.ErrorCatalog()
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(catalog, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	case "plain":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tEXIT\tRETRYABLE\tDESCRIPTION")
+		for _, info := range catalog {
+			fmt.Fprintf(w, "%s\t%d\t%t\t%s\n", info.Code, info.ExitCode, info.Retryable, info.Description)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// exitCodeInfo describes one exit code of the binary
+type exitCodeInfo struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ErrorCodes are the catalog error codes mapped to this exit code
+	ErrorCodes []string `json:"errorCodes"`
+}
+
+// exitCodes is the exit code table of all commands, in numeric order
+var exitCodes = []exitCodeInfo{
+	{Code: 0, Name: "OK", Description: "Successful validation; for lint and duplicates, no findings"},
+	{Code: 1, Name: "USAGE", Description: "Invalid command arguments, config or input file"},
+	{Code: 2, Name: "SERVICE_ERROR", Description: "Network or API error; for batch, at least one row has an error"},
+	{Code: 3, Name: "INVALID_INPUT", Description: "Invalid VAT number format or check digit; for lint and duplicates, violations or conflicts were found"},
+	{Code: 4, Name: "SERVICE_UNAVAILABLE", Description: "VIES or the member state service is unavailable"},
+	{Code: 5, Name: "BUDGET_EXCEEDED", Description: "Daily request budget used up (--max-requests-per-day)"},
+	{Code: exitPollDeadline, Name: "POLL_DEADLINE", Description: "poll-until-valid deadline passed before the number became valid"},
+	{Code: 130, Name: "INTERRUPTED", Description: "batch or schedule run interrupted by SIGINT/SIGTERM; completed rows were kept"},
+}
+
+// exitCodeTable returns exitCodes with the catalog error codes filled in
+func exitCodeTable() []exitCodeInfo {
+	table := make([]exitCodeInfo, len(exitCodes))
+	copy(table, exitCodes)
+	for i := range table {
+		table[i].ErrorCodes = []string{}
+		for _, info := range vies.ErrorCatalog() {
+			if info.ExitCode == table[i].Code {
+				table[i].ErrorCodes = append(table[i].ErrorCodes, info.Code)
+			}
+		}
+	}
+	return table
+}
+
+// runExitCodes implements the "exit-codes" subcommand, listing the exit code
+// table so wrapper scripts do not need to hardcode it
+func runExitCodes(args []string) {
+	fs := flag.NewFlagSet("exit-codes", flag.ExitOnError)
+	format := fs.String("format", "plain", "Output format (plain, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s exit-codes [--format plain|json]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "List all exit codes with their meaning and the error codes mapped to them\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	table := exitCodeTable()
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(table, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	case "plain":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "EXIT\tNAME\tERROR CODES\tDESCRIPTION")
+		for _, info := range table {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", info.Code, info.Name, strings.Join(info.ErrorCodes, ", "), info.Description)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestExitCodeTable(t *testing.T) {
+	readme, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, names := make(map[int]bool), make(map[string]bool)
+	mapped := make(map[string]int)
+	for _, info := range exitCodeTable() {
+		if codes[info.Code] || names[info.Name] {
+			t.Errorf("exit code %d (%s) is listed twice", info.Code, info.Name)
+		}
+		codes[info.Code], names[info.Name] = true, true
+		if info.Description == "" {
+			t.Errorf("exit code %d has no description", info.Code)
+		}
+		if !strings.Contains(string(readme), fmt.Sprintf("- `%d`: ", info.Code)) {
+			t.Errorf("exit code %d is not documented in the README", info.Code)
+		}
+		for _, code := range info.ErrorCodes {
+			if _, ok := mapped[code]; ok {
+				t.Errorf("error code %s is listed under exit codes %d and %d", code, mapped[code], info.Code)
+			}
+			mapped[code] = info.Code
+		}
+	}
+
+	// Every catalog code maps to one documented exit code
+	for _, info := range vies.ErrorCatalog() {
+		if got := exitCodeFor(info.Code, -1); got != info.ExitCode {
+			t.Errorf("exitCodeFor(%s) = %d, want %d", info.Code, got, info.ExitCode)
+		}
+		if exitCode, ok := mapped[info.Code]; !ok || exitCode != info.ExitCode {
+			t.Errorf("error code %s (exit %d) is missing from the exit code table", info.Code, info.ExitCode)
+		}
+	}
+	if got := exitCodeFor("NO_SUCH_CODE", 7); got != 7 {
+		t.Errorf("exitCodeFor() of an unknown code = %d, want the fallback", got)
+	}
+}
+
+func TestErrorExitCode(t *testing.T) {
+	validationErr := &vies.ValidationError{Code: vies.CodeInvalidFormat, Message: "Invalid length"}
+	unavailable := &vies.ServiceError{Code: vies.CodeServiceUnavailable, Message: "VIES service unavailable"}
+	budgetErr := &vies.ServiceError{Code: vies.CodeBudgetExceeded, Message: "Request not sent"}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation error", validationErr, 3},
+		{"wrapped validation error", fmt.Errorf("checking row 2: %w", validationErr), 3},
+		{"service error", unavailable, 4},
+		{"wrapped service error", fmt.Errorf("retry 3: %w", fmt.Errorf("waiting: %w", unavailable)), 4},
+		{"joined service error", errors.Join(errors.New("cleanup failed"), budgetErr), 5},
+		{"unknown service code", &vies.ServiceError{Code: "SOMETHING_NEW"}, 2},
+		{"other error", errors.New("disk full"), 2},
+	}
+	for _, tt := range tests {
+		if got := errorExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: errorExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
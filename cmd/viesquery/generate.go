@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// runGenerate implements the "generate" subcommand, printing random VAT
+// numbers that pass the offline format and check-digit validation
+func runGenerate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	country := fs.String("country", "", "Member state code, e.g. DE (required)")
+	count := fs.Int("count", 10, "Number of VAT numbers to generate")
+	seed := fs.Uint64("seed", 0, "Random seed, for reproducible fixtures (0: random)")
+	format := fs.String("format", "plain", "Output format (plain, csv, json)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s generate --country CODE [--count N] [flags]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Print synthetic VAT numbers that pass the format and check-digit validation,\n")
+		fmt.Fprintf(os.Stderr, "for load tests and fixtures. They are not taken from any register, but may\n")
+		fmt.Fprintf(os.Stderr, "happen to match an issued number.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *country == "" || fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: --country required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *count < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid count '%d'. Must be greater than 0\n", *count)
+		os.Exit(1)
+	}
+	if *format != "plain" && *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, csv, json\n", *format)
+		os.Exit(1)
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	if *seed == 0 {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	numbers := make([]string, *count)
+	for i := range numbers {
+		vatNumber, err := vies.GenerateVATNumber(strings.TrimSpace(*country), rng)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		numbers[i] = vatNumber
+	}
+
+	// Every format marks the numbers as synthetic, so generated fixtures are
+	// not mistaken for customer data
+	switch *format {
+	case "plain":
+		fmt.Fprintf(os.Stderr, "# %d synthetic VAT numbers (random, not taken from any register)\n", len(numbers))
+		for _, vatNumber := range numbers {
+			fmt.Println(vatNumber)
+		}
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"vat", "synthetic"})
+		for _, vatNumber := range numbers {
+			w.Write([]string{vatNumber, "true"})
+		}
+		w.Flush()
+	case "json":
+		type generated struct {
+			VATNumber string `json:"vatNumber"`
+			Synthetic bool   `json:"synthetic"`
+		}
+		list := make([]generated, len(numbers))
+		for i, vatNumber := range numbers {
+			list[i] = generated{VATNumber: vatNumber, Synthetic: true}
+		}
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// inputFetchTimeout bounds the download of a remote input file
+const inputFetchTimeout = 5 * time.Minute
+
+// maxInputSize bounds the size of a remote input file, so a wrong URL
+// cannot fill memory or disk
+var maxInputSize int64 = 256 << 20
+
+// headerList is a repeatable "Name: value" flag
+type headerList []string
+
+func (h *headerList) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerList) Set(s string) error {
+	name, _, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, expected \"Name: value\"", s)
+	}
+	*h = append(*h, s)
+	return nil
+}
+
+// inputHeaders returns the headers sent with remote input requests: the
+// --input-header flags, plus VIESQUERY_INPUT_AUTHORIZATION as the
+// Authorization header, which keeps tokens out of the process list
+func inputHeaders(flags headerList) http.Header {
+	header := make(http.Header)
+	if auth := os.Getenv("VIESQUERY_INPUT_AUTHORIZATION"); auth != "" {
+		header.Set("Authorization", auth)
+	}
+	for _, h := range flags {
+		name, value, _ := strings.Cut(h, ":")
+		header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header
+}
+
+// isRemoteInput reports whether an input path is an http(s) URL
+func isRemoteInput(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// openInput opens an input file: stdin for "-", a download for an http(s)
+// URL, or a local file
+func openInput(ctx context.Context, path string, header http.Header) (io.ReadCloser, error) {
+	switch {
+	case path == "-":
+		return io.NopCloser(os.Stdin), nil
+	case isRemoteInput(path):
+		return fetchInput(ctx, path, header)
+	}
+	return os.Open(path)
+}
+
+// fetchInput downloads a remote input file with a GET request
+func fetchInput(ctx context.Context, url string, header http.Header) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(ctx, inputFetchTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header = header.Clone()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("fetching input: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("fetching input: %s returned HTTP %d", redactURL(url), resp.StatusCode)
+	}
+	if resp.ContentLength > maxInputSize {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("fetching input: %s is larger than %d bytes", redactURL(url), maxInputSize)
+	}
+	return &fetchedInput{ReadCloser: resp.Body, cancel: cancel, url: url, left: maxInputSize}, nil
+}
+
+// fetchedInput is the body of a download, releasing its context on Close.
+// Reading fails once more than maxInputSize bytes arrived.
+type fetchedInput struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	url    string
+	left   int64
+}
+
+func (f *fetchedInput) Read(p []byte) (int, error) {
+	if int64(len(p)) > f.left+1 {
+		p = p[:f.left+1]
+	}
+	n, err := f.ReadCloser.Read(p)
+	f.left -= int64(n)
+	if f.left < 0 {
+		return 0, fmt.Errorf("fetching input: %s is larger than %d bytes", redactURL(f.url), maxInputSize)
+	}
+	return n, err
+}
+
+func (f *fetchedInput) Close() error {
+	defer f.cancel()
+	return f.ReadCloser.Close()
+}
+
+// redactURL drops the credentials and query of a URL from error messages,
+// where tokens are often passed
+func redactURL(url string) string {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if scheme, rest, ok := strings.Cut(url, "://"); ok {
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.IndexByte(rest+"/", '/') {
+			rest = rest[at+1:]
+		}
+		url = scheme + "://" + rest
+	}
+	return url
+}
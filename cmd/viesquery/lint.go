@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"l22.io/viesquery/internal/batch"
+)
+
+// runLint implements the "lint" subcommand, checking the format of every VAT
+// number in a CSV file without contacting VIES
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	inputPath := fs.String("input", "", "CSV or .xlsx file to check: a path, - for stdin, or an http(s) URL")
+	column := fs.String("column", "", "VAT number column, by header name or 1-based index (default: a column named vat/vat_number/vatNumber, else the first)")
+	noHeader := fs.Bool("no-header", false, "Treat the first row as data instead of a header")
+	delimiter := fs.String("delimiter", ",", "Field delimiter")
+	sheet := fs.String("sheet", "", "Worksheet of .xlsx input, by name or 1-based position (default: the first)")
+	format := fs.String("format", "plain", "Report format (plain, json)")
+	var inputHeader headerList
+	fs.Var(&inputHeader, "input-header", "Header sent when the input is an http(s) URL, e.g. \"Authorization: Bearer TOKEN\" (repeatable; VIESQUERY_INPUT_AUTHORIZATION sets Authorization)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s lint --input FILE [flags]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Check the format of every VAT number in a CSV file without network calls\n")
+		fmt.Fprintf(os.Stderr, "and report the violations. Exits with 3 if any violation is found.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *inputPath == "" && fs.NArg() == 1 {
+		*inputPath = fs.Arg(0)
+	}
+	if *inputPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --input required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *format != "plain" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+		os.Exit(1)
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid delimiter '%s'. Must be a single character\n", *delimiter)
+		os.Exit(1)
+	}
+
+	input, err := openInput(context.Background(), *inputPath, inputHeaders(inputHeader))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+
+	violations, rows, err := batch.Lint(input, batch.Options{
+		Column:   *column,
+		NoHeader: *noHeader,
+		Comma:    comma,
+		Sheet:    *sheet,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		if violations == nil {
+			violations = []batch.Violation{}
+		}
+		data, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Println(string(data))
+	case "plain":
+		if len(violations) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ROW\tVAT NUMBER\tCODE\tMESSAGE")
+			for _, v := range violations {
+				message := v.Message
+				if len(v.Suggestions) > 0 {
+					message += " (did you mean " + strings.Join(v.Suggestions, ", ") + "?)"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", v.Row, v.VATNumber, v.Code, message)
+			}
+			w.Flush()
+		}
+		fmt.Fprintf(os.Stderr, "%d rows checked, %d violations\n", rows, len(violations))
+	}
+
+	if len(violations) > 0 {
+		os.Exit(3)
+	}
+}
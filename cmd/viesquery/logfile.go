@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"l22.io/viesquery/internal/logfile"
+)
+
+// logFileFlags are the flags of long-running subcommands that write their
+// logs to a rotated file instead of stderr
+type logFileFlags struct {
+	path       *string
+	maxSizeMB  *int
+	maxAge     dayDuration
+	maxBackups *int
+}
+
+// addLogFileFlags defines the --log-file flags on fs
+func addLogFileFlags(fs *flag.FlagSet) *logFileFlags {
+	l := &logFileFlags{maxAge: dayDuration(24 * time.Hour)}
+	l.path = fs.String("log-file", getEnvString("VIESQUERY_LOG_FILE", ""), "Write progress and verbose logs to this file, with timestamps, instead of stderr")
+	l.maxSizeMB = fs.Int("log-max-size", 100, "Rotate the log file when it reaches this size in MB (0: no limit)")
+	fs.Var(&l.maxAge, "log-max-age", "Rotate the log file after this long, e.g. 12h or 7d (0: no limit)")
+	l.maxBackups = fs.Int("log-max-backups", 7, "Number of rotated log files kept (0: all)")
+	return l
+}
+
+// open returns the logger for progress messages and the one for verbose
+// client logs. Without --log-file both write to stderr, progress messages
+// without timestamps as before.
+func (l *logFileFlags) open() (progress, client *log.Logger, err error) {
+	if *l.path == "" {
+		return log.New(os.Stderr, "", 0), log.New(os.Stderr, "[VIES] ", log.LstdFlags), nil
+	}
+	file, err := logfile.Open(*l.path, logfile.Options{
+		MaxSize:    int64(*l.maxSizeMB) << 20,
+		MaxAge:     time.Duration(l.maxAge),
+		MaxBackups: *l.maxBackups,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return log.New(file, "", log.LstdFlags), log.New(file, "[VIES] ", log.LstdFlags), nil
+}
@@ -3,15 +3,25 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // embedded zone database for --tz on systems without one
+	"unicode"
 
-	"l22.io/viesquery/internal/output"
-	"l22.io/viesquery/internal/vies"
+	"l22.io/viesquery/internal/budget"
+	"l22.io/viesquery/pkg/output"
+	"l22.io/viesquery/pkg/vies"
 )
 
 var (
@@ -19,45 +29,152 @@ var (
 	Version = "dev"
 )
 
+var (
+	// stdout receives results and error reports: os.Stdout or the --output file
+	stdout io.Writer = os.Stdout
+	// outFile is the pending --output file, committed by exit
+	outFile *atomicFile
+)
+
 func main() {
+	// Subcommands are dispatched before flag parsing
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "errors":
+			runErrors(os.Args[2:])
+			return
+		case "exit-codes":
+			runExitCodes(os.Args[2:])
+			return
+		case "countries":
+			runCountries(os.Args[2:])
+			return
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		case "schedule":
+			runSchedule(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "duplicates":
+			runDuplicates(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "repl":
+			runREPL(os.Args[2:])
+			return
+		case "poll-until-valid":
+			runPollUntilValid(os.Args[2:])
+			return
+		}
+	}
+
 	var (
-		format     = flag.String("format", getEnvString("VIESQUERY_FORMAT", "plain"), "Output format (plain, json)")
-		timeout    = flag.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 30), "Request timeout in seconds")
+		format     = flag.String("format", getEnvString("VIESQUERY_FORMAT", ""), "Output format ("+strings.Join(output.SupportedFormats(), ", ")+"; default plain)")
+		timeout    = flag.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 0), "Request timeout in seconds (default 30)")
 		verbose    = flag.Bool("verbose", getEnvBool("VIESQUERY_VERBOSE", false), "Enable verbose logging")
 		version    = flag.Bool("version", false, "Display version information")
 		help       = flag.Bool("help", false, "Display help information")
-		dateStyle  = flag.String("date-style", getEnvString("VIESQUERY_DATE_STYLE", ""), "Date rendering style (gce-verbose|iso-date|rfc3339|unix|iso-week)")
-		calendar   = flag.String("calendar", getEnvString("VIESQUERY_CALENDAR", ""), "Calendar system (gregorian; others planned)")
+		schema     = flag.Bool("print-schema", false, "Print the JSON Schema for json output and exit")
+		protoDef   = flag.Bool("print-proto", false, "Print the protobuf definition for proto output and exit")
+		dateStyle  = flag.String("date-style", getEnvString("VIESQUERY_DATE_STYLE", ""), "Date rendering style ("+strings.Join(output.SupportedDateStyles(), "|")+")")
+		dateFormat = flag.String("date-format", getEnvString("VIESQUERY_DATE_FORMAT", ""), "Layout for the custom date style (Go layout such as 02.01.2006, or strftime such as %d.%m.%Y)")
+		tz         = flag.String("tz", getEnvString("VIESQUERY_TZ", ""), "Time zone for rendering request dates (e.g., Europe/Berlin; default UTC)")
+		calendar   = flag.String("calendar", getEnvString("VIESQUERY_CALENDAR", ""), "Calendar system ("+strings.Join(output.SupportedCalendars(), "|")+")")
 		configPath = flag.String("config", getEnvString("VIESQUERY_CONFIG", ""), "Path to config file (JSON). Defaults to $XDG_CONFIG_HOME/viesquery/config.json or ~/.config/viesquery/config.json")
+		locale     = flag.String("locale", getEnvString("VIESQUERY_LOCALE", ""), "Locale for the verbose date sentence, country names and plain output labels (en, de, fr, es, it, nl, pl, pt)")
+		country    = flag.String("country", "", "Country code of a VAT number given without its prefix, e.g. --country DE 123456788")
+		defCountry = flag.String("default-country", getEnvString("VIESQUERY_DEFAULT_COUNTRY", ""), "Country code prepended to numbers without a country prefix, e.g. IT for national datasets")
+		addrFormat = flag.String("address-format", getEnvString("VIESQUERY_ADDRESS_FORMAT", ""), "Address rendering ("+strings.Join(output.AddressFormats, ", ")+"; default as returned by VIES)")
+		redact     = flag.Bool("redact", getEnvBool("VIESQUERY_REDACT", false), "Mask trader names and addresses in output and verbose logs")
+		profile    = flag.String("profile", getEnvString("VIESQUERY_PROFILE", ""), "Named profile from the config file")
+		env        = flag.String("env", getEnvString("VIESQUERY_ENV", ""), "VIES environment: prod, or test for the acceptance service and its test numbers (default prod)")
+		soapVer    = flag.String("soap-version", getEnvString("VIESQUERY_SOAP_VERSION", ""), "SOAP protocol version (1.1, 1.2; default 1.1)")
+		greekPfx   = flag.String("greek-prefix", getEnvString("VIESQUERY_GREEK_PREFIX", ""), "Country code reported for Greek numbers: canonical (EL) or input (GR if given as GR) (default canonical)")
+		fields     = flag.String("fields", "", "Comma-separated result fields to output, e.g. valid,name,requestDate (default: all)")
+		query      = flag.String("query", "", "jq expression applied to the JSON result before output, e.g. .valid (replaces the output format for results)")
+		maxPerDay  = flag.Int("max-requests-per-day", getEnvInt("VIESQUERY_MAX_REQUESTS_PER_DAY", 0), "Refuse to send more than this many requests per UTC day, across invocations (0: unlimited)")
+		outputPath = flag.String("output", "", "Write the result to this file (atomically replaced) instead of stdout")
+		appendOut  = flag.Bool("append", false, "Append to the --output file instead of replacing it")
+		printReq   = flag.Bool("print-request", false, "Print the HTTP request that would be sent to VIES and exit without sending it")
+		printResp  = flag.Bool("print-response", false, "Print the raw VIES HTTP response to stderr")
+		waitFor    = flag.Duration("wait-for-service", getEnvDuration("VIESQUERY_WAIT_FOR_SERVICE", 0), "While VIES or the member state is unavailable, keep retrying with backoff for up to this long, e.g. 2h (0: fail at once)")
+		retries    = flag.Int("retries", getEnvInt("VIESQUERY_RETRIES", 0), "Retry up to this many times while VIES or the member state is unavailable (0: no limit with --wait-for-service, else no retries)")
+		retryWait  = flag.Duration("retry-wait", getEnvDuration("VIESQUERY_RETRY_WAIT", 0), "Pause before the first retry, doubled for each further retry (default 30s)")
+		retryMax   = flag.Duration("retry-max-wait", getEnvDuration("VIESQUERY_RETRY_MAX_WAIT", 0), "Upper limit of the pause between retries (default 10m)")
+		maxElapsed = flag.Duration("max-elapsed", getEnvDuration("VIESQUERY_MAX_ELAPSED", 0), "Hard limit on the total time of the validation, retries and waits included, e.g. 45s (0: no limit)")
+		windows    = flag.String("maintenance-window", getEnvString("VIESQUERY_MAINTENANCE_WINDOWS", ""), "Known daily maintenance windows, e.g. DE=23:00-05:00@Europe/Berlin,*=02:00-02:30; checks during a window warn, and --wait-for-service retries after it")
+		determin   = flag.Bool("deterministic", getEnvBool("VIESQUERY_DETERMINISTIC", false), "Replace request dates and log timestamps with fixed placeholders, for golden-file tests")
 	)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "VIES Query - EU VAT Number Validation Tool (pre-production)\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags] VAT_NUMBER\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] VAT_NUMBER\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] COUNTRY NUMBER...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s batch [flags] FILE|URL\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s schedule --input FILE --cron EXPR [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s lint --input FILE [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s duplicates --input FILE [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s generate --country CODE [--count N]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s repl [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s poll-until-valid [--interval 6h] [--deadline 14d] VAT_NUMBER\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s countries [--format plain|json] [CODE...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s errors [--format plain|json]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s exit-codes [--format plain|json]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Validate EU VAT numbers using the VIES API\n\n")
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
-		fmt.Fprintf(os.Stderr, "  VAT_NUMBER    EU VAT number to validate (e.g., DE123456789)\n\n")
+		fmt.Fprintf(os.Stderr, "  VAT_NUMBER    EU VAT number to validate (e.g., DE123456788); spaces need no quotes\n")
+		fmt.Fprintf(os.Stderr, "  COUNTRY       Country code of NUMBER, when stored separately (e.g., DE 123456788)\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nSupported Countries:\n")
 		fmt.Fprintf(os.Stderr, "  AT, BE, BG, HR, CY, CZ, DK, EE, FI, FR, DE, EL,\n")
 		fmt.Fprintf(os.Stderr, "  HU, IE, IT, LV, LT, LU, MT, NL, PL, PT, RO, SK, SI, ES, SE\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
-		fmt.Fprintf(os.Stderr, "  %s DE123456789\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s DE123456788\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --format json AT12345678\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  %s --timeout 60 --verbose IT12345678901\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --timeout 60 --verbose IT12345670017\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --date-style gce-verbose --calendar gregorian DE336158855\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --env test DE100\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --print-request DE123456788\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s lint --input customers.csv\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s batch customers.csv > customers-checked.csv\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s repl\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
-		fmt.Fprintf(os.Stderr, "  VIESQUERY_FORMAT       Default output format (plain, json)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_FORMAT       Default output format (%s)\n", strings.Join(output.SupportedFormats(), ", "))
 		fmt.Fprintf(os.Stderr, "  VIESQUERY_TIMEOUT      Default timeout in seconds\n")
 		fmt.Fprintf(os.Stderr, "  VIESQUERY_VERBOSE      Enable verbose mode (true, false)\n")
-		fmt.Fprintf(os.Stderr, "  VIESQUERY_DATE_STYLE   Date style (gce-verbose|iso-date|rfc3339|unix|iso-week)\n")
-		fmt.Fprintf(os.Stderr, "  VIESQUERY_CALENDAR     Calendar system (gregorian|julian|buddhist|minguo|japanese|islamic|hebrew)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_DATE_STYLE   Date style (%s)\n", strings.Join(output.SupportedDateStyles(), "|"))
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_DATE_FORMAT  Layout for the custom date style\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_TZ           Time zone for request dates (e.g., Europe/Berlin)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_CALENDAR     Calendar system (%s)\n", strings.Join(output.SupportedCalendars(), "|"))
 		fmt.Fprintf(os.Stderr, "  VIESQUERY_CONFIG       Path to config file\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_LOCALE       Locale for dates, country names and labels\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_DEFAULT_COUNTRY Country code for numbers without a prefix\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_ADDRESS_FORMAT  Address rendering (oneline, multiline, postal)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_REDACT       Mask trader names and addresses (true, false)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_PROFILE      Named profile from the config file\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_ENV          VIES environment (prod, test)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_SOAP_VERSION SOAP protocol version (1.1, 1.2)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_GREEK_PREFIX Country code for Greek numbers (canonical, input)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_MAX_REQUESTS_PER_DAY  Daily request budget (0: unlimited)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_WAIT_FOR_SERVICE  Keep retrying during outages for up to this long (e.g. 2h)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_RETRIES      Retries while VIES is unavailable\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_RETRY_WAIT   Pause before the first retry (e.g. 10s)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_RETRY_MAX_WAIT  Upper limit of the pause between retries (e.g. 2m)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_MAX_ELAPSED  Hard limit on the total time of a validation (e.g. 45s)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_MAINTENANCE_WINDOWS  Known daily maintenance windows (e.g. DE=23:00-05:00@Europe/Berlin)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_BATCH_WINDOW  Daily period for batch requests (e.g. 02:00-05:00@Europe/Brussels)\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_INPUT_AUTHORIZATION  Authorization header for http(s) batch input\n")
+		fmt.Fprintf(os.Stderr, "  VIESQUERY_DETERMINISTIC Fixed placeholders for dates and log timestamps (true, false)\n")
 		fmt.Fprintf(os.Stderr, "\nConfig File (JSON):\n")
-		fmt.Fprintf(os.Stderr, "  {\n    \"dateStyle\": \"gce-verbose\",\n    \"calendar\": \"gregorian\",\n    \"format\": \"plain\",\n    \"timeout\": 30,\n    \"verbose\": false\n  }\n")
-		fmt.Fprintf(os.Stderr, "\nDate styles available: gce-verbose (default), iso-date, rfc3339, unix, iso-week.\n")
-		fmt.Fprintf(os.Stderr, "Calendars available for gce-verbose: gregorian (default), julian, buddhist, minguo, japanese, islamic (tabular). Hebrew planned.\n")
+		fmt.Fprintf(os.Stderr, "  {\n    \"dateStyle\": \"gce-verbose\",\n    \"calendar\": \"gregorian\",\n    \"format\": \"plain\",\n    \"timeout\": 30,\n    \"verbose\": false,\n    \"locale\": \"en\",\n    \"redact\": false,\n    \"profiles\": {\n      \"client-a\": {\"requester\": \"DE123456788\", \"format\": \"json\", \"timeout\": 60}\n    }\n  }\n")
+		fmt.Fprintf(os.Stderr, "\nDate styles available: gce-verbose (default), iso-date, rfc3339, unix, iso-week, iso-ordinal, jdn, custom (requires --date-format).\n")
+		fmt.Fprintf(os.Stderr, "Calendars available for gce-verbose: gregorian (default), julian, buddhist, minguo, japanese, islamic (tabular), islamic-umalqura, persian, hebrew.\n")
 	}
 
 	flag.Parse()
@@ -73,86 +190,494 @@ func main() {
 		os.Exit(0)
 	}
 
-	if flag.NArg() != 1 {
+	if *schema {
+		os.Stdout.Write(output.JSONSchema())
+		os.Exit(0)
+	}
+
+	if *protoDef {
+		os.Stdout.Write(output.ProtoDefinition())
+		os.Exit(0)
+	}
+
+	if flag.NArg() < 1 {
 		fmt.Fprintf(os.Stderr, "Error: VAT number required\n\n")
 		flag.Usage()
 		os.Exit(1)
 	}
+	vatNumber, err := vatNumberArgument(flag.Args(), *country)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Load config for persistent options (date style, calendar, etc.)
-	resolvedConfigPath := *configPath
-	if resolvedConfigPath == "" {
-		if dir, err := os.UserConfigDir(); err == nil {
-			resolvedConfigPath = filepath.Join(dir, "viesquery", "config.json")
-		}
-	}
+	resolvedConfigPath := resolveConfigPath(*configPath)
 	cfg := loadConfig(resolvedConfigPath)
 
-	// Resolve date formatting options with precedence: defaults -> config -> env (already in flags defaults) -> flags
-	resolvedDateStyle := "gce-verbose"
-	if cfg.DateStyle != "" {
-		resolvedDateStyle = cfg.DateStyle
+	// A selected profile overrides the top-level config settings and the
+	// environment; only flags given on the command line override it
+	prof, err := cfg.profile(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v (config file %s)\n", err, resolvedConfigPath)
+		os.Exit(1)
+	}
+	cfg = cfg.withProfile(prof)
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	// Resolve date formatting options with precedence: defaults -> config -> env (already in flags defaults) -> profile -> explicit flags
+	resolvedDateStyle := resolveSetting(explicit["date-style"], *dateStyle, prof.DateStyle, cfg.DateStyle, "gce-verbose")
+	resolvedCalendar := resolveSetting(explicit["calendar"], *calendar, prof.Calendar, cfg.Calendar, "gregorian")
+	if err := output.SetDateOptions(resolvedDateStyle, resolvedCalendar); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	resolvedDateFormat := resolveSetting(explicit["date-format"], *dateFormat, prof.DateFormat, cfg.DateFormat, "")
+	if resolvedDateStyle == "custom" && resolvedDateFormat == "" {
+		fmt.Fprintf(os.Stderr, "Error: Date style 'custom' requires --date-format\n")
+		os.Exit(1)
+	}
+	output.SetDateFormat(resolvedDateFormat)
+
+	resolvedTimeZone := resolveSetting(explicit["tz"], *tz, prof.TimeZone, cfg.TimeZone, "")
+	if err := output.SetTimeZone(resolvedTimeZone); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Resolve locale with the same precedence as date options
+	resolvedLocale := resolveSetting(explicit["locale"], *locale, prof.Locale, cfg.Locale, "en")
+	if err := output.SetLocale(resolvedLocale); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	resolvedAddressFormat := resolveSetting(explicit["address-format"], *addrFormat, prof.AddressFormat, cfg.AddressFormat, "")
+	if err := output.SetAddressFormat(resolvedAddressFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := output.SetLabels(cfg.Labels); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid labels in config file %s: %v\n", resolvedConfigPath, err)
+		os.Exit(1)
+	}
+	if err := output.SetPlainLayout(cfg.PlainLayout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid plainLayout in config file %s: %v\n", resolvedConfigPath, err)
+		os.Exit(1)
+	}
+
+	// Redaction and verbose mode are enabled if requested by either the config or the flag
+	redactOutput := cfg.Redact || *redact
+	verboseOutput := cfg.Verbose || *verbose
+	output.SetRedaction(redactOutput)
+	if err := output.SetFields(splitList(*fields)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := output.SetQuery(*query); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	output.SetVerbose(verboseOutput)
+	output.SetDeterministic(*determin)
+
+	// Resolve and validate output format
+	resolvedFormat := resolveSetting(explicit["format"], *format, prof.Format, cfg.Format, "plain")
+	if _, err := output.GetFormatter(resolvedFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: %s\n", resolvedFormat, strings.Join(output.SupportedFormats(), ", "))
+		os.Exit(1)
 	}
-	if *dateStyle != "" {
-		resolvedDateStyle = *dateStyle
+
+	// Resolve and validate timeout
+	resolvedTimeout := resolveSetting(explicit["timeout"], *timeout, prof.Timeout, cfg.Timeout, 30)
+	if resolvedTimeout < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", resolvedTimeout)
+		os.Exit(1)
 	}
-	resolvedCalendar := "gregorian"
-	if cfg.Calendar != "" {
-		resolvedCalendar = cfg.Calendar
+	if *retries < 0 || *retryWait < 0 || *retryMax < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --retries, --retry-wait and --retry-max-wait must not be negative\n")
+		os.Exit(1)
 	}
-	if *calendar != "" {
-		resolvedCalendar = *calendar
+	if *maxElapsed < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid max elapsed '%s'. Must not be negative\n", *maxElapsed)
+		os.Exit(1)
 	}
-	output.SetDateOptions(resolvedDateStyle, resolvedCalendar)
 
-	vatNumber := flag.Arg(0)
+	// Resolve VIES environment
+	resolvedEnv := resolveSetting(explicit["env"], *env, prof.Env, cfg.Env, "prod")
+	if resolvedEnv != "prod" && resolvedEnv != "test" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid environment '%s'. Supported environments: prod, test\n", resolvedEnv)
+		os.Exit(1)
+	}
+
+	// Resolve SOAP version
+	resolvedSOAPVersion := vies.SOAPVersion(resolveSetting(explicit["soap-version"], *soapVer, prof.SOAPVersion, cfg.SOAPVersion, string(vies.SOAP11)))
+	if resolvedSOAPVersion != vies.SOAP11 && resolvedSOAPVersion != vies.SOAP12 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid SOAP version '%s'. Supported versions: 1.1, 1.2\n", resolvedSOAPVersion)
+		os.Exit(1)
+	}
 
-	// Validate output format
-	if *format != "plain" && *format != "json" {
-		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: plain, json\n", *format)
+	// Resolve how Greek numbers are reported
+	resolvedGreekPrefix := resolveSetting(explicit["greek-prefix"], *greekPfx, prof.GreekPrefix, cfg.GreekPrefix, "canonical")
+	if resolvedGreekPrefix != "canonical" && resolvedGreekPrefix != "input" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid Greek prefix '%s'. Supported values: canonical, input\n", resolvedGreekPrefix)
 		os.Exit(1)
 	}
 
-	// Validate timeout
-	if *timeout < 1 {
-		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", *timeout)
+	resolvedDefaultCountry := resolveSetting(explicit["default-country"], *defCountry, prof.DefaultCountry, cfg.DefaultCountry, "")
+	if err := validateDefaultCountry(resolvedDefaultCountry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Create VIES client
-	client := vies.NewClient(
-		vies.WithTimeout(time.Duration(*timeout)*time.Second),
-		vies.WithVerbose(*verbose),
-	)
+	clientOptions := []vies.ClientOption{
+		vies.WithTimeout(time.Duration(resolvedTimeout) * time.Second),
+		vies.WithVerbose(verboseOutput),
+		vies.WithRedact(redactOutput),
+		vies.WithSOAPVersion(resolvedSOAPVersion),
+	}
+	if resolvedEnv == "test" {
+		clientOptions = append(clientOptions, vies.WithTestService())
+	}
+	if cfg.Endpoint != "" {
+		clientOptions = append(clientOptions, vies.WithEndpoint(cfg.Endpoint))
+	}
+	if resolvedGreekPrefix == "input" {
+		clientOptions = append(clientOptions, vies.WithInputCountryPrefix())
+	}
+	if resolvedDefaultCountry != "" {
+		clientOptions = append(clientOptions, vies.WithDefaultCountry(resolvedDefaultCountry))
+	}
+	if *printResp {
+		clientOptions = append(clientOptions, vies.WithResponseDump(os.Stderr))
+	}
+	if *determin {
+		clientOptions = append(clientOptions, vies.WithLogger(log.New(os.Stderr, "[VIES] ", 0)))
+	}
+	resolvedMaxPerDay := resolveSetting(explicit["max-requests-per-day"], *maxPerDay, prof.MaxRequestsPerDay, cfg.MaxRequestsPerDay, 0)
+	if resolvedMaxPerDay > 0 {
+		clientOptions = append(clientOptions, vies.WithRequestBudget(dailyBudget(resolvedMaxPerDay)))
+	}
+	client := vies.NewClient(clientOptions...)
+
+	maintenance, err := vies.ParseMaintenanceSchedule(*windows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var requestOptions []vies.RequestOption
+	if cfg.Requester != "" {
+		requestOptions = append(requestOptions, vies.WithRequester(cfg.Requester))
+	}
+
+	if *appendOut && *outputPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --append requires --output\n")
+		os.Exit(1)
+	}
+	if *outputPath != "" {
+		f, err := createOutput(*outputPath, *appendOut)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outFile, stdout = f, f
+	}
 
-	// Validate VAT number
 	ctx := context.Background()
-	result, err := client.CheckVAT(ctx, vatNumber)
+
+	// Dry run: show the exact request instead of sending it
+	if *printReq {
+		printRequest(ctx, client, vatNumber, resolvedFormat, requestOptions)
+		exit(0)
+	}
+
+	// Warn about checks during a known maintenance window of the member
+	// state the check goes to; malformed numbers fail in CheckVAT below
+	if country, err := client.CountryCode(vatNumber); err == nil {
+		if end, ok := maintenance.Active(country, time.Now()); ok {
+			fmt.Fprintf(os.Stderr, "Warning: %s is in a known maintenance window until %s; VIES may report it as unavailable\n",
+				country, end.Format("15:04 MST"))
+		}
+	}
+
+	// Bound the whole validation, independent of the per-request timeout
+	if *maxElapsed > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *maxElapsed)
+		defer cancel()
+	}
+
+	// Validate VAT number, riding out outages if requested
+	var checker vies.Checker = client
+	if *waitFor > 0 || *retries > 0 {
+		checker = &vies.ServiceWaiter{
+			Checker:        client,
+			MaxWait:        *waitFor,
+			MaxRetries:     *retries,
+			InitialBackoff: *retryWait,
+			MaxBackoff:     *retryMax,
+			Maintenance:    maintenance,
+			OnRetry: func(err error, delay time.Duration) {
+				fmt.Fprintf(os.Stderr, "VIES unavailable (%v), retrying in %s\n", err, delay)
+			},
+		}
+	}
+	result, err := checker.CheckVAT(ctx, vatNumber, requestOptions...)
 	if err != nil {
-		handleError(err, *format)
+		handleError(err, resolvedFormat)
 		return
 	}
 
 	// Display result
-	displayResult(result, *format)
+	displayResult(result, resolvedFormat)
+	exit(0)
+}
+
+// dailyBudget returns the request budget shared by all invocations of the
+// current user, stored in the user cache directory
+func dailyBudget(max int) *budget.Daily {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return budget.NewDaily(filepath.Join(dir, "viesquery", "budget.json"), max)
+}
+
+// exit commits the --output file, if any, and terminates with code. The file
+// receives error reports too, just as stdout would.
+func exit(code int) {
+	if outFile != nil {
+		if err := outFile.Commit(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: writing output: %v\n", err)
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+	os.Exit(code)
+}
+
+// printRequest writes the HTTP request CheckVAT would send to stdout
+func printRequest(ctx context.Context, client *vies.Client, vatNumber, format string, options []vies.RequestOption) {
+	req, err := client.NewRequest(ctx, vatNumber, options...)
+	if err != nil {
+		handleError(err, format)
+		return
+	}
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(2)
+	}
+	fmt.Fprintf(stdout, "%s\n", dump)
+}
+
+// config holds persistent settings read from the JSON config file
+type config struct {
+	Format     string `json:"format"`
+	Timeout    int    `json:"timeout"`
+	Verbose    bool   `json:"verbose"`
+	DateStyle  string `json:"dateStyle"`
+	DateFormat string `json:"dateFormat"`
+	Calendar   string `json:"calendar"`
+	TimeZone   string `json:"timeZone"`
+	Locale     string `json:"locale"`
+	Redact     bool   `json:"redact"`
+	Endpoint   string `json:"endpoint"`
+	Requester  string `json:"requester"`
+	Env        string `json:"env"`
+	// DefaultCountry is prepended to numbers without a country prefix
+	DefaultCountry string `json:"defaultCountry"`
+	// AddressFormat is "oneline", "multiline" or "postal"
+	AddressFormat string `json:"addressFormat"`
+	// SOAPVersion is "1.1" or "1.2"
+	SOAPVersion string `json:"soapVersion"`
+	// GreekPrefix is "canonical" (EL) or "input"
+	GreekPrefix string `json:"greekPrefix"`
+	// MaxRequestsPerDay caps the requests sent per UTC day; 0 is unlimited
+	MaxRequestsPerDay int `json:"maxRequestsPerDay"`
+	// BatchWindow restricts batch runs to a daily period, e.g.
+	// "02:00-05:00@Europe/Brussels"
+	BatchWindow string `json:"batchWindow"`
+	// Labels replace labels and status words of plain output, e.g.
+	// {"valid": "Gültig"}; see output.LabelNames
+	Labels map[string]string `json:"labels"`
+	// PlainLayout lists the fields of plain output in order; unlisted
+	// fields are left out
+	PlainLayout []string `json:"plainLayout"`
+
+	// Profiles are named sets of settings selected with --profile
+	Profiles map[string]config `json:"profiles"`
+}
+
+// profile returns the named profile; an empty name selects none
+func (c config) profile(name string) (config, error) {
+	if name == "" {
+		return config{}, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		if len(names) == 0 {
+			return config{}, fmt.Errorf("profile '%s' not found: no profiles defined", name)
+		}
+		return config{}, fmt.Errorf("profile '%s' not found. Available profiles: %s", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// resolveSetting picks a setting from its sources, in order of precedence:
+// a flag given on the command line, the selected profile, the environment
+// variable (the flag's default), the config file and the built-in default.
+// Zero values count as unset.
+func resolveSetting[T comparable](explicit bool, flagValue, profileValue, configValue, def T) T {
+	var zero T
+	switch {
+	case explicit && flagValue != zero:
+		return flagValue
+	case profileValue != zero:
+		return profileValue
+	case flagValue != zero:
+		return flagValue
+	case configValue != zero:
+		return configValue
+	}
+	return def
+}
+
+// withProfile returns the config with the settings of profile p applied on top
+func (c config) withProfile(p config) config {
+	if p.Format != "" {
+		c.Format = p.Format
+	}
+	if p.Timeout != 0 {
+		c.Timeout = p.Timeout
+	}
+	if p.DateStyle != "" {
+		c.DateStyle = p.DateStyle
+	}
+	if p.DateFormat != "" {
+		c.DateFormat = p.DateFormat
+	}
+	if p.Calendar != "" {
+		c.Calendar = p.Calendar
+	}
+	if p.TimeZone != "" {
+		c.TimeZone = p.TimeZone
+	}
+	if p.Locale != "" {
+		c.Locale = p.Locale
+	}
+	if p.AddressFormat != "" {
+		c.AddressFormat = p.AddressFormat
+	}
+	if p.DefaultCountry != "" {
+		c.DefaultCountry = p.DefaultCountry
+	}
+	if p.Endpoint != "" {
+		c.Endpoint = p.Endpoint
+	}
+	if p.Requester != "" {
+		c.Requester = p.Requester
+	}
+	if p.SOAPVersion != "" {
+		c.SOAPVersion = p.SOAPVersion
+	}
+	if p.GreekPrefix != "" {
+		c.GreekPrefix = p.GreekPrefix
+	}
+	if p.MaxRequestsPerDay != 0 {
+		c.MaxRequestsPerDay = p.MaxRequestsPerDay
+	}
+	if p.Env != "" {
+		c.Env = p.Env
+	}
+	if p.BatchWindow != "" {
+		c.BatchWindow = p.BatchWindow
+	}
+	if len(p.PlainLayout) > 0 {
+		c.PlainLayout = p.PlainLayout
+	}
+	if len(p.Labels) > 0 {
+		labels := make(map[string]string, len(c.Labels)+len(p.Labels))
+		maps.Copy(labels, c.Labels)
+		maps.Copy(labels, p.Labels)
+		c.Labels = labels
+	}
+	c.Verbose = c.Verbose || p.Verbose
+	c.Redact = c.Redact || p.Redact
+	c.Profiles = nil
+	return c
+}
+
+// vatNumberArgument returns the VAT number to check from the command-line
+// arguments: a full VAT number, also when unquoted spaces split it into
+// several arguments, or a country code and a number without prefix, as
+// separate arguments or with --country. A number that already carries the
+// given prefix is kept as it is.
+func vatNumberArgument(args []string, country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" && len(args) > 1 && isCountryArgument(args[0]) {
+		country, args = strings.ToUpper(args[0]), args[1:]
+	}
+	number := strings.TrimSpace(strings.Join(args, " "))
+	if country == "" {
+		return number, nil
+	}
+
+	code := country
+	if code == "GR" {
+		code = "EL"
+	}
+	if _, err := vies.GetCountryInfo(code); err != nil {
+		return "", fmt.Errorf("Invalid country '%s'. Expected a member state code such as DE, followed by the number", country)
+	}
+	if strings.HasPrefix(strings.ToUpper(number), country) {
+		return number, nil
+	}
+	return country + number, nil
+}
+
+// isCountryArgument reports whether a separate argument is a country code,
+// i.e. two letters
+func isCountryArgument(arg string) bool {
+	return len(arg) == 2 && unicode.IsLetter(rune(arg[0])) && unicode.IsLetter(rune(arg[1]))
+}
+
+// validateDefaultCountry rejects a default country that is not a member
+// state code; an empty code disables the default.
+func validateDefaultCountry(country string) error {
+	code := strings.ToUpper(strings.TrimSpace(country))
+	if code == "" {
+		return nil
+	}
+	if code == "GR" {
+		code = "EL"
+	}
+	if _, err := vies.GetCountryInfo(code); err != nil {
+		return fmt.Errorf("Invalid default country '%s'. Expected a member state code such as IT", country)
+	}
+	return nil
+}
+
+// resolveConfigPath returns path, or the default config file location if
+// it is empty
+func resolveConfigPath(path string) string {
+	if path == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "viesquery", "config.json")
+		}
+	}
+	return path
 }
 
 // loadConfig reads a JSON config file if present and returns the values; on error returns empty defaults
-func loadConfig(path string) struct {
-	Format    string `json:"format"`
-	Timeout   int    `json:"timeout"`
-	Verbose   bool   `json:"verbose"`
-	DateStyle string `json:"dateStyle"`
-	Calendar  string `json:"calendar"`
-} {
-	type cfgT struct {
-		Format    string `json:"format"`
-		Timeout   int    `json:"timeout"`
-		Verbose   bool   `json:"verbose"`
-		DateStyle string `json:"dateStyle"`
-		Calendar  string `json:"calendar"`
-	}
-	var cfg cfgT
+func loadConfig(path string) config {
+	var cfg config
 	if path == "" {
 		return cfg
 	}
@@ -167,50 +692,79 @@ func loadConfig(path string) struct {
 }
 
 func handleError(err error, format string) {
-	formatter := output.NewManager()
-	f, fErr := formatter.GetFormatter(format)
+	f, fErr := output.GetFormatter(format)
 	if fErr != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(2)
+		exit(2)
 	}
 
 	output, formatErr := f.FormatError(err)
 	if formatErr != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(2)
+		exit(2)
 	}
 
-	fmt.Print(output)
+	fmt.Fprint(stdout, output)
+	exit(errorExitCode(err))
+}
 
-	// Set appropriate exit code based on error type
-	switch e := err.(type) {
-	case *vies.ValidationError:
-		os.Exit(3) // Invalid VAT format
-	case *vies.ServiceError:
-		if e.Code == vies.ErrServiceUnavailable {
-			os.Exit(4) // Service unavailable
-		}
-		os.Exit(2) // Network/API error
-	default:
-		os.Exit(2) // General error
+// errorExitCode returns the exit code for err based on the error catalog;
+// the VIES error may be wrapped
+func errorExitCode(err error) int {
+	var validationErr *vies.ValidationError
+	var serviceErr *vies.ServiceError
+	switch {
+	case errors.As(err, &validationErr):
+		return exitCodeFor(validationErr.Code, 3) // Invalid VAT format
+	case errors.As(err, &serviceErr):
+		return exitCodeFor(serviceErr.Code, 2) // Network/API error
 	}
+	return 2 // General error
+}
+
+// exitCodeFor returns the catalog exit code for an error code, or fallback if unknown
+func exitCodeFor(code string, fallback int) int {
+	if info, ok := vies.LookupError(code); ok {
+		return info.ExitCode
+	}
+	return fallback
 }
 
 func displayResult(result *vies.CheckVatResult, format string) {
-	formatter := output.NewManager()
-	f, err := formatter.GetFormatter(format)
+	if output.HasQuery() {
+		out, err := output.QueryResult(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(2)
+		}
+		fmt.Fprint(stdout, out)
+		return
+	}
+
+	f, err := output.GetFormatter(format)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(2)
+		exit(2)
 	}
 
 	output, err := f.Format(result)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
-		os.Exit(2)
+		exit(2)
 	}
 
-	fmt.Print(output)
+	fmt.Fprint(stdout, output)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // getEnvString returns environment variable value or default
@@ -231,6 +785,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration returns environment variable as duration (e.g. 90s, 2h) or default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 // getEnvBool returns environment variable as boolean or default
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
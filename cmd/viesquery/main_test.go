@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestResolveSetting(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		explicit                             bool
+		flagValue, profileValue, configValue string
+		want                                 string
+	}{
+		{"explicit flag wins", true, "xml", "json", "csv", "xml"},
+		{"profile beats the environment", false, "xml", "json", "csv", "json"},
+		{"environment beats the config", false, "xml", "", "csv", "xml"},
+		{"config beats the default", false, "", "", "csv", "csv"},
+		{"default", false, "", "", "", "plain"},
+		{"empty explicit flag is unset", true, "", "json", "csv", "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSetting(tt.explicit, tt.flagValue, tt.profileValue, tt.configValue, "plain"); got != tt.want {
+				t.Errorf("resolveSetting() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := resolveSetting(false, 45, 60, 10, 30); got != 60 {
+		t.Errorf("timeout = %d, want the profile's 60", got)
+	}
+	if got := resolveSetting(true, 45, 60, 10, 30); got != 45 {
+		t.Errorf("timeout = %d, want the flag's 45", got)
+	}
+}
+
+func TestConfigProfile(t *testing.T) {
+	cfg := config{Profiles: map[string]config{
+		"client-a": {Requester: "DE123456788", Format: "json"},
+		"client-b": {Timeout: 60},
+	}}
+
+	p, err := cfg.profile("client-a")
+	if err != nil || p.Requester != "DE123456788" {
+		t.Errorf("profile(client-a) = %+v, %v", p, err)
+	}
+	if p, err := cfg.profile(""); err != nil || !reflect.DeepEqual(p, config{}) {
+		t.Errorf("profile(\"\") = %+v, %v; want no profile", p, err)
+	}
+
+	_, err = cfg.profile("client-c")
+	if err == nil || !strings.Contains(err.Error(), "'client-c' not found") || !strings.Contains(err.Error(), "client-a, client-b") {
+		t.Errorf("expected the unknown profile and the available ones, got %v", err)
+	}
+	if _, err := (config{}).profile("client-a"); err == nil || !strings.Contains(err.Error(), "no profiles defined") {
+		t.Errorf("expected no profiles to be reported, got %v", err)
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	cfg := config{
+		Format:  "plain",
+		Timeout: 30,
+		Locale:  "de",
+		Redact:  true,
+		Labels:  map[string]string{"valid": "Gültig", "name": "Name"},
+	}
+	p := config{
+		Format:  "json",
+		Timeout: 60,
+		Verbose: true,
+		Labels:  map[string]string{"name": "Firma"},
+	}
+
+	got := cfg.withProfile(p)
+	if got.Format != "json" || got.Timeout != 60 || got.Locale != "de" {
+		t.Errorf("expected the profile to override set fields only: %+v", got)
+	}
+	if !got.Verbose || !got.Redact {
+		t.Errorf("expected verbose and redact from either side: %+v", got)
+	}
+	if want := map[string]string{"valid": "Gültig", "name": "Firma"}; !reflect.DeepEqual(got.Labels, want) {
+		t.Errorf("Labels = %v, want %v", got.Labels, want)
+	}
+	if cfg.Labels["name"] != "Name" {
+		t.Error("withProfile modified the top-level labels")
+	}
+}
+
+func TestPrintRequest(t *testing.T) {
+	defer func(w io.Writer) { stdout = w }(stdout)
+	var out strings.Builder
+	stdout = &out
+
+	client := vies.NewClient(vies.WithEndpoint("https://vies.example/checkVatService"), vies.WithRedact(true))
+	printRequest(context.Background(), client, "DE136695976", "plain", []vies.RequestOption{vies.WithRequester("FR40303265045")})
+
+	dump := out.String()
+	for _, want := range []string{
+		"POST /checkVatService HTTP/1.1\r\n",
+		"Host: vies.example\r\n",
+		"Soapaction: checkVatApprox\r\n",
+		"vatNumber>136695976<",
+		"requesterVatNumber>40303265045<",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("request dump lacks %q:\n%s", want, dump)
+		}
+	}
+}
+
+func TestVATNumberArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{"single argument", []string{"DE136695976"}, "", "DE136695976", false},
+		{"quoted with spaces", []string{"DE 136 695 976"}, "", "DE 136 695 976", false},
+		{"split by spaces", []string{"DE", "136", "695", "976"}, "", "DE136 695 976", false},
+		{"split after the prefix", []string{"DE136", "695", "976"}, "", "DE136 695 976", false},
+		{"country and number", []string{"DE", "136695976"}, "", "DE136695976", false},
+		{"lower-case country", []string{"de", "136695976"}, "", "DE136695976", false},
+		{"country and prefixed number", []string{"DE", "DE136695976"}, "", "DE136695976", false},
+		{"greek country", []string{"GR", "094259216"}, "", "GR094259216", false},
+		{"pasted label", []string{"USt-IdNr.", "de", "136.695.976"}, "", "USt-IdNr. de 136.695.976", false},
+		{"--country", []string{"136695976"}, "de", "DE136695976", false},
+		{"--country with split number", []string{"136", "695", "976"}, "DE", "DE136 695 976", false},
+		{"--country with prefixed number", []string{"DE136695976"}, "DE", "DE136695976", false},
+		{"unknown country", []string{"XX", "136695976"}, "", "", true},
+		{"unknown --country", []string{"136695976"}, "XX", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vatNumberArgument(tt.args, tt.country)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("vatNumberArgument(%q, %q) = %q, %v; want %q", tt.args, tt.country, got, err, tt.want)
+			}
+			if err == nil {
+				if _, _, err := vies.ParseVATNumber(got); err != nil {
+					t.Errorf("ParseVATNumber(%q) error = %v", got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile writes to a temporary file next to the destination and renames
+// it into place on Commit, so readers never see a partial file
+type atomicFile struct {
+	*os.File
+	path string
+	// existing reports whether appended-to content was already present
+	existing bool
+}
+
+// createOutput opens path for atomic writing. With appendMode the current
+// contents of path, if any, are copied into the temporary file first.
+func createOutput(path string, appendMode bool) (*atomicFile, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	f := &atomicFile{File: tmp, path: path}
+
+	if info, err := os.Stat(path); err == nil {
+		// Keep the permissions of the file being replaced
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			f.Abort()
+			return nil, err
+		}
+	} else {
+		tmp.Chmod(0o644)
+	}
+
+	if appendMode {
+		src, err := os.Open(path)
+		switch {
+		case err == nil:
+			n, err := io.Copy(tmp, src)
+			src.Close()
+			if err != nil {
+				f.Abort()
+				return nil, err
+			}
+			f.existing = n > 0
+		case !os.IsNotExist(err):
+			f.Abort()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Commit flushes the temporary file and renames it over the destination
+func (f *atomicFile) Commit() error {
+	if err := f.Sync(); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards the temporary file, leaving the destination untouched
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/internal/batch"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestAtomicFileCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := createOutput(path, false)
+	if err != nil {
+		t.Fatalf("createOutput() error = %v", err)
+	}
+	if f.existing {
+		t.Error("expected existing to be false outside append mode")
+	}
+	f.WriteString("new\n")
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Errorf("destination changed before Commit: %q", data)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("destination = %q, want the new contents", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want the replaced file's 0600", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestAtomicFileAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	if err := os.WriteFile(path, []byte("original\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := createOutput(path, true)
+	if err != nil {
+		t.Fatalf("createOutput() error = %v", err)
+	}
+	f.WriteString("partial")
+	f.Abort()
+
+	if data, _ := os.ReadFile(path); string(data) != "original\n" {
+		t.Errorf("destination = %q, want the original left intact", data)
+	}
+	assertNoTempFiles(t, dir)
+
+	// Aborting the first write of a new file creates nothing
+	fresh := filepath.Join(dir, "fresh.csv")
+	if f, err = createOutput(fresh, false); err != nil {
+		t.Fatalf("createOutput() error = %v", err)
+	}
+	f.Abort()
+	if _, err := os.Stat(fresh); !os.IsNotExist(err) {
+		t.Errorf("expected no %s after Abort, got %v", fresh, err)
+	}
+}
+
+func TestAtomicFileAppendSkipsHeader(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+	path := filepath.Join(t.TempDir(), "report.csv")
+
+	appendRun := func(input string) {
+		t.Helper()
+		f, err := createOutput(path, true)
+		if err != nil {
+			t.Fatalf("createOutput() error = %v", err)
+		}
+		_, err = batch.Run(context.Background(), checker, strings.NewReader(input), f, batch.Options{
+			Fields:           []string{"valid"},
+			OmitReportHeader: f.existing,
+		})
+		if err != nil {
+			f.Abort()
+			t.Fatalf("batch.Run() error = %v", err)
+		}
+		if err := f.Commit(); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+	}
+	appendRun("vat\nDE266201128\n")
+	appendRun("vat\nFR12345678901\n")
+
+	want := "vat,valid\nDE266201128,true\nFR12345678901,false\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("report =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// assertNoTempFiles fails if temporary files of createOutput remain in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
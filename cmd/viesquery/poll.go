@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"l22.io/viesquery/pkg/output"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// exitPollDeadline is the exit code of poll-until-valid when the number is
+// still not valid at the deadline
+const exitPollDeadline = 6
+
+// runPollUntilValid implements the "poll-until-valid" subcommand, which
+// re-checks a VAT number at an interval until VIES reports it as valid, for
+// newly registered numbers that take days to appear
+func runPollUntilValid(args []string) {
+	fs := flag.NewFlagSet("poll-until-valid", flag.ExitOnError)
+	interval := dayDuration(6 * time.Hour)
+	deadline := dayDuration(14 * 24 * time.Hour)
+	fs.Var(&interval, "interval", "Time between checks, e.g. 30m, 6h or 1d")
+	fs.Var(&deadline, "deadline", "Give up after this long, e.g. 14d")
+	format := fs.String("format", getEnvString("VIESQUERY_FORMAT", "plain"), "Output format ("+strings.Join(output.SupportedFormats(), ", ")+")")
+	timeout := fs.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 30), "Request timeout in seconds")
+	verbose := fs.Bool("verbose", getEnvBool("VIESQUERY_VERBOSE", false), "Enable verbose logging")
+	redact := fs.Bool("redact", getEnvBool("VIESQUERY_REDACT", false), "Mask trader names and addresses in output and verbose logs")
+	env := fs.String("env", getEnvString("VIESQUERY_ENV", "prod"), "VIES environment: prod, or test for the acceptance service")
+	logFile := addLogFileFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s poll-until-valid [flags] VAT_NUMBER\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Check a VAT number every --interval until VIES reports it as valid (exit 0)\n")
+		fmt.Fprintf(os.Stderr, "or --deadline passes (exit %d). Service errors are retried at the next\n", exitPollDeadline)
+		fmt.Fprintf(os.Stderr, "interval; format errors end the poll at once.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: VAT number required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if interval <= 0 || deadline <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --interval and --deadline must be greater than 0\n")
+		os.Exit(1)
+	}
+	if _, err := output.GetFormatter(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: %s\n", *format, strings.Join(output.SupportedFormats(), ", "))
+		os.Exit(1)
+	}
+	if *timeout < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", *timeout)
+		os.Exit(1)
+	}
+	if *env != "prod" && *env != "test" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid environment '%s'. Supported environments: prod, test\n", *env)
+		os.Exit(1)
+	}
+
+	progress, clientLog, err := logFile.open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	output.SetRedaction(*redact)
+	output.SetVerbose(*verbose)
+
+	clientOptions := []vies.ClientOption{
+		vies.WithTimeout(time.Duration(*timeout) * time.Second),
+		vies.WithVerbose(*verbose),
+		vies.WithRedact(*redact),
+		vies.WithLogger(clientLog),
+	}
+	if *env == "test" {
+		clientOptions = append(clientOptions, vies.WithTestService())
+	}
+	client := vies.NewClient(clientOptions...)
+
+	vatNumber := fs.Arg(0)
+	p := newPoller(client, progress, time.Duration(interval), time.Duration(deadline))
+	result, err := p.pollUntilValid(context.Background(), vatNumber)
+	if err != nil {
+		handleError(err, *format)
+		return
+	}
+	displayResult(result, *format)
+	exit(pollExitCode(result))
+}
+
+// pollExitCode returns the exit code for the final result of a poll
+func pollExitCode(result *vies.CheckVatResult) int {
+	if !result.Valid {
+		return exitPollDeadline
+	}
+	return 0
+}
+
+// poller re-checks a VAT number at a fixed interval until a deadline
+type poller struct {
+	checker  vies.Checker
+	logger   *log.Logger
+	interval time.Duration
+	deadline time.Duration
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+func newPoller(checker vies.Checker, logger *log.Logger, interval, deadline time.Duration) *poller {
+	return &poller{
+		checker:  checker,
+		logger:   logger,
+		interval: interval,
+		deadline: deadline,
+		now:      time.Now,
+		after:    time.After,
+	}
+}
+
+// pollUntilValid checks vatNumber every interval until it is valid or the
+// deadline passes, logging each failed attempt. It returns the last result,
+// or the last error if no check succeeded at the end. Malformed numbers are
+// reported at once.
+func (p *poller) pollUntilValid(ctx context.Context, vatNumber string) (*vies.CheckVatResult, error) {
+	end := p.now().Add(p.deadline)
+	for {
+		result, err := p.checker.CheckVAT(ctx, vatNumber)
+		var validationErr *vies.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return nil, err
+		case err == nil && result.Valid:
+			return result, nil
+		}
+
+		next := p.now().Add(p.interval)
+		if next.After(end) {
+			return result, err
+		}
+		status := "not valid yet"
+		if err != nil {
+			status = fmt.Sprintf("check failed (%v)", err)
+		}
+		p.logger.Printf("%s: %s, next check at %s", vatNumber, status, next.Format(time.RFC3339))
+
+		select {
+		case <-p.after(p.interval):
+		case <-ctx.Done():
+			return result, err
+		}
+	}
+}
+
+// dayDuration is a duration flag that also accepts whole days, e.g. "14d" or "1d12h"
+type dayDuration time.Duration
+
+func (d *dayDuration) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *dayDuration) Set(s string) error {
+	var total time.Duration
+	if before, after, ok := strings.Cut(s, "d"); ok {
+		n, err := strconv.Atoi(before)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		total = time.Duration(n) * 24 * time.Hour
+		s = after
+	}
+	if s != "" {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		total += parsed
+	}
+	*d = dayDuration(total)
+	return nil
+}
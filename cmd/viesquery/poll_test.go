@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+// fakePoller returns a poller whose clock advances by every wait instead of
+// sleeping; onWait runs before each wait returns, with the waits so far
+func fakePoller(checker vies.Checker, interval, deadline time.Duration, onWait func(waits []time.Duration) bool) (*poller, *[]time.Duration) {
+	now := time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	p := newPoller(checker, log.New(io.Discard, "", 0), interval, deadline)
+	p.now = func() time.Time { return now }
+	p.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		if onWait == nil || onWait(waits) {
+			now = now.Add(d)
+			ch <- now
+		}
+		return ch
+	}
+	return p, &waits
+}
+
+func TestPollUntilValid(t *testing.T) {
+	mock := viesmock.New()
+	p, waits := fakePoller(mock, 6*time.Hour, 14*24*time.Hour, func(waits []time.Duration) bool {
+		if len(waits) == 2 {
+			mock.SetValid("DE136695976", "Example GmbH", "Berlin")
+		}
+		return true
+	})
+
+	result, err := p.pollUntilValid(context.Background(), "DE136695976")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || len(mock.Calls()) != 3 {
+		t.Errorf("expected the third check to be valid, got %+v after %d checks", result, len(mock.Calls()))
+	}
+	if want := []time.Duration{6 * time.Hour, 6 * time.Hour}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+	if code := pollExitCode(result); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+}
+
+func TestPollUntilValidDeadline(t *testing.T) {
+	mock := viesmock.New()
+	p, waits := fakePoller(mock, 6*time.Hour, 14*time.Hour, nil)
+
+	result, err := p.pollUntilValid(context.Background(), "DE136695976")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Checks at 0h, 6h and 12h; the next one at 18h is past the deadline
+	if len(mock.Calls()) != 3 || len(*waits) != 2 {
+		t.Errorf("got %d checks and %d waits, want 3 and 2", len(mock.Calls()), len(*waits))
+	}
+	if code := pollExitCode(result); code != exitPollDeadline {
+		t.Errorf("exit code = %d, want %d", code, exitPollDeadline)
+	}
+}
+
+func TestPollUntilValidErrors(t *testing.T) {
+	t.Run("service errors are retried", func(t *testing.T) {
+		mock := viesmock.New()
+		mock.FailAll(&vies.ServiceError{Code: vies.CodeServiceUnavailable, Message: "VIES service unavailable"})
+		p, _ := fakePoller(mock, time.Hour, 3*time.Hour, func(waits []time.Duration) bool {
+			if len(waits) == 2 {
+				mock.FailAll(nil)
+				mock.SetValid("DE136695976", "Example GmbH", "Berlin")
+			}
+			return true
+		})
+		result, err := p.pollUntilValid(context.Background(), "DE136695976")
+		if err != nil || !result.Valid {
+			t.Errorf("expected a valid result after the outage, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("last error at the deadline", func(t *testing.T) {
+		mock := viesmock.New()
+		mock.FailAll(&vies.ServiceError{Code: vies.CodeServiceUnavailable, Message: "VIES service unavailable"})
+		p, _ := fakePoller(mock, time.Hour, 2*time.Hour, nil)
+		if _, err := p.pollUntilValid(context.Background(), "DE136695976"); !errors.Is(err, vies.ErrServiceUnavailable) {
+			t.Errorf("expected the last service error, got %v", err)
+		}
+		if len(mock.Calls()) != 3 {
+			t.Errorf("got %d checks, want 3", len(mock.Calls()))
+		}
+	})
+
+	t.Run("format errors end the poll", func(t *testing.T) {
+		mock := viesmock.New()
+		p, waits := fakePoller(mock, time.Hour, 24*time.Hour, nil)
+		var validationErr *vies.ValidationError
+		if _, err := p.pollUntilValid(context.Background(), "DE123"); !errors.As(err, &validationErr) {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+		if len(*waits) != 0 {
+			t.Errorf("expected no retries, got %d waits", len(*waits))
+		}
+	})
+}
+
+func TestPollUntilValidCancel(t *testing.T) {
+	mock := viesmock.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The second wait never ends but is cancelled
+	p, _ := fakePoller(mock, time.Hour, 24*time.Hour, func(waits []time.Duration) bool {
+		if len(waits) == 2 {
+			cancel()
+			return false
+		}
+		return true
+	})
+
+	result, err := p.pollUntilValid(ctx, "DE136695976")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid || len(mock.Calls()) != 2 {
+		t.Errorf("expected the poll to stop after 2 checks, got %+v after %d", result, len(mock.Calls()))
+	}
+	if code := pollExitCode(result); code != exitPollDeadline {
+		t.Errorf("exit code = %d, want %d", code, exitPollDeadline)
+	}
+}
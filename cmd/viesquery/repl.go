@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"l22.io/viesquery/pkg/output"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// runREPL implements the "repl" subcommand: it reads VAT numbers line by
+// line and checks each with one long-lived client, so the connection to
+// VIES and the result cache stay warm between lookups
+func runREPL(args []string) {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	format := fs.String("format", getEnvString("VIESQUERY_FORMAT", "plain"), "Output format ("+strings.Join(output.SupportedFormats(), ", ")+")")
+	timeout := fs.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 30), "Request timeout in seconds")
+	verbose := fs.Bool("verbose", getEnvBool("VIESQUERY_VERBOSE", false), "Enable verbose logging")
+	redact := fs.Bool("redact", getEnvBool("VIESQUERY_REDACT", false), "Mask trader names and addresses in output and verbose logs")
+	env := fs.String("env", getEnvString("VIESQUERY_ENV", "prod"), "VIES environment: prod, or test for the acceptance service")
+	cacheTTL := fs.Duration("cache-ttl", time.Hour, "How long a result is reused for repeated lookups in the session (0: until exit)")
+	cacheInvalidTTL := fs.Duration("cache-invalid-ttl", 0, "How long an invalid result is reused (0: same as --cache-ttl)")
+	cacheErrorTTL := fs.Duration("cache-error-ttl", 0, "How long a VIES fault such as MS_UNAVAILABLE is reused (0: not cached)")
+	noCache := fs.Bool("no-cache", false, "Ask VIES for every lookup, also for repeated numbers")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s repl [flags]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Check VAT numbers as they are typed, one per line, until EOF or \"quit\".\n")
+		fmt.Fprintf(os.Stderr, "All lookups share one VIES connection and an in-memory result cache;\n")
+		fmt.Fprintf(os.Stderr, "\"refresh NUMBER\" asks VIES again and updates the cached result.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	formatter, err := output.GetFormatter(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Supported formats: %s\n", *format, strings.Join(output.SupportedFormats(), ", "))
+		os.Exit(1)
+	}
+	if *timeout < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", *timeout)
+		os.Exit(1)
+	}
+	if *env != "prod" && *env != "test" {
+		fmt.Fprintf(os.Stderr, "Error: Invalid environment '%s'. Supported environments: prod, test\n", *env)
+		os.Exit(1)
+	}
+	for _, ttl := range []time.Duration{*cacheTTL, *cacheInvalidTTL, *cacheErrorTTL} {
+		if ttl < 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid cache TTL '%s'. Must not be negative\n", ttl)
+			os.Exit(1)
+		}
+	}
+
+	output.SetRedaction(*redact)
+	output.SetVerbose(*verbose)
+
+	clientOptions := []vies.ClientOption{
+		vies.WithTimeout(time.Duration(*timeout) * time.Second),
+		vies.WithVerbose(*verbose),
+		vies.WithRedact(*redact),
+	}
+	if !*noCache {
+		clientOptions = append(clientOptions,
+			vies.WithCache(vies.NewMemoryCache(), *cacheTTL),
+			vies.WithNegativeCache(*cacheInvalidTTL, *cacheErrorTTL))
+	}
+	if *env == "test" {
+		clientOptions = append(clientOptions, vies.WithTestService())
+	}
+	client := vies.NewClient(clientOptions...)
+
+	// Only prompt when a person is typing
+	prompt := ""
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		prompt = "vat> "
+	}
+	repl(context.Background(), client, formatter, os.Stdin, os.Stdout, prompt)
+}
+
+// repl checks each line of in and writes the formatted outcome to out. A
+// line "refresh NUMBER" bypasses the cache for NUMBER and updates it.
+// Errors are reported like results and do not end the session.
+func repl(ctx context.Context, checker vies.Checker, formatter output.Formatter, in io.Reader, out io.Writer, prompt string) {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(os.Stderr, prompt)
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "quit", "exit":
+			return
+		}
+
+		var options []vies.RequestOption
+		if number, ok := strings.CutPrefix(line, "refresh "); ok {
+			line, options = strings.TrimSpace(number), []vies.RequestOption{vies.WithRefresh()}
+		}
+
+		var text string
+		result, err := checker.CheckVAT(ctx, line, options...)
+		if err == nil {
+			text, err = formatter.Format(result)
+		} else {
+			text, err = formatter.FormatError(err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+			continue
+		}
+		fmt.Fprint(out, text)
+		if prompt != "" {
+			fmt.Fprintln(out)
+		}
+	}
+	if prompt != "" {
+		fmt.Fprintln(os.Stderr)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/output"
+	"l22.io/viesquery/pkg/vies"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestRepl(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	mock.SetValid("DE136695976", "Example GmbH", "Berlin")
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithCache(vies.NewMemoryCache(), time.Hour))
+	formatter, err := output.GetFormatter("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := strings.NewReader("DE136695976\n\nDE123\nDE136695976\nrefresh DE136695976\nquit\nFR40303265045\n")
+	var out strings.Builder
+	repl(context.Background(), client, formatter, in, &out, "")
+
+	text := out.String()
+	if got := strings.Count(text, `"valid": true`); got != 3 {
+		t.Errorf("expected 3 valid results, got %d:\n%s", got, text)
+	}
+	if !strings.Contains(text, `"code": "INVALID_FORMAT"`) {
+		t.Errorf("expected the invalid number to be reported:\n%s", text)
+	}
+	if strings.Contains(text, "FR40303265045") {
+		t.Errorf("expected quit to end the session:\n%s", text)
+	}
+	// The repeated check is cached, the refresh is not
+	if got := mock.Calls(); len(got) != 2 {
+		t.Errorf("expected 2 requests to VIES, got %v", got)
+	}
+}
+
+func TestReplEndOfInput(t *testing.T) {
+	mock := viesmock.New()
+	formatter, err := output.GetFormatter("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	repl(context.Background(), mock, formatter, strings.NewReader("FR40303265045"), &out, "")
+	if !strings.Contains(out.String(), `"valid": false`) || len(mock.Calls()) != 1 {
+		t.Errorf("expected the last line without newline to be checked:\n%s", out.String())
+	}
+}
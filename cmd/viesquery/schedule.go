@@ -0,0 +1,225 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"l22.io/viesquery/internal/batch"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// scheduleOptions are the settings shared by the runs of "schedule"
+type scheduleOptions struct {
+	input     string
+	header    http.Header
+	outputDir string
+	compress  bool
+	batch     batch.Options
+}
+
+// runSchedule implements the "schedule" subcommand, a long-lived process
+// that revalidates a CSV file on a cron schedule and writes a dated report
+// for every run
+func runSchedule(args []string) {
+	fs := flag.NewFlagSet("schedule", flag.ExitOnError)
+	inputPath := fs.String("input", "", "CSV or .xlsx file to revalidate, a path or an http(s) URL, read again on every run")
+	var inputHeader headerList
+	fs.Var(&inputHeader, "input-header", "Header sent when the input is an http(s) URL, e.g. \"Authorization: Bearer TOKEN\" (repeatable; VIESQUERY_INPUT_AUTHORIZATION sets Authorization)")
+	cronSpec := fs.String("cron", "", "Cron expression of the runs, e.g. \"0 3 * * MON\" (minute hour day-of-month month day-of-week)")
+	cronTZ := fs.String("cron-tz", "Local", "Time zone of --cron, e.g. Europe/Brussels")
+	outputDir := fs.String("output-dir", ".", "Directory of the dated reports")
+	column := fs.String("column", "", "VAT number column, by header name or 1-based index (default: a column named vat/vat_number/vatNumber, else the first)")
+	noHeader := fs.Bool("no-header", false, "Treat the first row as data instead of a header")
+	delimiter := fs.String("delimiter", ",", "Field delimiter")
+	sheet := fs.String("sheet", "", "Worksheet of .xlsx input, by name or 1-based position (default: the first)")
+	workers := fs.Int("workers", 1, "Number of concurrent VIES requests (VIES recommends about 1 request per second)")
+	timeout := fs.Int("timeout", getEnvInt("VIESQUERY_TIMEOUT", 30), "Request timeout in seconds")
+	verbose := fs.Bool("verbose", getEnvBool("VIESQUERY_VERBOSE", false), "Enable verbose logging")
+	redact := fs.Bool("redact", getEnvBool("VIESQUERY_REDACT", false), "Mask trader names and addresses in the reports and verbose logs")
+	compress := fs.Bool("compress", false, "Gzip the reports")
+	fields := fs.String("fields", "", "Comma-separated result columns to append, in order (default: all but durationMs; requestIdentifier only with --requester)")
+	requester := fs.String("requester", "", "VAT number of the party on whose behalf the checks are made; adds the VIES consultation number as a requestIdentifier column")
+	maxPerDay := fs.Int("max-requests-per-day", getEnvInt("VIESQUERY_MAX_REQUESTS_PER_DAY", 0), "Refuse to send more than this many requests per UTC day, across invocations (0: unlimited)")
+	logFile := addLogFileFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule --input FILE --cron EXPR [flags]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Keep running and validate the VAT numbers in a CSV file at the times given by\n")
+		fmt.Fprintf(os.Stderr, "--cron, writing each report, as by batch, to --output-dir with the date and\n")
+		fmt.Fprintf(os.Stderr, "time of the run in its name. Stop with SIGINT or SIGTERM.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *inputPath == "" || *cronSpec == "" {
+		fmt.Fprintf(os.Stderr, "Error: --input and --cron required\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *inputPath == "-" {
+		fmt.Fprintf(os.Stderr, "Error: --input must be a file, as it is read on every run\n")
+		os.Exit(1)
+	}
+	cron, err := batch.ParseCron(*cronSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	loc, err := time.LoadLocation(*cronTZ)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid time zone '%s': %v\n", *cronTZ, err)
+		os.Exit(1)
+	}
+	if *workers < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid workers '%d'. Must be greater than 0\n", *workers)
+		os.Exit(1)
+	}
+	if *timeout < 1 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid timeout '%d'. Must be greater than 0\n", *timeout)
+		os.Exit(1)
+	}
+	if *requester != "" {
+		if err := vies.ValidateFormat(*requester); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid requester: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid delimiter '%s'. Must be a single character\n", *delimiter)
+		os.Exit(1)
+	}
+	if info, err := os.Stat(*outputDir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "Error: Output directory '%s' does not exist\n", *outputDir)
+		os.Exit(1)
+	}
+
+	progress, clientLog, err := logFile.open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	clientOptions := []vies.ClientOption{
+		vies.WithTimeout(time.Duration(*timeout) * time.Second),
+		vies.WithVerbose(*verbose),
+		vies.WithRedact(*redact),
+		vies.WithLogger(clientLog),
+	}
+	if *maxPerDay > 0 {
+		clientOptions = append(clientOptions, vies.WithRequestBudget(dailyBudget(*maxPerDay)))
+	}
+	client := vies.NewClient(clientOptions...)
+
+	opts := scheduleOptions{
+		input:     *inputPath,
+		header:    inputHeaders(inputHeader),
+		outputDir: *outputDir,
+		compress:  *compress,
+		batch: batch.Options{
+			Column:    *column,
+			NoHeader:  *noHeader,
+			Comma:     comma,
+			Sheet:     *sheet,
+			Workers:   *workers,
+			Redact:    *redact,
+			Fields:    splitList(*fields),
+			Requester: strings.TrimSpace(*requester),
+		},
+	}
+
+	// SIGINT/SIGTERM stop the process; a run in progress keeps the rows
+	// checked so far, as with batch
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		next := cron.Next(time.Now().In(loc))
+		if next.IsZero() {
+			fmt.Fprintf(os.Stderr, "Error: cron expression '%s' never matches\n", cron)
+			os.Exit(1)
+		}
+		progress.Printf("Next run at %s", next.Format("2006-01-02 15:04 MST"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			os.Exit(0)
+		}
+
+		path, summary, err := scheduledRun(ctx, client, next, opts)
+		switch {
+		case errors.Is(err, context.Canceled):
+			progress.Printf("Interrupted: %d rows checked, %d rows not processed, report %s", summary.Rows, summary.NotProcessed, path)
+			os.Exit(130)
+		case err != nil:
+			// A failed run must not end the schedule; the next one may succeed
+			progress.Printf("Run of %s failed: %v", next.Format("2006-01-02 15:04"), err)
+		default:
+			progress.Printf("Checked %d rows: %d valid, %d invalid, %d errors, report %s",
+				summary.Rows, summary.Valid, summary.Invalid, summary.Errors, path)
+		}
+	}
+}
+
+// scheduledRun revalidates the input file once and writes the report for
+// the run at the given time, returning the report path
+func scheduledRun(ctx context.Context, checker vies.Checker, at time.Time, opts scheduleOptions) (string, batch.Summary, error) {
+	input, err := openInput(ctx, opts.input, opts.header)
+	if err != nil {
+		return "", batch.Summary{}, err
+	}
+	defer input.Close()
+
+	path := reportPath(opts.outputDir, opts.input, at, opts.compress)
+	f, err := createOutput(path, false)
+	if err != nil {
+		return "", batch.Summary{}, err
+	}
+	var report io.Writer = f
+	var gz *gzip.Writer
+	if opts.compress {
+		gz = gzip.NewWriter(f)
+		report = gz
+	}
+
+	summary, err := batch.Run(ctx, checker, input, report, opts.batch)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		f.Abort()
+		return "", summary, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			f.Abort()
+			return "", summary, fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	if err := f.Commit(); err != nil {
+		return "", summary, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, summary, err
+}
+
+// reportPath names the report of a scheduled run after the input file and
+// the time of the run, e.g. suppliers-2025-01-13T0300.csv
+func reportPath(dir, input string, at time.Time, compress bool) string {
+	base := filepath.Base(redactURL(input))
+	name := strings.TrimSuffix(base, filepath.Ext(base)) + "-" + at.Format("2006-01-02T1504") + ".csv"
+	if compress {
+		name += ".gz"
+	}
+	return filepath.Join(dir, name)
+}
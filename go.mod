@@ -1,3 +1,7 @@
 module l22.io/viesquery
 
 go 1.25.1
+
+require github.com/itchyny/gojq v0.12.19
+
+require github.com/itchyny/timefmt-go v0.1.8 // indirect
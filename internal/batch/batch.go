@@ -0,0 +1,453 @@
+// Package batch validates many VAT numbers from CSV or .xlsx input and
+// writes a reconciled CSV report.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/internal/xlsx"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// ResultColumns are appended to every input row, in this order. The
+// requestIdentifier column is only included by default with
+// Options.Requester, as VIES only issues consultation numbers then;
+// durationMs, the milliseconds spent on the row including retries and rate
+// limit waits, only when selected with Options.Fields.
+var ResultColumns = []string{"valid", "name", "address", "errorCode", "errorMessage", "requestIdentifier", "durationMs"}
+
+// vatColumnNames are header names recognized as the VAT number column when
+// no column is given explicitly
+var vatColumnNames = []string{"vat", "vatnumber", "vat_number", "vat number", "vatid", "vat_id", "vat id"}
+
+// Error codes reported in the errorCode column for rows cut short by
+// Options.ItemTimeout and Options.Deadline
+const (
+	CodeItemTimeout      = "ITEM_TIMEOUT"
+	CodeDeadlineExceeded = "BATCH_DEADLINE"
+)
+
+// Errors reported for rows cut short by Options.ItemTimeout and
+// Options.Deadline
+var (
+	ErrItemTimeout      = errors.New("no result within the item timeout")
+	ErrDeadlineExceeded = errors.New("not checked before the batch deadline")
+)
+
+// Options configures a batch run
+type Options struct {
+	// Column selects the VAT number column by header name or 1-based index.
+	// Empty means the first header in vatColumnNames, else the first column.
+	Column string
+	// NoHeader treats the first row as data
+	NoHeader bool
+	// Comma is the field delimiter; ',' if zero. Reports of .xlsx input
+	// are always comma-separated.
+	Comma rune
+	// Sheet selects the worksheet of .xlsx input by name or 1-based
+	// position; empty means the first one. Input starting with the zip
+	// signature is read as .xlsx, anything else as CSV.
+	Sheet string
+	// Workers is the number of concurrent VIES requests; 1 if zero
+	Workers int
+	// Redact masks trader names and addresses in the report
+	Redact bool
+	// Fields selects and orders the appended ResultColumns; if empty all
+	// of them, except durationMs and requestIdentifier without a Requester
+	Fields []string
+	// Requester is the VAT number of the party on whose behalf the checks
+	// are made, so VIES issues a consultation number for every row
+	Requester string
+	// OmitReportHeader leaves out the report's header row, e.g. when
+	// appending to an existing report
+	OmitReportHeader bool
+	// ItemTimeout bounds the time spent on one row, retries included;
+	// 0 means no limit. Rows exceeding it are reported as ITEM_TIMEOUT.
+	ItemTimeout time.Duration
+	// Deadline bounds the whole run; 0 means no limit. Rows not checked in
+	// time are reported as BATCH_DEADLINE, so the report stays complete.
+	Deadline time.Duration
+	// Window restricts the requests to a daily period; nil means any time.
+	// Outside of it the run pauses, finishing the rows in progress, and
+	// resumes when the window opens again. Time spent paused counts
+	// against Deadline.
+	Window *Window
+	// OnPause, if set, is called whenever the run pauses for Window, with
+	// the time it resumes
+	OnPause func(until time.Time)
+}
+
+// Summary counts the outcome of a batch run
+type Summary struct {
+	Rows    int
+	Valid   int
+	Invalid int
+	Errors  int
+	// Throttled counts VIES concurrency faults that were retried after
+	// reducing the number of concurrent requests
+	Throttled int
+	// TimedOut and Unchecked count the rows among Errors that hit the item
+	// timeout or the batch deadline
+	TimedOut  int
+	Unchecked int
+	// NotProcessed counts the rows left out of the report because the run
+	// was cancelled
+	NotProcessed int
+}
+
+// Run reads CSV rows from r, checks the VAT number of each row with checker
+// and writes the rows to w with ResultColumns appended. Rows keep their input
+// order and are written, and flushed, as soon as they and all rows before
+// them are checked, so long runs can be tailed. If w has a Flush method (e.g.
+// *gzip.Writer or *bufio.Writer) it is flushed after every row too. Per-row
+// failures are reported in the errorCode and errorMessage columns; only input
+// and output errors are returned.
+//
+// Cancelling ctx stops the run cleanly: the rows checked so far are written
+// and flushed, rows interrupted or not yet checked are left out and counted
+// in Summary.NotProcessed, and the context's error is returned.
+func Run(ctx context.Context, checker vies.Checker, r io.Reader, w io.Writer, opts Options) (Summary, error) {
+	columns, err := selectColumns(opts)
+	if err != nil {
+		return Summary{}, err
+	}
+	in, err := readInput(r, opts)
+	if err != nil {
+		return Summary{}, err
+	}
+	header, rows := in.header, in.rows
+
+	writer := csv.NewWriter(w)
+	writer.Comma = in.comma
+	flush := func() error {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() error }); ok {
+			return f.Flush()
+		}
+		return nil
+	}
+	if header != nil && !opts.OmitReportHeader {
+		if err := writer.Write(append(append([]string(nil), header...), pick(ResultColumns, columns)...)); err != nil {
+			return Summary{}, err
+		}
+		if err := flush(); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	// Stop the workers if writing fails part-way
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	throttle := newThrottle(opts.Workers)
+	quit := make(chan struct{})
+	defer close(quit)
+	results := check(ctx, quit, checker, in, throttle, opts)
+
+	var summary Summary
+	for _, row := range rows {
+		res, ok := <-results
+		if !ok || (res.err != nil && ctx.Err() != nil) {
+			summary.NotProcessed = len(rows) - summary.Rows
+			summary.Throttled = throttle.count()
+			return summary, ctx.Err()
+		}
+		if opts.Redact {
+			res.result = vies.Redact(res.result)
+		}
+		summary.Rows++
+		switch {
+		case res.err != nil:
+			summary.Errors++
+			if errors.Is(res.err, ErrItemTimeout) {
+				summary.TimedOut++
+			}
+			if errors.Is(res.err, ErrDeadlineExceeded) {
+				summary.Unchecked++
+			}
+		case res.result.Valid:
+			summary.Valid++
+		default:
+			summary.Invalid++
+		}
+		if err := writer.Write(append(append([]string(nil), row...), pick(resultFields(res), columns)...)); err != nil {
+			return summary, err
+		}
+		if err := flush(); err != nil {
+			return summary, err
+		}
+	}
+	summary.Throttled = throttle.count()
+	return summary, nil
+}
+
+// input is a parsed CSV file with its VAT number column resolved
+type input struct {
+	header []string // nil when the file has no header row
+	rows   [][]string
+	column int
+	comma  rune
+}
+
+// readInput reads all rows from r, CSV or an .xlsx spreadsheet, and
+// resolves the VAT number column
+func readInput(r io.Reader, opts Options) (*input, error) {
+	buffered := bufio.NewReader(r)
+	var rows [][]string
+	comma := ','
+	if magic, _ := buffered.Peek(4); xlsx.IsXLSX(magic) {
+		data, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+		if rows, err = xlsx.ReadSheet(bytes.NewReader(data), int64(len(data)), opts.Sheet); err != nil {
+			return nil, err
+		}
+	} else {
+		if opts.Sheet != "" {
+			return nil, fmt.Errorf("a sheet can only be selected in .xlsx input")
+		}
+		reader := csv.NewReader(buffered)
+		reader.FieldsPerRecord = -1
+		if opts.Comma != 0 {
+			reader.Comma = opts.Comma
+		}
+		var err error
+		if rows, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("reading CSV input: %w", err)
+		}
+		comma = reader.Comma
+	}
+
+	in := &input{rows: rows, comma: comma}
+	if !opts.NoHeader && len(rows) > 0 {
+		in.header, in.rows = rows[0], rows[1:]
+	}
+	var err error
+	in.column, err = resolveColumn(opts.Column, in.header)
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// vatNumber returns the trimmed VAT number cell of row i
+func (in *input) vatNumber(i int) string {
+	if in.column < len(in.rows[i]) {
+		return strings.TrimSpace(in.rows[i][in.column])
+	}
+	return ""
+}
+
+// rowResult is the outcome of checking a single row
+type rowResult struct {
+	result   *vies.CheckVatResult
+	err      error
+	duration time.Duration
+}
+
+// check validates the VAT number column of every row using a pool of
+// workers, whose concurrency is adapted by throttle. Results are delivered
+// in input order, each as soon as it and all rows before it are done. Cancel
+// ctx to stop early, and close quit once the results are no longer read.
+func check(ctx context.Context, quit <-chan struct{}, checker vies.Checker, in *input, throttle *throttle, opts Options) <-chan rowResult {
+	results := make([]rowResult, len(in.rows))
+	done := make([]chan struct{}, len(in.rows))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	// Past the deadline rows are still delivered, marked as unchecked
+	checkCtx, cancelCheck := ctx, context.CancelFunc(func() {})
+	if opts.Deadline > 0 {
+		checkCtx, cancelCheck = context.WithTimeout(ctx, opts.Deadline)
+	}
+
+	gate := &windowGate{window: opts.Window, onPause: opts.OnPause}
+	jobs := make(chan int)
+	var workers sync.WaitGroup
+	for n := 0; n < opts.Workers; n++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range jobs {
+				gate.wait(checkCtx)
+				results[i] = checkRow(ctx, checkCtx, checker, throttle, in.vatNumber(i), opts)
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		defer cancelCheck()
+		defer workers.Wait()
+		defer close(jobs)
+		for i := range in.rows {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// After cancellation rows that were already done are still delivered,
+	// until the first one that is not
+	out := make(chan rowResult)
+	go func() {
+		defer close(out)
+		for i := range in.rows {
+			select {
+			case <-done[i]:
+			case <-ctx.Done():
+				select {
+				case <-done[i]:
+				default:
+					return
+				}
+			}
+			select {
+			case out <- results[i]:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// checkRow checks one VAT number within the batch deadline of checkCtx and
+// the item timeout, mapping either being exceeded to its batch error
+func checkRow(ctx, checkCtx context.Context, checker vies.Checker, throttle *throttle, vatNumber string, opts Options) rowResult {
+	itemTimeout := opts.ItemTimeout
+	if checkCtx.Err() != nil && ctx.Err() == nil {
+		return rowResult{err: ErrDeadlineExceeded}
+	}
+	itemCtx, cancel := checkCtx, context.CancelFunc(func() {})
+	if itemTimeout > 0 {
+		itemCtx, cancel = context.WithTimeout(checkCtx, itemTimeout)
+	}
+	defer cancel()
+
+	var options []vies.RequestOption
+	if opts.Requester != "" {
+		options = append(options, vies.WithRequester(opts.Requester))
+	}
+	start := time.Now()
+	result, err := throttle.checkThrottled(itemCtx, checker, vatNumber, options...)
+	duration := time.Since(start)
+	if err != nil && ctx.Err() == nil {
+		switch {
+		case checkCtx.Err() != nil:
+			err = ErrDeadlineExceeded
+		case itemCtx.Err() != nil:
+			err = fmt.Errorf("%w (%s)", ErrItemTimeout, itemTimeout)
+		}
+	}
+	return rowResult{result: result, err: err, duration: duration}
+}
+
+// resultFields renders the appended report columns for a row
+func resultFields(res rowResult) []string {
+	if res.err != nil {
+		code := vies.CodeServiceError
+		var validationErr *vies.ValidationError
+		var serviceErr *vies.ServiceError
+		switch {
+		case errors.Is(res.err, ErrItemTimeout):
+			code = CodeItemTimeout
+		case errors.Is(res.err, ErrDeadlineExceeded):
+			code = CodeDeadlineExceeded
+		case errors.As(res.err, &validationErr):
+			code = validationErr.Code
+		case errors.As(res.err, &serviceErr):
+			code = serviceErr.Code
+		}
+		return []string{"", "", "", code, res.err.Error(), "", durationMs(res)}
+	}
+	return []string{strconv.FormatBool(res.result.Valid), res.result.Name, res.result.Address, "", "", res.result.RequestIdentifier, durationMs(res)}
+}
+
+// durationMs renders the time spent on a row; empty for rows that were
+// never checked
+func durationMs(res rowResult) string {
+	if res.duration == 0 {
+		return ""
+	}
+	return strconv.FormatInt(res.duration.Milliseconds(), 10)
+}
+
+// selectColumns returns the indexes into ResultColumns of opts.Fields, or
+// of the default columns if it is empty
+func selectColumns(opts Options) ([]int, error) {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = ResultColumns[:len(ResultColumns)-1]
+		if opts.Requester == "" {
+			fields = ResultColumns[:len(ResultColumns)-2]
+		}
+	}
+	columns := make([]int, 0, len(fields))
+	for _, field := range fields {
+		index := -1
+		for i, name := range ResultColumns {
+			if name == field {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("unknown field %q (supported: %s)", field, strings.Join(ResultColumns, ", "))
+		}
+		columns = append(columns, index)
+	}
+	return columns, nil
+}
+
+// pick returns the values at the given indexes
+func pick(values []string, indexes []int) []string {
+	picked := make([]string, len(indexes))
+	for i, index := range indexes {
+		picked[i] = values[index]
+	}
+	return picked
+}
+
+// resolveColumn returns the 0-based index of the VAT number column
+func resolveColumn(column string, header []string) (int, error) {
+	if column == "" {
+		for i, name := range header {
+			for _, known := range vatColumnNames {
+				if strings.EqualFold(strings.TrimSpace(name), known) {
+					return i, nil
+				}
+			}
+		}
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("invalid column index %d (columns are numbered from 1)", n)
+		}
+		return n - 1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("column %q not found in CSV header", column)
+}
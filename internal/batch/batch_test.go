@@ -0,0 +1,344 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestRunAppendsResultColumns(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+
+	input := "id,customer,vat_number\n1,Example,DE266201128\n2,Other,FR12345678901\n3,Broken,DE123\n"
+	var out strings.Builder
+	summary, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "id,customer,vat_number,valid,name,address,errorCode,errorMessage\n" +
+		"1,Example,DE266201128,true,Example GmbH,Berlin,,\n" +
+		"2,Other,FR12345678901,false,,,,\n" +
+		"3,Broken,DE123,,,,INVALID_FORMAT,Invalid length for Germany VAT number. Expected: DE + 9 digits\n"
+	if out.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if summary != (Summary{Rows: 3, Valid: 1, Invalid: 1, Errors: 1}) {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestRunRequestIdentifier(t *testing.T) {
+	var requesters []string
+	checker := checkerFunc(func(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+		var opts vies.RequestOptions
+		for _, option := range options {
+			option(&opts)
+		}
+		requesters = append(requesters, opts.Requester)
+		return &vies.CheckVatResult{Valid: true, RequestIdentifier: "WAPIAAAAW1234567"}, nil
+	})
+
+	input := "vat\nDE266201128\n"
+	var out strings.Builder
+	if _, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Requester: "DE136695976"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "vat,valid,name,address,errorCode,errorMessage,requestIdentifier\n" +
+		"DE266201128,true,,,,,WAPIAAAAW1234567\n"
+	if out.String() != want || len(requesters) != 1 || requesters[0] != "DE136695976" {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s\nrequesters: %v", out.String(), want, requesters)
+	}
+}
+
+func TestRunSelectsFields(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+
+	input := "vat\nDE266201128\n"
+	var out strings.Builder
+	if _, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Fields: []string{"name", "valid"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "vat,name,valid\nDE266201128,Example GmbH,true\n"; out.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if _, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Fields: []string{"requestDate"}}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestRunDuration(t *testing.T) {
+	checker := checkerFunc(func(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &vies.CheckVatResult{Valid: true}, nil
+	})
+
+	input := "vat\nDE266201128\n"
+	var out strings.Builder
+	if _, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Fields: []string{"valid", "durationMs"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != "vat,valid,durationMs" {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+	fields := strings.Split(lines[1], ",")
+	if ms, err := strconv.Atoi(fields[2]); err != nil || ms < 20 {
+		t.Errorf("expected the time spent on the row, got %q", fields[2])
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	checker := checkerFunc(func(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+		if vatNumber == "DE136695976" {
+			panic("malformed response")
+		}
+		return &vies.CheckVatResult{Valid: true}, nil
+	})
+
+	input := "vat\nDE136695976\nDE266201128\n"
+	var out strings.Builder
+	summary, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{Fields: []string{"valid", "errorCode"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "vat,valid,errorCode\nDE136695976,,INTERNAL_ERROR\nDE266201128,true,\n"
+	if out.String() != want || summary.Errors != 1 || summary.Valid != 1 {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s\nsummary: %+v", out.String(), want, summary)
+	}
+}
+
+// gatedChecker holds back the second row until the first has been written
+type gatedChecker struct {
+	release chan struct{}
+}
+
+func (c *gatedChecker) CheckVAT(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	if vatNumber == "DE136695976" {
+		select {
+		case <-c.release:
+		case <-time.After(2 * time.Second):
+			return nil, errors.New("first row was not written before the second was checked")
+		}
+	}
+	return &vies.CheckVatResult{Valid: true}, nil
+}
+
+// releasingWriter releases the gated checker once a write contains marker
+type releasingWriter struct {
+	strings.Builder
+	marker  string
+	release chan struct{}
+}
+
+func (w *releasingWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), w.marker) {
+		close(w.release)
+	}
+	return w.Builder.Write(p)
+}
+
+func TestRunStreamsRows(t *testing.T) {
+	release := make(chan struct{})
+	out := &releasingWriter{marker: "DE266201128", release: release}
+	input := "vat\nDE266201128\nDE136695976\n"
+	summary, err := Run(context.Background(), &gatedChecker{release: release}, strings.NewReader(input), out, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Errors != 0 {
+		t.Errorf("rows were not streamed:\n%s", out.String())
+	}
+}
+
+func TestRunTimeouts(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetLatency(50 * time.Millisecond)
+	input := "vat\nDE266201128\nDE136695976\n"
+
+	var out strings.Builder
+	summary, err := Run(context.Background(), checker, strings.NewReader(input), &out, Options{
+		Fields:      []string{"errorCode"},
+		ItemTimeout: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "vat,errorCode\nDE266201128,ITEM_TIMEOUT\nDE136695976,ITEM_TIMEOUT\n"; out.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if summary.TimedOut != 2 || summary.Errors != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// Rows left when the deadline passes are reported without being checked
+	out.Reset()
+	summary, err = Run(context.Background(), checker, strings.NewReader(input+"NL004495445B01\n"), &out, Options{
+		Fields:   []string{"errorCode"},
+		Deadline: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "vat,errorCode\nDE266201128,BATCH_DEADLINE\nDE136695976,BATCH_DEADLINE\nNL004495445B01,BATCH_DEADLINE\n"; out.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if summary.Unchecked != 3 || summary.Rows != 3 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+// cancellingChecker cancels the run while checking its second number
+type cancellingChecker struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (c *cancellingChecker) CheckVAT(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	c.calls++
+	if c.calls == 2 {
+		c.cancel()
+		<-ctx.Done()
+		return nil, &vies.ServiceError{Code: vies.CodeNetworkTimeout, Message: "Request cancelled", Err: ctx.Err()}
+	}
+	return &vies.CheckVatResult{Valid: true}, nil
+}
+
+func TestRunCancelledKeepsCompletedRows(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	input := "vat\nDE266201128\nDE136695976\nNL004495445B01\n"
+
+	var out strings.Builder
+	summary, err := Run(ctx, &cancellingChecker{cancel: cancel}, strings.NewReader(input), &out, Options{Fields: []string{"valid"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if want := "vat,valid\nDE266201128,true\n"; out.String() != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if summary.Rows != 1 || summary.NotProcessed != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestResolveColumn(t *testing.T) {
+	header := []string{"Name", "VAT"}
+	tests := []struct {
+		column  string
+		header  []string
+		want    int
+		wantErr bool
+	}{
+		{"", header, 1, false},
+		{"name", header, 0, false},
+		{"2", nil, 1, false},
+		{"0", nil, 0, true},
+		{"missing", header, 0, true},
+		{"", nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := resolveColumn(tt.column, tt.header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveColumn(%q, %v) = %d, %v", tt.column, tt.header, got, err)
+		}
+	}
+}
+
+func TestLintReportsViolations(t *testing.T) {
+	input := "name,vat\nGood,DE266201128\nShort,AT12345678\nForeign,GB123456789\n"
+	violations, rows, err := Lint(strings.NewReader(input), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("expected 3 rows checked, got %d", rows)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", violations)
+	}
+	if v := violations[0]; v.Row != 3 || v.Code != "INVALID_FORMAT" || len(v.Suggestions) != 1 || v.Suggestions[0] != "ATU12345678" {
+		t.Errorf("unexpected first violation: %+v", v)
+	}
+	if v := violations[1]; v.Row != 4 || v.Code != "UNSUPPORTED_COUNTRY" {
+		t.Errorf("unexpected second violation: %+v", v)
+	}
+}
+
+func TestLintSpreadsheet(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml":            `<workbook><sheets><sheet name="VAT" r:id="rId1" xmlns:r="urn:r"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="inlineStr"><is><t>vat</t></is></c></row>` +
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>DE266201128</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>AT12345678</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	} {
+		f, _ := archive.Create(name)
+		f.Write([]byte(content))
+	}
+	archive.Close()
+
+	violations, rows, err := Lint(bytes.NewReader(buf.Bytes()), Options{Sheet: "VAT"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows != 2 || len(violations) != 1 || violations[0].Row != 3 || violations[0].VATNumber != "AT12345678" {
+		t.Errorf("unexpected lint of the spreadsheet: %d rows, %+v", rows, violations)
+	}
+
+	if _, _, err := Lint(strings.NewReader("vat\nDE266201128\n"), Options{Sheet: "VAT"}); err == nil {
+		t.Error("expected an error selecting a sheet of CSV input")
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	input := "customer_id,name,vat\n" +
+		"C1,Example GmbH,DE266201128\n" +
+		"C2,EXAMPLE G.m.b.H.,de 266 201 128\n" +
+		"C3,Other AG,DE136695976\n" +
+		"C3,Other AG,DE136695976\n" +
+		"C4,Acme BV,NL004495445B01\n" +
+		"C5,,\n" +
+		"C6,,\n"
+	duplicates, rows, err := Duplicates(strings.NewReader(input), Options{}, DuplicateColumns{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows != 7 {
+		t.Errorf("expected 7 rows read, got %d", rows)
+	}
+	want := []Duplicate{
+		{VATNumber: "DE266201128", Rows: []int{2, 3}, CustomerIDs: []string{"C1", "C2"}, Names: []string{"Example GmbH"}, Conflict: true},
+		{VATNumber: "DE136695976", Rows: []int{4, 5}, CustomerIDs: []string{"C3"}, Names: []string{"Other AG"}},
+	}
+	if !reflect.DeepEqual(duplicates, want) {
+		t.Errorf("Duplicates() = %+v, want %+v", duplicates, want)
+	}
+
+	if _, _, err := Duplicates(strings.NewReader(input), Options{}, DuplicateColumns{ID: "account"}); err == nil {
+		t.Error("expected an error for a missing ID column")
+	}
+}
+
+// checkerFunc adapts a function to the vies.Checker interface
+type checkerFunc func(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error)
+
+func (f checkerFunc) CheckVAT(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	return f(ctx, vatNumber, options...)
+}
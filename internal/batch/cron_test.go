@@ -0,0 +1,53 @@
+package batch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2025-01-09 is a Thursday
+	from := time.Date(2025, 1, 9, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 3 * * MON", time.Date(2025, 1, 13, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 9, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2025, 1, 10, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2025, 1, 9, 11, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{"0 0 15 * FRI", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 FEB *", time.Time{}},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := cron.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+
+	// Times are matched in the location of the argument
+	brussels, err := time.LoadLocation("Europe/Brussels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, _ := ParseCron("0 3 * * *")
+	if got, want := cron.Next(from.In(brussels)), time.Date(2025, 1, 10, 3, 0, 0, 0, brussels); !got.Equal(want) {
+		t.Errorf("Next in Brussels = %s, want %s", got, want)
+	}
+
+	for _, invalid := range []string{"0 3 * *", "60 * * * *", "0 3 * * MONDAY", "0 5-3 * * *", "*/0 * * * *"} {
+		if _, err := ParseCron(invalid); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package batch
+
+import (
+	"io"
+	"strings"
+
+	"l22.io/viesquery/pkg/companyname"
+	"l22.io/viesquery/pkg/vies"
+)
+
+// idColumnNames and nameColumnNames are header names recognized as the
+// customer ID and company name columns when none is given explicitly
+var (
+	idColumnNames   = []string{"customer_id", "customerid", "customer id", "customer", "customer_number", "customer number", "id"}
+	nameColumnNames = []string{"name", "company", "company_name", "company name", "customer_name", "customer name"}
+)
+
+// Duplicate describes a VAT number found in more than one row
+type Duplicate struct {
+	// VATNumber is the number in canonical form, so "GR094259216" and
+	// "el 094 259 216" count as the same number
+	VATNumber string `json:"vatNumber"`
+	// Rows are the 1-based record numbers in the file, header included
+	Rows []int `json:"rows"`
+	// CustomerIDs and Names are the distinct values of the rows, in order of
+	// appearance; names differing only in case, accents, punctuation or
+	// legal form count as one
+	CustomerIDs []string `json:"customerIds,omitempty"`
+	Names       []string `json:"names,omitempty"`
+	// Conflict reports rows mapping the number to different customer IDs or
+	// names, as opposed to plain repetitions
+	Conflict bool `json:"conflict"`
+}
+
+// DuplicateColumns selects the columns compared by Duplicates, by header
+// name or 1-based index. Empty means a recognized header name such as
+// "customer_id" or "name"; without one the column is not compared.
+type DuplicateColumns struct {
+	ID   string
+	Name string
+}
+
+// Duplicates finds VAT numbers occurring in more than one CSV row without
+// contacting VIES. It returns them in order of first appearance and the
+// number of rows read. Rows with an empty VAT number are ignored.
+func Duplicates(r io.Reader, opts Options, columns DuplicateColumns) ([]Duplicate, int, error) {
+	in, err := readInput(r, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	idColumn, err := optionalColumn(columns.ID, in.header, idColumnNames)
+	if err != nil {
+		return nil, 0, err
+	}
+	nameColumn, err := optionalColumn(columns.Name, in.header, nameColumnNames)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := 1
+	if in.header != nil {
+		offset = 2
+	}
+
+	type group struct {
+		duplicate Duplicate
+		names     map[string]bool // normalized forms of Duplicate.Names
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for i, row := range in.rows {
+		vatNumber := in.vatNumber(i)
+		if vatNumber == "" {
+			continue
+		}
+		key, err := vies.ValidateAndNormalize(vatNumber)
+		if err != nil {
+			key = vies.NormalizeInput(vatNumber)
+		}
+		g := groups[key]
+		if g == nil {
+			g = &group{duplicate: Duplicate{VATNumber: key}, names: make(map[string]bool)}
+			groups[key] = g
+			order = append(order, key)
+		}
+		d := &g.duplicate
+		d.Rows = append(d.Rows, i+offset)
+		if id := cell(row, idColumn); id != "" && !contains(d.CustomerIDs, id) {
+			d.CustomerIDs = append(d.CustomerIDs, id)
+		}
+		if name := cell(row, nameColumn); name != "" && !g.names[companyname.Normalize(name)] {
+			g.names[companyname.Normalize(name)] = true
+			d.Names = append(d.Names, name)
+		}
+	}
+
+	var duplicates []Duplicate
+	for _, key := range order {
+		d := groups[key].duplicate
+		if len(d.Rows) < 2 {
+			continue
+		}
+		d.Conflict = len(d.CustomerIDs) > 1 || len(d.Names) > 1
+		duplicates = append(duplicates, d)
+	}
+	return duplicates, len(in.rows), nil
+}
+
+// optionalColumn resolves an optional column: an explicit one must exist,
+// otherwise the first header in known is used, or -1 if there is none
+func optionalColumn(column string, header, known []string) (int, error) {
+	if column != "" {
+		return resolveColumn(column, header)
+	}
+	for _, name := range known {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// cell returns the trimmed value of a column, or "" if the row is too short
+// or column is -1
+func cell(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
+
+// contains reports whether values holds s
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package batch
+
+import (
+	"errors"
+	"io"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Violation describes a row whose VAT number fails offline validation
+type Violation struct {
+	Row         int      `json:"row"` // 1-based record number in the file, header included
+	VATNumber   string   `json:"vatNumber"`
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Lint checks the VAT number column of every CSV row against the offline
+// format rules without contacting VIES. It returns the violations in input
+// order and the number of rows checked.
+func Lint(r io.Reader, opts Options) ([]Violation, int, error) {
+	in, err := readInput(r, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offset := 1
+	if in.header != nil {
+		offset = 2
+	}
+
+	var violations []Violation
+	for i := range in.rows {
+		vatNumber := in.vatNumber(i)
+		err := vies.ValidateFormat(vatNumber)
+		if err == nil {
+			continue
+		}
+		violation := Violation{
+			Row:       i + offset,
+			VATNumber: vatNumber,
+			Code:      vies.CodeInvalidFormat,
+			Message:   err.Error(),
+		}
+		var validationErr *vies.ValidationError
+		if errors.As(err, &validationErr) {
+			violation.Code = validationErr.Code
+			violation.Suggestions = validationErr.Suggestions
+		}
+		violations = append(violations, violation)
+	}
+	return violations, len(in.rows), nil
+}
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// concurrencyFaults are the VIES faults signalling too many concurrent
+// requests, globally or towards one member state
+var concurrencyFaults = map[string]bool{
+	"GLOBAL_MAX_CONCURRENT_REQ":      true,
+	"GLOBAL_MAX_CONCURRENT_REQ_TIME": true,
+	"MS_MAX_CONCURRENT_REQ":          true,
+	"MS_MAX_CONCURRENT_REQ_TIME":     true,
+}
+
+const (
+	// maxThrottleRetries is how often a row is retried after concurrency faults
+	maxThrottleRetries = 5
+	// rampUpAfter is the number of successful checks before the concurrency
+	// limit grows by one again
+	rampUpAfter = 20
+)
+
+// throttleBackoff is the base delay before retrying a throttled row; it
+// grows linearly with each retry
+var throttleBackoff = time.Second
+
+// isConcurrencyFault reports whether err is a VIES concurrency fault
+func isConcurrencyFault(err error) bool {
+	var serviceErr *vies.ServiceError
+	return errors.As(err, &serviceErr) && concurrencyFaults[serviceErr.FaultCode]
+}
+
+// throttle limits the number of concurrent checks. The limit halves on
+// every concurrency fault and grows back by one after rampUpAfter successes,
+// up to the configured number of workers.
+type throttle struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     int
+	max       int
+	active    int
+	streak    int
+	throttled int
+}
+
+func newThrottle(workers int) *throttle {
+	t := &throttle{limit: workers, max: workers}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// acquire blocks until a check may start
+func (t *throttle) acquire() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for t.active >= t.limit {
+		t.cond.Wait()
+	}
+	t.active++
+}
+
+// release ends a check and records its outcome
+func (t *throttle) release(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	switch {
+	case isConcurrencyFault(err):
+		t.throttled++
+		t.streak = 0
+		t.limit = max(1, t.limit/2)
+	case err == nil:
+		t.streak++
+		if t.limit < t.max && t.streak >= rampUpAfter {
+			t.limit++
+			t.streak = 0
+		}
+	}
+	t.cond.Broadcast()
+}
+
+// checkThrottled checks a VAT number, retrying with a growing delay while
+// VIES reports concurrency faults
+func (t *throttle) checkThrottled(ctx context.Context, checker vies.Checker, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	for attempt := 1; ; attempt++ {
+		t.acquire()
+		// A panicking check fails its row only, and must not keep the slot
+		result, err := vies.CheckRecovered(ctx, checker, vatNumber, options...)
+		t.release(err)
+		if !isConcurrencyFault(err) || attempt > maxThrottleRetries {
+			return result, err
+		}
+		select {
+		case <-time.After(time.Duration(attempt) * throttleBackoff):
+		case <-ctx.Done():
+			return result, err
+		}
+	}
+}
+
+// count returns the number of concurrency faults seen
+func (t *throttle) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.throttled
+}
@@ -0,0 +1,60 @@
+package batch
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// busyChecker reports a concurrency fault for the first failures calls
+type busyChecker struct {
+	calls    atomic.Int32
+	failures int32
+}
+
+func (c *busyChecker) CheckVAT(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	if c.calls.Add(1) <= c.failures {
+		return nil, &vies.ServiceError{Code: vies.CodeSOAPFault, FaultCode: "MS_MAX_CONCURRENT_REQ"}
+	}
+	return &vies.CheckVatResult{Valid: true}, nil
+}
+
+func TestRunRetriesConcurrencyFaults(t *testing.T) {
+	defer func(d time.Duration) { throttleBackoff = d }(throttleBackoff)
+	throttleBackoff = time.Millisecond
+
+	input := "vat\nDE266201128\nDE136695976\nNL004495445B01\n"
+	var out strings.Builder
+	summary, err := Run(context.Background(), &busyChecker{failures: 3}, strings.NewReader(input), &out, Options{Workers: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Errors != 0 || summary.Valid != 3 || summary.Throttled != 3 {
+		t.Errorf("unexpected summary: %+v\n%s", summary, out.String())
+	}
+}
+
+func TestThrottleAdaptsLimit(t *testing.T) {
+	th := newThrottle(8)
+	fault := &vies.ServiceError{FaultCode: "GLOBAL_MAX_CONCURRENT_REQ"}
+
+	th.acquire()
+	th.release(fault)
+	th.acquire()
+	th.release(fault)
+	if th.limit != 2 {
+		t.Fatalf("limit after two faults = %d, want 2", th.limit)
+	}
+
+	for i := 0; i < rampUpAfter; i++ {
+		th.acquire()
+		th.release(nil)
+	}
+	if th.limit != 3 {
+		t.Errorf("limit after %d successes = %d, want 3", rampUpAfter, th.limit)
+	}
+}
@@ -0,0 +1,125 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window is a daily period during which a batch run may send requests,
+// e.g. the night hours the Commission recommends for bulk queries
+type Window struct {
+	// Start and End are offsets from local midnight. A window with End
+	// before Start spans midnight, e.g. 22:00-06:00.
+	Start, End time.Duration
+	// Location is the time zone of Start and End; UTC if nil
+	Location *time.Location
+}
+
+// ParseWindow parses a window in the form HH:MM-HH:MM[@ZONE], e.g.
+// "02:00-05:00@Europe/Brussels"
+func ParseWindow(s string) (*Window, error) {
+	span, zone, hasZone := strings.Cut(strings.TrimSpace(s), "@")
+	var window Window
+	if hasZone {
+		loc, err := time.LoadLocation(strings.TrimSpace(zone))
+		if err != nil {
+			return nil, fmt.Errorf("invalid batch window '%s': %w", s, err)
+		}
+		window.Location = loc
+	}
+	start, end, ok := strings.Cut(span, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid batch window '%s': expected HH:MM-HH:MM[@ZONE]", s)
+	}
+	var err error
+	if window.Start, err = parseTimeOfDay(start); err != nil {
+		return nil, fmt.Errorf("invalid batch window '%s': %w", s, err)
+	}
+	if window.End, err = parseTimeOfDay(end); err != nil {
+		return nil, fmt.Errorf("invalid batch window '%s': %w", s, err)
+	}
+	if window.Start == window.End {
+		return nil, fmt.Errorf("invalid batch window '%s': empty time range", s)
+	}
+	return &window, nil
+}
+
+// parseTimeOfDay parses HH:MM into an offset from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", strings.TrimSpace(s))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String formats the window as accepted by ParseWindow
+func (w *Window) String() string {
+	s := fmt.Sprintf("%02d:%02d-%02d:%02d", int(w.Start.Hours()), int(w.Start.Minutes())%60, int(w.End.Hours()), int(w.End.Minutes())%60)
+	if w.Location != nil && w.Location != time.UTC {
+		s += "@" + w.Location.String()
+	}
+	return s
+}
+
+// NextOpen returns t if the window is open at t, else the time it opens next
+func (w *Window) NextOpen(t time.Time) time.Time {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	offset := local.Sub(midnight)
+
+	switch {
+	case w.Start < w.End && offset >= w.Start && offset < w.End:
+		return t
+	case w.Start > w.End && (offset >= w.Start || offset < w.End):
+		return t
+	case offset < w.Start:
+		return midnight.Add(w.Start)
+	}
+	// Dates rather than 24 hours, so a DST change does not shift the start
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc).Add(w.Start)
+}
+
+// windowGate holds back the workers of a run while its window is closed,
+// reporting each pause once
+type windowGate struct {
+	window  *Window
+	onPause func(until time.Time)
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// wait blocks until the window is open or ctx is done
+func (g *windowGate) wait(ctx context.Context) {
+	if g == nil || g.window == nil {
+		return
+	}
+	now := time.Now()
+	open := g.window.NextOpen(now)
+	if !open.After(now) {
+		return
+	}
+	g.mu.Lock()
+	if !g.pausedUntil.Equal(open) {
+		g.pausedUntil = open
+		if g.onPause != nil {
+			g.onPause(open)
+		}
+	}
+	g.mu.Unlock()
+
+	timer := time.NewTimer(open.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,81 @@
+package batch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestWindowNextOpen(t *testing.T) {
+	night, err := ParseWindow("02:00-05:00@Europe/Brussels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := night.String(); got != "02:00-05:00@Europe/Brussels" {
+		t.Errorf("String() = %q", got)
+	}
+	overnight, err := ParseWindow("22:00-06:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brussels := night.Location
+
+	tests := []struct {
+		window *Window
+		at     time.Time
+		want   time.Time
+	}{
+		{night, time.Date(2025, 1, 9, 3, 0, 0, 0, brussels), time.Date(2025, 1, 9, 3, 0, 0, 0, brussels)},
+		{night, time.Date(2025, 1, 9, 1, 0, 0, 0, brussels), time.Date(2025, 1, 9, 2, 0, 0, 0, brussels)},
+		{night, time.Date(2025, 1, 9, 5, 0, 0, 0, brussels), time.Date(2025, 1, 10, 2, 0, 0, 0, brussels)},
+		// 02:00 Brussels is 01:00 UTC in winter
+		{night, time.Date(2025, 1, 9, 1, 30, 0, 0, time.UTC), time.Date(2025, 1, 9, 1, 30, 0, 0, time.UTC)},
+		{overnight, time.Date(2025, 1, 9, 23, 0, 0, 0, time.UTC), time.Date(2025, 1, 9, 23, 0, 0, 0, time.UTC)},
+		{overnight, time.Date(2025, 1, 9, 5, 59, 0, 0, time.UTC), time.Date(2025, 1, 9, 5, 59, 0, 0, time.UTC)},
+		{overnight, time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC), time.Date(2025, 1, 9, 22, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.window.NextOpen(tt.at); !got.Equal(tt.want) {
+			t.Errorf("%s.NextOpen(%s) = %s, want %s", tt.window, tt.at, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"02:00", "02:00-02:00", "25:00-03:00", "02:00-05:00@Nowhere/City"} {
+		if _, err := ParseWindow(invalid); err == nil {
+			t.Errorf("ParseWindow(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestRunPausesOutsideWindow(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+
+	// A window opening two hours from now
+	now := time.Now().UTC()
+	start := (time.Duration(now.Hour())*time.Hour + 2*time.Hour) % (24 * time.Hour)
+	window := &Window{Start: start, End: (start + time.Hour) % (24 * time.Hour)}
+
+	var pauses []time.Time
+	var out strings.Builder
+	summary, err := Run(context.Background(), checker, strings.NewReader("vat\nDE266201128\nDE266201128\n"), &out, Options{
+		Window:   window,
+		OnPause:  func(until time.Time) { pauses = append(pauses, until) },
+		Deadline: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checker.Calls()) != 0 {
+		t.Errorf("expected no requests outside the window, got %d", len(checker.Calls()))
+	}
+	if summary.Unchecked != 2 {
+		t.Errorf("expected both rows past the deadline, got %+v", summary)
+	}
+	if len(pauses) != 1 || !pauses[0].Equal(window.NextOpen(now)) {
+		t.Errorf("expected one pause until %s, got %v", window.NextOpen(now), pauses)
+	}
+}
@@ -0,0 +1,132 @@
+// Package budget persists request budgets across invocations.
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+const (
+	// lockTimeout is how long Take waits for another process's lock
+	lockTimeout = 5 * time.Second
+	// staleLockAge is the age after which a lock is assumed abandoned
+	staleLockAge = 30 * time.Second
+)
+
+// state is the persisted usage of the current day
+type state struct {
+	Date  string `json:"date"` // UTC date, YYYY-MM-DD
+	Count int    `json:"count"`
+}
+
+// Daily is a vies.RequestBudget allowing a fixed number of requests per UTC
+// day. Usage is stored in a JSON file so it is shared by every invocation
+// and process using the same path.
+type Daily struct {
+	path string
+	max  int
+	now  func() time.Time
+}
+
+var _ vies.RequestBudget = (*Daily)(nil)
+
+// NewDaily returns a budget of max requests per day, stored at path
+func NewDaily(path string, max int) *Daily {
+	return &Daily{path: path, max: max, now: time.Now}
+}
+
+// Take reserves one request for today, or returns an error wrapping
+// vies.ErrBudgetExceeded if today's requests are used up
+func (d *Daily) Take() error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := d.read()
+	if err != nil {
+		return err
+	}
+	if current.Count >= d.max {
+		return fmt.Errorf("%w: all %d requests for %s (UTC) are used", vies.ErrBudgetExceeded, d.max, current.Date)
+	}
+	current.Count++
+	return d.write(current)
+}
+
+// Used returns the number of requests taken today
+func (d *Daily) Used() (int, error) {
+	current, err := d.read()
+	return current.Count, err
+}
+
+// read loads today's usage; a missing file or an earlier day counts as zero
+func (d *Daily) read() (state, error) {
+	today := state{Date: d.now().UTC().Format("2006-01-02")}
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return today, nil
+	}
+	if err != nil {
+		return today, err
+	}
+	var stored state
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return today, fmt.Errorf("corrupt budget file %s: %w", d.path, err)
+	}
+	if stored.Date != today.Date {
+		return today, nil
+	}
+	return stored, nil
+}
+
+// write replaces the budget file atomically
+func (d *Daily) write(s state) error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := d.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.path)
+}
+
+// lock takes an exclusive lock file next to the budget file. Lock files are
+// portable across platforms, unlike flock.
+func (d *Daily) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o755); err != nil {
+		return nil, err
+	}
+	lockPath := d.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("budget file %s is locked by another process", d.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,40 @@
+package budget
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestDailyBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.json")
+	day := time.Date(2025, 1, 9, 23, 0, 0, 0, time.UTC)
+
+	first := NewDaily(path, 2)
+	first.now = func() time.Time { return day }
+	if err := first.Take(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A second instance shares the persisted usage
+	second := NewDaily(path, 2)
+	second.now = first.now
+	if err := second.Take(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := second.Take(); !errors.Is(err, vies.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+
+	// The budget resets on the next UTC day
+	second.now = func() time.Time { return day.Add(2 * time.Hour) }
+	if err := second.Take(); err != nil {
+		t.Fatalf("unexpected error on the next day: %v", err)
+	}
+	if used, err := second.Used(); err != nil || used != 1 {
+		t.Errorf("Used() = %d, %v, want 1", used, err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package logfile writes logs to a file that is rotated by size and age, for
+// long-running subcommands that should not depend on stderr redirection.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp in the names of rotated files. It sorts
+// lexically in time order, which pruning relies on.
+const backupTimeFormat = "2006-01-02T150405.000"
+
+// Options control when a log file is rotated and how many rotated files are
+// kept. Zero values disable the respective limit.
+type Options struct {
+	// MaxSize rotates the file before a write would make it larger, in bytes
+	MaxSize int64
+	// MaxAge rotates the file once it has been in use for this long
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; older ones are removed
+	MaxBackups int
+}
+
+// File is an io.WriteCloser appending to a log file. A rotated file is
+// renamed after the time of its rotation, e.g. viesquery.log becomes
+// viesquery-2025-01-13T030000.000.log, and a new file is started. File is
+// safe for concurrent use; each Write should be one complete log entry, as
+// written by log.Logger, so entries are never split across files.
+type File struct {
+	mu     sync.Mutex
+	path   string
+	opts   Options
+	file   *os.File
+	size   int64
+	opened time.Time
+	now    func() time.Time
+}
+
+// Open opens or creates the log file at path for appending. The age of an
+// existing file counts from this call.
+func Open(path string, opts Options) (*File, error) {
+	f := &File{path: path, opts: opts, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the log file for appending
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	f.file, f.size, f.opened = file, info.Size(), f.now()
+	return nil
+}
+
+// Write appends p to the log file, rotating it first if p would exceed
+// MaxSize or the file is older than MaxAge. An entry larger than MaxSize is
+// written to a file of its own.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooLarge := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	tooOld := f.opts.MaxAge > 0 && f.now().Sub(f.opened) >= f.opts.MaxAge
+	if tooLarge || tooOld {
+		// Logging goes on in the current file if only the rename failed
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate renames the current file after the current time, starts a new one
+// and removes the backups beyond MaxBackups
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	f.file = nil
+	renameErr := os.Rename(f.path, f.backupName(f.now()))
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("rotating log file: %w", renameErr)
+	}
+	return f.prune()
+}
+
+// backupName returns the name of the file rotated at t
+func (f *File) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// Backups returns the rotated files of the log file at path, oldest first
+func Backups(path string) ([]string, error) {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(filepath.Clean(dir + "."))
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// prune removes the oldest backups beyond MaxBackups
+func (f *File) prune() error {
+	if f.opts.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := Backups(f.path)
+	if err != nil {
+		return fmt.Errorf("removing old log files: %w", err)
+	}
+	for len(backups) > f.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("removing old log files: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "viesquery.log")
+	f, err := Open(path, Options{MaxSize: 20, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	now := time.Date(2025, 1, 13, 3, 0, 0, 0, time.UTC)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, entry := range []string{"first entry\n", "second entry\n", "third entry\n", "fourth entry\n"} {
+		if _, err := f.Write([]byte(entry)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	// Each entry exceeds the size of the file with the previous one, so
+	// every write after the first rotates; only the newest two are kept
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatalf("Backups() error = %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Backups() = %v, want 2 files", backups)
+	}
+	for i, want := range []string{"second entry\n", "third entry\n"} {
+		if data, _ := os.ReadFile(backups[i]); string(data) != want {
+			t.Errorf("backup %s = %q, want %q", backups[i], data, want)
+		}
+	}
+	if !strings.HasSuffix(backups[0], ".log") || !strings.Contains(filepath.Base(backups[0]), "viesquery-2025-01-13T") {
+		t.Errorf("unexpected backup name %s", backups[0])
+	}
+	if data, _ := os.ReadFile(path); string(data) != "fourth entry\n" {
+		t.Errorf("current log = %q, want the last entry", data)
+	}
+}
+
+func TestRotateByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "viesquery.log")
+	if err := os.WriteFile(path, []byte("earlier run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(path, Options{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	now := f.opened
+	f.now = func() time.Time { return now }
+
+	f.Write([]byte("appended\n"))
+	now = now.Add(time.Hour)
+	f.Write([]byte("next day\n"))
+
+	backups, _ := Backups(path)
+	if len(backups) != 1 {
+		t.Fatalf("Backups() = %v, want 1 file", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "earlier run\nappended\n" {
+		t.Errorf("backup = %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "next day\n" {
+		t.Errorf("current log = %q", data)
+	}
+}
@@ -0,0 +1,246 @@
+// Package xlsx reads the cell values of Office Open XML spreadsheets
+// (.xlsx), as exported by Excel and LibreOffice. Only values are read:
+// formulas yield their cached result, and formatting is ignored.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// zipMagic starts every zip archive, and so every .xlsx file
+var zipMagic = []byte("PK\x03\x04")
+
+// IsXLSX reports whether data, the start of a file, looks like a spreadsheet
+// rather than text
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, zipMagic)
+}
+
+// sheetRef is a worksheet listed in the workbook
+type sheetRef struct {
+	Name  string     `xml:"name,attr"`
+	Attrs []xml.Attr `xml:",any,attr"`
+}
+
+// relationID returns the r:id of the sheet, whichever namespace prefix the
+// writer used
+func (s sheetRef) relationID() string {
+	for _, attr := range s.Attrs {
+		if attr.Name.Local == "id" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// ReadSheet returns the rows of a worksheet as strings. sheet selects the
+// worksheet by name or 1-based position; empty means the first one. Missing
+// cells are returned as empty strings and rows without any value are left
+// out, as blank lines are in CSV.
+func ReadSheet(r io.ReaderAt, size int64, sheet string) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("reading spreadsheet: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var workbook struct {
+		Sheets []sheetRef `xml:"sheets>sheet"`
+	}
+	if err := decodeFile(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	ref, err := selectSheet(workbook.Sheets, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeFile(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	var sheetPath string
+	for _, rel := range rels.Relationships {
+		if rel.ID == ref.relationID() {
+			sheetPath = rel.Target
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("reading spreadsheet: worksheet %q has no data", ref.Name)
+	}
+	if strings.HasPrefix(sheetPath, "/") {
+		sheetPath = strings.TrimPrefix(sheetPath, "/")
+	} else {
+		sheetPath = path.Join("xl", sheetPath)
+	}
+
+	// Workbooks without text cells have no shared strings
+	var shared struct {
+		Items []richText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeFile(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var worksheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeFile(files, sheetPath, &worksheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range worksheet.Rows {
+		var values []string
+		blank := true
+		for _, cell := range row.Cells {
+			column := len(values)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			var value string
+			switch {
+			case cell.Type == "inlineStr":
+				value = cell.Inline.String()
+			case cell.Value == "":
+				// a formatted cell without value
+			case cell.Type == "s":
+				i, err := strconv.Atoi(cell.Value)
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("reading spreadsheet: cell %s refers to unknown string %q", cell.Ref, cell.Value)
+				}
+				value = shared.Items[i].String()
+			case cell.Type == "b":
+				value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.Value]
+			case cell.Type == "str" || cell.Type == "e":
+				value = cell.Value
+			default:
+				value = number(cell.Value)
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = value
+			if strings.TrimSpace(value) != "" {
+				blank = false
+			}
+		}
+		if !blank {
+			rows = append(rows, values)
+		}
+	}
+	return rows, nil
+}
+
+// selectSheet finds a worksheet by name or 1-based position
+func selectSheet(sheets []sheetRef, sheet string) (sheetRef, error) {
+	if len(sheets) == 0 {
+		return sheetRef{}, fmt.Errorf("reading spreadsheet: workbook has no worksheets")
+	}
+	if sheet == "" {
+		return sheets[0], nil
+	}
+	for _, ref := range sheets {
+		if ref.Name == sheet {
+			return ref, nil
+		}
+	}
+	if n, err := strconv.Atoi(sheet); err == nil && n >= 1 && n <= len(sheets) {
+		return sheets[n-1], nil
+	}
+	names := make([]string, len(sheets))
+	for i, ref := range sheets {
+		names[i] = ref.Name
+	}
+	return sheetRef{}, fmt.Errorf("unknown sheet %q (sheets: %s)", sheet, strings.Join(names, ", "))
+}
+
+// decodeFile unmarshals an XML part of the archive into v
+func decodeFile(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("reading spreadsheet: %s missing, not an .xlsx file", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("reading spreadsheet: %w", err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("reading spreadsheet: %s: %w", name, err)
+	}
+	return nil
+}
+
+// richText is a string item, either plain or made of formatted runs.
+// Phonetic hints (rPh) are not part of the value.
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "C7"
+func columnIndex(ref string) (int, error) {
+	column := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		column = column*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("reading spreadsheet: invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+// number renders a numeric cell without an exponent, so long numbers such
+// as VAT numbers without a country prefix keep all their digits
+func number(value string) string {
+	if !strings.ContainsAny(value, "eE") {
+		return value
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
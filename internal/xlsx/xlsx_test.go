@@ -0,0 +1,90 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// workbook builds an .xlsx archive from its parts
+func workbook(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadSheet(t *testing.T) {
+	data := workbook(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Suppliers" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>vat</t></si><si><t>name</t></si><si><r><t>DE</t></r><r><t>266201128</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>total</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>1.23456789E+8</v></c><c r="C2" t="str"><v>Example GmbH</v></c></row>
+<row r="3"><c r="A3" t="s"/></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>NL004495445B01</t></is></c><c r="B5" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+	})
+
+	if !IsXLSX(data) || IsXLSX([]byte("vat\nDE266201128\n")) {
+		t.Error("IsXLSX does not tell spreadsheets from CSV")
+	}
+
+	rows, err := ReadSheet(bytes.NewReader(data), int64(len(data)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"total"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("first sheet = %q, want %q", rows, want)
+	}
+
+	want := [][]string{
+		{"vat", "", "name"},
+		{"DE266201128", "123456789", "Example GmbH"},
+		{"NL004495445B01", "TRUE"},
+	}
+	for _, sheet := range []string{"Suppliers", "2"} {
+		rows, err := ReadSheet(bytes.NewReader(data), int64(len(data)), sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("sheet %s = %q, want %q", sheet, rows, want)
+		}
+	}
+
+	if _, err := ReadSheet(bytes.NewReader(data), int64(len(data)), "Customers"); err == nil || !strings.Contains(err.Error(), "Summary, Suppliers") {
+		t.Errorf("expected an error listing the sheets, got %v", err)
+	}
+	notXLSX := workbook(t, map[string]string{"readme.txt": "hello"})
+	if _, err := ReadSheet(bytes.NewReader(notXLSX), int64(len(notXLSX)), ""); err == nil {
+		t.Error("expected an error for a zip file without a workbook")
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "C7": 2, "Z10": 25, "AA2": 26, "AB100": 27} {
+		if got, err := columnIndex(ref); err != nil || got != want {
+			t.Errorf("columnIndex(%q) = %d, %v; want %d", ref, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package calendar converts dates between the Gregorian calendar and other
+// calendar systems.
+//
+// All conversions go through the Julian Day Number (JDN), the integer count of
+// days since 1 January 4713 BCE in the proleptic Julian calendar, and operate
+// on plain year, month and day integers. Months are 1-based. Gregorian and
+// Julian dates are proleptic, so years before their historical adoption are
+// accepted.
+package calendar
+
+// GregorianToJDN returns the Julian Day Number of a Gregorian date
+func GregorianToJDN(y, m, d int) int {
+	a := (14 - m) / 12
+	y2 := y + 4800 - a
+	m2 := m + 12*a - 3
+	return d + (153*m2+2)/5 + 365*y2 + y2/4 - y2/100 + y2/400 - 32045
+}
+
+// JDNToGregorian returns the Gregorian date of a Julian Day Number
+func JDNToGregorian(jdn int) (year, month, day int) {
+	a := jdn + 32044
+	b := (4*a + 3) / 146097
+	c := a - 146097*b/4
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+	day = e - (153*m+2)/5 + 1
+	month = m + 3 - 12*(m/10)
+	year = 100*b + d - 4800 + m/10
+	return
+}
+
+// JulianToJDN returns the Julian Day Number of a Julian calendar date
+func JulianToJDN(y, m, d int) int {
+	a := (14 - m) / 12
+	y2 := y + 4800 - a
+	m2 := m + 12*a - 3
+	return d + (153*m2+2)/5 + 365*y2 + y2/4 - 32083
+}
+
+// JDNToJulian returns the Julian calendar date of a Julian Day Number
+func JDNToJulian(jdn int) (year, month, day int) {
+	c := jdn + 32082
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+	day = e - (153*m+2)/5 + 1
+	month = m + 3 - 12*(m/10)
+	year = d - 4800 + m/10
+	return
+}
+
+// JulianFromGregorian converts a Gregorian date to the Julian calendar
+func JulianFromGregorian(y, m, d int) (jy, jm, jd int) {
+	return JDNToJulian(GregorianToJDN(y, m, d))
+}
+
+// GregorianFromJulian converts a Julian calendar date to the Gregorian calendar
+func GregorianFromJulian(y, m, d int) (gy, gm, gd int) {
+	return JDNToGregorian(JulianToJDN(y, m, d))
+}
+
+// JapaneseEra returns the Japanese era name and the year within that era for a
+// Gregorian date. Dates before the Meiji era are reported as "Pre-Meiji" with
+// the Gregorian year.
+func JapaneseEra(y, m, d int) (string, int) {
+	// Define era boundaries (inclusive start dates)
+	type eraDef struct {
+		name    string
+		y, m, d int
+	}
+	eras := []eraDef{
+		{"Reiwa", 2019, 5, 1},
+		{"Heisei", 1989, 1, 8},
+		{"Showa", 1926, 12, 25},
+		{"Taisho", 1912, 7, 30},
+		{"Meiji", 1868, 10, 23},
+	}
+	for _, e := range eras {
+		if afterOrEqual(y, m, d, e.y, e.m, e.d) {
+			year := y - e.y + 1
+			return e.name, year
+		}
+	}
+	return "Pre-Meiji", y
+}
+
+func afterOrEqual(y, m, d, y2, m2, d2 int) bool {
+	if y != y2 {
+		return y > y2
+	}
+	if m != m2 {
+		return m > m2
+	}
+	return d >= d2
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,175 @@
+package calendar
+
+import "testing"
+
+// roundTripStart and roundTripEnd bound the JDN range used by round-trip tests
+var (
+	roundTripStart = GregorianToJDN(1900, 1, 1)
+	roundTripEnd   = GregorianToJDN(2100, 12, 31)
+)
+
+func TestGregorianRoundTrip(t *testing.T) {
+	if got := GregorianToJDN(2000, 1, 1); got != 2451545 {
+		t.Fatalf("GregorianToJDN(2000-01-01) = %d, want 2451545", got)
+	}
+	for jdn := roundTripStart; jdn <= roundTripEnd; jdn++ {
+		y, m, d := JDNToGregorian(jdn)
+		if got := GregorianToJDN(y, m, d); got != jdn {
+			t.Fatalf("round trip of JDN %d via %d-%02d-%02d gave %d", jdn, y, m, d, got)
+		}
+	}
+}
+
+func TestJulianFromGregorian(t *testing.T) {
+	// The Julian calendar runs 13 days behind the Gregorian calendar since 1900
+	jy, jm, jd := JulianFromGregorian(2025, 9, 9)
+	if jy != 2025 || jm != 8 || jd != 27 {
+		t.Errorf("JulianFromGregorian(2025-09-09) = %d-%02d-%02d, want 2025-08-27", jy, jm, jd)
+	}
+	for jdn := roundTripStart; jdn <= roundTripEnd; jdn++ {
+		y, m, d := JDNToJulian(jdn)
+		if got := JulianToJDN(y, m, d); got != jdn {
+			t.Fatalf("round trip of JDN %d via Julian %d-%02d-%02d gave %d", jdn, y, m, d, got)
+		}
+	}
+}
+
+func TestJapaneseEra(t *testing.T) {
+	tests := []struct {
+		y, m, d int
+		era     string
+		year    int
+	}{
+		{2019, 5, 1, "Reiwa", 1},
+		{2019, 4, 30, "Heisei", 31},
+		{2025, 9, 9, "Reiwa", 7},
+		{1989, 1, 7, "Showa", 64},
+	}
+	for _, tt := range tests {
+		era, year := JapaneseEra(tt.y, tt.m, tt.d)
+		if era != tt.era || year != tt.year {
+			t.Errorf("JapaneseEra(%d-%02d-%02d) = %s %d, want %s %d", tt.y, tt.m, tt.d, era, year, tt.era, tt.year)
+		}
+	}
+}
+
+func TestIslamicCivilRoundTrip(t *testing.T) {
+	iy, im, id := IslamicCivilFromGregorian(622, 7, 19)
+	if iy != 1 || im != 1 || id != 1 {
+		t.Errorf("IslamicCivilFromGregorian(622-07-19) = %d/%d/%d, want 1/1/1", iy, im, id)
+	}
+	for jdn := roundTripStart; jdn <= roundTripEnd; jdn++ {
+		y, m, d := JDNToIslamicCivil(jdn)
+		if got := IslamicCivilToJDN(y, m, d); got != jdn {
+			t.Fatalf("round trip of JDN %d via %d/%d/%d gave %d", jdn, y, m, d, got)
+		}
+	}
+}
+
+func TestUmmAlQuraFromGregorian(t *testing.T) {
+	tests := []struct {
+		gy, gm, gd int
+		iy, im, id int
+	}{
+		{1990, 1, 1, 1410, 6, 4},
+		{2023, 3, 23, 1444, 9, 1}, // Ramadan 1444
+		{2050, 12, 31, 1473, 4, 17},
+	}
+
+	for _, tt := range tests {
+		iy, im, id, ok := UmmAlQuraFromGregorian(tt.gy, tt.gm, tt.gd)
+		if !ok || iy != tt.iy || im != tt.im || id != tt.id {
+			t.Errorf("UmmAlQuraFromGregorian(%d-%02d-%02d) = %d/%d/%d (ok=%t), want %d/%d/%d",
+				tt.gy, tt.gm, tt.gd, iy, im, id, ok, tt.iy, tt.im, tt.id)
+		}
+	}
+
+	if _, _, _, ok := UmmAlQuraFromGregorian(2100, 1, 1); ok {
+		t.Error("expected dates beyond the Umm al-Qura tables to be reported as out of range")
+	}
+}
+
+func TestUmmAlQuraRoundTrip(t *testing.T) {
+	start := GregorianToJDN(1937, 3, 14)
+	end := GregorianToJDN(2077, 11, 15)
+	for jdn := start; jdn <= end; jdn++ {
+		y, m, d, ok := JDNToUmmAlQura(jdn)
+		if !ok {
+			t.Fatalf("JDN %d unexpectedly outside the Umm al-Qura range", jdn)
+		}
+		if got, ok := UmmAlQuraToJDN(y, m, d); !ok || got != jdn {
+			t.Fatalf("round trip of JDN %d via %d/%d/%d gave %d (ok=%t)", jdn, y, m, d, got, ok)
+		}
+	}
+}
+
+func TestPersianFromGregorian(t *testing.T) {
+	tests := []struct {
+		gy, gm, gd int
+		py, pm, pd int
+	}{
+		{2025, 3, 21, 1404, 1, 1},   // Nowruz 1404
+		{2025, 3, 20, 1403, 12, 30}, // last day of leap year 1403
+		{2024, 3, 19, 1402, 12, 29},
+		{2025, 9, 9, 1404, 6, 18},
+		{1979, 2, 11, 1357, 11, 22},
+	}
+
+	for _, tt := range tests {
+		py, pm, pd := PersianFromGregorian(tt.gy, tt.gm, tt.gd)
+		if py != tt.py || pm != tt.pm || pd != tt.pd {
+			t.Errorf("PersianFromGregorian(%d-%02d-%02d) = %d/%d/%d, want %d/%d/%d",
+				tt.gy, tt.gm, tt.gd, py, pm, pd, tt.py, tt.pm, tt.pd)
+		}
+	}
+
+	if !PersianLeapYear(1403) || PersianLeapYear(1404) {
+		t.Error("expected 1403 to be a leap year and 1404 a common year")
+	}
+}
+
+func TestPersianRoundTrip(t *testing.T) {
+	for jdn := roundTripStart; jdn <= roundTripEnd; jdn++ {
+		y, m, d := JDNToPersian(jdn)
+		if got := PersianToJDN(y, m, d); got != jdn {
+			t.Fatalf("round trip of JDN %d via %d/%d/%d gave %d", jdn, y, m, d, got)
+		}
+	}
+}
+
+func TestHebrewFromGregorian(t *testing.T) {
+	tests := []struct {
+		gy, gm, gd int
+		hy, hm, hd int
+	}{
+		{2025, 9, 23, 5786, 7, 1},  // Rosh Hashanah 5786
+		{2024, 10, 3, 5785, 7, 1},  // Rosh Hashanah 5785
+		{2025, 9, 9, 5785, 6, 16},  // Elul
+		{2024, 3, 11, 5784, 13, 1}, // Adar II in a leap year
+		{2024, 4, 9, 5784, 1, 1},   // Nisan
+		{2023, 12, 8, 5784, 9, 25}, // Hanukkah
+		{2000, 1, 1, 5760, 10, 23},
+		{1948, 5, 14, 5708, 2, 5},
+	}
+
+	for _, tt := range tests {
+		hy, hm, hd := HebrewFromGregorian(tt.gy, tt.gm, tt.gd)
+		if hy != tt.hy || hm != tt.hm || hd != tt.hd {
+			t.Errorf("HebrewFromGregorian(%d-%02d-%02d) = %d/%d/%d, want %d/%d/%d",
+				tt.gy, tt.gm, tt.gd, hy, hm, hd, tt.hy, tt.hm, tt.hd)
+		}
+	}
+
+	if got := HebrewMonthName(12, 5784); got != "Adar I" {
+		t.Errorf("HebrewMonthName(12, 5784) = %q, want \"Adar I\"", got)
+	}
+}
+
+func TestHebrewRoundTrip(t *testing.T) {
+	for jdn := roundTripStart; jdn <= roundTripEnd; jdn++ {
+		y, m, d := JDNToHebrew(jdn)
+		if got := HebrewToJDN(y, m, d); got != jdn {
+			t.Fatalf("round trip of JDN %d via %d/%d/%d gave %d", jdn, y, m, d, got)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package calendar
+
+// Hebrew calendar conversion (arithmetic rules of the fixed calendar, molad-based).
+// Months are numbered from Nisan (1) as in the biblical reckoning; the year starts at
+// Tishrei (7). In leap years month 12 is Adar I and month 13 is Adar II.
+
+// hebrewEpochJDN is the Julian Day Number of 1 Tishrei AM 1 (7 October 3761 BCE, Julian)
+const hebrewEpochJDN = 347998
+
+// HebrewFromGregorian converts a Gregorian date to the Hebrew calendar
+func HebrewFromGregorian(y, m, d int) (hy, hm, hd int) {
+	return JDNToHebrew(GregorianToJDN(y, m, d))
+}
+
+// GregorianFromHebrew converts a Hebrew date to the Gregorian calendar
+func GregorianFromHebrew(hy, hm, hd int) (gy, gm, gd int) {
+	return JDNToGregorian(HebrewToJDN(hy, hm, hd))
+}
+
+// HebrewLeapYear reports whether Hebrew year y has thirteen months
+func HebrewLeapYear(y int) bool {
+	return (7*y+1)%19 < 7
+}
+
+// HebrewMonthsInYear returns 13 for leap years and 12 otherwise
+func HebrewMonthsInYear(y int) int {
+	if HebrewLeapYear(y) {
+		return 13
+	}
+	return 12
+}
+
+// hebrewElapsedDays returns the days from the epoch to the molad of Tishrei of year y,
+// applying the molad zaken and lo ADU rosh postponements
+func hebrewElapsedDays(y int) int {
+	monthsElapsed := floorDiv(235*y-234, 19)
+	partsElapsed := 12084 + 13753*monthsElapsed
+	days := 29*monthsElapsed + floorDiv(partsElapsed, 25920)
+	if (3*(days+1))%7 < 3 {
+		return days + 1
+	}
+	return days
+}
+
+// hebrewYearLengthCorrection applies the GaTaRaD and BeTUTaKPaT postponements
+func hebrewYearLengthCorrection(y int) int {
+	ny0 := hebrewElapsedDays(y - 1)
+	ny1 := hebrewElapsedDays(y)
+	ny2 := hebrewElapsedDays(y + 1)
+	switch {
+	case ny2-ny1 == 356:
+		return 2
+	case ny1-ny0 == 382:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func hebrewNewYearJDN(y int) int {
+	return hebrewEpochJDN + hebrewElapsedDays(y) + hebrewYearLengthCorrection(y)
+}
+
+func hebrewDaysInYear(y int) int {
+	return hebrewNewYearJDN(y+1) - hebrewNewYearJDN(y)
+}
+
+func hebrewDaysInMonth(m, y int) int {
+	switch {
+	case m == 2 || m == 4 || m == 6 || m == 10 || m == 13:
+		return 29
+	case m == 12 && !HebrewLeapYear(y):
+		return 29
+	case m == 8 && hebrewDaysInYear(y)%10 != 5: // Cheshvan is long only in complete years (355/385)
+		return 29
+	case m == 9 && hebrewDaysInYear(y)%10 == 3: // Kislev is short in deficient years (353/383)
+		return 29
+	default:
+		return 30
+	}
+}
+
+// HebrewToJDN returns the Julian Day Number of a Hebrew date
+func HebrewToJDN(y, m, d int) int {
+	jdn := hebrewNewYearJDN(y) + d - 1
+	if m < 7 {
+		for mm := 7; mm <= HebrewMonthsInYear(y); mm++ {
+			jdn += hebrewDaysInMonth(mm, y)
+		}
+		for mm := 1; mm < m; mm++ {
+			jdn += hebrewDaysInMonth(mm, y)
+		}
+	} else {
+		for mm := 7; mm < m; mm++ {
+			jdn += hebrewDaysInMonth(mm, y)
+		}
+	}
+	return jdn
+}
+
+// JDNToHebrew returns the Hebrew date of a Julian Day Number
+func JDNToHebrew(jdn int) (year, month, day int) {
+	// Estimate the year from the mean year length, then correct forward
+	year = (jdn-hebrewEpochJDN)*98496/35975351 - 1
+	for hebrewNewYearJDN(year+1) <= jdn {
+		year++
+	}
+	month = 1
+	if jdn < HebrewToJDN(year, 1, 1) {
+		month = 7
+	}
+	for jdn > HebrewToJDN(year, month, hebrewDaysInMonth(month, year)) {
+		month++
+	}
+	day = jdn - HebrewToJDN(year, month, 1) + 1
+	return
+}
+
+// HebrewMonthName returns the name of Hebrew month m in year y, distinguishing
+// Adar I and Adar II in leap years
+func HebrewMonthName(m, y int) string {
+	switch m {
+	case 1:
+		return "Nisan"
+	case 2:
+		return "Iyar"
+	case 3:
+		return "Sivan"
+	case 4:
+		return "Tammuz"
+	case 5:
+		return "Av"
+	case 6:
+		return "Elul"
+	case 7:
+		return "Tishrei"
+	case 8:
+		return "Cheshvan"
+	case 9:
+		return "Kislev"
+	case 10:
+		return "Tevet"
+	case 11:
+		return "Shevat"
+	case 12:
+		if HebrewLeapYear(y) {
+			return "Adar I"
+		}
+		return "Adar"
+	case 13:
+		return "Adar II"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,68 @@
+package calendar
+
+// islamicEpochJDN is the Julian Day Number of 1 Muharram AH 1 (civil epoch, 16 July 622 Julian)
+const islamicEpochJDN = 1948440
+
+// IslamicCivilToJDN returns the Julian Day Number of a date in the tabular
+// (civil) Islamic calendar
+func IslamicCivilToJDN(y, m, d int) int {
+	return (11*y+3)/30 + 354*y + 30*m - (m-1)/2 + d + islamicEpochJDN - 385
+}
+
+// JDNToIslamicCivil returns the tabular (civil) Islamic date of a Julian Day Number
+func JDNToIslamicCivil(jdn int) (iy, im, id int) {
+	l := jdn - islamicEpochJDN + 10632
+	n := (l - 1) / 10631
+	l = l - 10631*n + 354
+	j := ((10985 - l) / 5316) * ((50 * l) / 17719)
+	j += (l / 5670) * ((43 * l) / 15238)
+	l = l - ((30-j)/15)*((17719*j)/50) - (j/16)*((15238*j)/43) + 29
+	im = (24 * l) / 709
+	id = l - (709*im)/24
+	iy = 30*n + j - 30
+	return
+}
+
+// IslamicCivilFromGregorian converts a Gregorian date to the tabular (civil)
+// Islamic calendar
+func IslamicCivilFromGregorian(y, m, d int) (iy, im, id int) {
+	return JDNToIslamicCivil(GregorianToJDN(y, m, d))
+}
+
+// GregorianFromIslamicCivil converts a tabular (civil) Islamic date to the
+// Gregorian calendar
+func GregorianFromIslamicCivil(y, m, d int) (gy, gm, gd int) {
+	return JDNToGregorian(IslamicCivilToJDN(y, m, d))
+}
+
+// IslamicMonthName returns the transliterated name of Islamic month m (1-12)
+func IslamicMonthName(m int) string {
+	switch m {
+	case 1:
+		return "Muharram"
+	case 2:
+		return "Safar"
+	case 3:
+		return "Rabi' al-awwal"
+	case 4:
+		return "Rabi' al-thani"
+	case 5:
+		return "Jumada al-awwal"
+	case 6:
+		return "Jumada al-thani"
+	case 7:
+		return "Rajab"
+	case 8:
+		return "Sha'ban"
+	case 9:
+		return "Ramadan"
+	case 10:
+		return "Shawwal"
+	case 11:
+		return "Dhu al-Qi'dah"
+	case 12:
+		return "Dhu al-Hijjah"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,114 @@
+package calendar
+
+// Persian (Solar Hijri / Jalali) conversion using the 33-year cycle break table
+// (Borkowski's algorithm), valid for Persian years -61 to 3177
+var persianBreaks = []int{-61, 9, 38, 199, 426, 686, 756, 818, 1111, 1181, 1210,
+	1635, 2060, 2097, 2192, 2262, 2324, 2394, 2456, 3178}
+
+// persianCalendarYear returns the leap status (0 = leap year), the corresponding
+// Gregorian year and the March day of Nowruz for Persian year py
+func persianCalendarYear(py int) (leap, gy, march int) {
+	gy = py + 621
+	leapJ := -14
+	jp := persianBreaks[0]
+	jump := 0
+	for i := 1; i < len(persianBreaks); i++ {
+		jm := persianBreaks[i]
+		jump = jm - jp
+		if py < jm {
+			break
+		}
+		leapJ += jump/33*8 + jump%33/4
+		jp = jm
+	}
+	n := py - jp
+	leapJ += n/33*8 + (n%33+3)/4
+	if jump%33 == 4 && jump-n == 4 {
+		leapJ++
+	}
+	leapG := gy/4 - (gy/100+1)*3/4 - 150
+	march = 20 + leapJ - leapG
+	if jump-n < 6 {
+		n = n - jump + (jump+4)/33*33
+	}
+	leap = ((n+1)%33 - 1) % 4
+	if leap == -1 {
+		leap = 4
+	}
+	return
+}
+
+// PersianFromGregorian converts a Gregorian date to the Persian (Solar Hijri) calendar
+func PersianFromGregorian(y, m, d int) (py, pm, pd int) {
+	return JDNToPersian(GregorianToJDN(y, m, d))
+}
+
+// GregorianFromPersian converts a Persian (Solar Hijri) date to the Gregorian calendar
+func GregorianFromPersian(py, pm, pd int) (gy, gm, gd int) {
+	return JDNToGregorian(PersianToJDN(py, pm, pd))
+}
+
+// PersianLeapYear reports whether Persian year py has 366 days
+func PersianLeapYear(py int) bool {
+	leap, _, _ := persianCalendarYear(py)
+	return leap == 0
+}
+
+// PersianToJDN returns the Julian Day Number of a Persian (Solar Hijri) date
+func PersianToJDN(py, pm, pd int) int {
+	_, gy, march := persianCalendarYear(py)
+	return GregorianToJDN(gy, 3, march) + (pm-1)*31 - pm/7*(pm-7) + pd - 1
+}
+
+// JDNToPersian returns the Persian (Solar Hijri) date of a Julian Day Number
+func JDNToPersian(jdn int) (py, pm, pd int) {
+	gy, _, _ := JDNToGregorian(jdn)
+	py = gy - 621
+	leap, _, march := persianCalendarYear(py)
+	k := jdn - GregorianToJDN(gy, 3, march)
+	if k >= 0 {
+		if k <= 185 {
+			return py, 1 + k/31, k%31 + 1
+		}
+		k -= 186
+	} else {
+		py--
+		k += 179
+		if leap == 1 {
+			k++
+		}
+	}
+	return py, 7 + k/30, k%30 + 1
+}
+
+// PersianMonthName returns the name of Persian month m (1-12)
+func PersianMonthName(m int) string {
+	switch m {
+	case 1:
+		return "Farvardin"
+	case 2:
+		return "Ordibehesht"
+	case 3:
+		return "Khordad"
+	case 4:
+		return "Tir"
+	case 5:
+		return "Mordad"
+	case 6:
+		return "Shahrivar"
+	case 7:
+		return "Mehr"
+	case 8:
+		return "Aban"
+	case 9:
+		return "Azar"
+	case 10:
+		return "Dey"
+	case 11:
+		return "Bahman"
+	case 12:
+		return "Esfand"
+	default:
+		return ""
+	}
+}
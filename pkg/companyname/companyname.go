@@ -0,0 +1,116 @@
+// Package companyname normalizes trader names so that names returned by
+// VIES can be matched against names kept in other systems, such as an ERP.
+//
+// Normalization folds case, transliterates Greek and Cyrillic letters to
+// Latin, removes diacritics, drops punctuation and strips legal-form
+// designations such as "GmbH", "S.A." or "Sp. z o.o.":
+//
+//	companyname.Normalize("Müller Maschinenbau GmbH")     // "muller maschinenbau"
+//	companyname.Normalize("MULLER MASCHINENBAU G.m.b.H.") // "muller maschinenbau"
+//	companyname.Normalize("ΠΑΠΑΔΟΠΟΥΛΟΣ Α.Ε.")            // "papadopoulos"
+//
+// The rules are deliberately simple and deterministic; they reduce
+// formatting differences but do not detect different names for the same
+// company.
+package companyname
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize returns the comparison form of a company name: Fold followed by
+// StripLegalForm
+func Normalize(name string) string {
+	return StripLegalForm(Fold(name))
+}
+
+// Equal reports whether two company names have the same normalized form
+func Equal(a, b string) bool {
+	return Normalize(a) == Normalize(b)
+}
+
+// Fold lower-cases name, transliterates Greek and Cyrillic letters, removes
+// diacritics and punctuation, and collapses whitespace. Dots and apostrophes
+// are dropped without a trace, so "S.A." and "SA" fold alike; other
+// punctuation separates words.
+func Fold(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range greekDigraphs.Replace(strings.ToLower(name)) {
+		switch {
+		case r == '.' || r == '\'' || r == '’' || r == '´' || r == '`':
+			continue
+		case unicode.Is(unicode.Mn, r):
+			// combining marks of decomposed input, e.g. "e\u0301"
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			if latin, ok := transliteration[r]; ok {
+				b.WriteString(latin)
+			} else {
+				b.WriteRune(r)
+			}
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// StripLegalForm removes legal-form designations from the start and end of
+// a folded name, e.g. "gmbh" from "example gmbh" or "uab" from "uab example".
+// A name consisting only of a legal form is returned unchanged.
+func StripLegalForm(folded string) string {
+	words := strings.Fields(folded)
+	stripped := words
+	for {
+		n := matchSuffix(stripped)
+		if n == 0 || n == len(stripped) {
+			break
+		}
+		stripped = stripped[:len(stripped)-n]
+	}
+	for {
+		n := matchPrefix(stripped)
+		if n == 0 || n == len(stripped) {
+			break
+		}
+		stripped = stripped[n:]
+	}
+	return strings.Join(stripped, " ")
+}
+
+// matchSuffix returns the number of words of the longest legal form that
+// words ends with
+func matchSuffix(words []string) int {
+	for _, form := range legalForms {
+		if len(form) <= len(words) && equalWords(words[len(words)-len(form):], form) {
+			return len(form)
+		}
+	}
+	return 0
+}
+
+// matchPrefix returns the number of words of the longest legal form that
+// words starts with, considering only forms written in front of names
+func matchPrefix(words []string) int {
+	for _, form := range prefixLegalForms {
+		if len(form) <= len(words) && equalWords(words[:len(form)], form) {
+			return len(form)
+		}
+	}
+	return 0
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package companyname
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Müller Maschinenbau GmbH", "muller maschinenbau"},
+		{"MULLER MASCHINENBAU G.m.b.H.", "muller maschinenbau"},
+		{"Example GmbH & Co. KG", "example"},
+		{"Société Générale S.A.", "societe generale"},
+		{"Przykład Sp. z o.o.", "przyklad"},
+		{"Příklad s.r.o.", "priklad"},
+		{"Exempel AB (publ)", "exempel"},
+		{"UAB \"Pavyzdys\"", "pavyzdys"},
+		{"SIA Piemērs", "piemers"},
+		{"ΠΑΠΑΔΟΠΟΥΛΟΣ Α.Ε.", "papadopoulos"},
+		{"ПРИМЕР ЕООД", "primer"},
+		{"Café Central", "cafe central"},
+		{"O'Brien Ltd", "obrien"},
+		{"GmbH", "gmbh"}, // only a legal form: kept
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.name); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal("EXAMPLE B.V.", "Example BV") {
+		t.Error("Equal(EXAMPLE B.V., Example BV) = false, want true")
+	}
+	if Equal("Example GmbH", "Sample GmbH") {
+		t.Error("Equal(Example GmbH, Sample GmbH) = true, want false")
+	}
+}
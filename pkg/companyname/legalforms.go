@@ -0,0 +1,76 @@
+package companyname
+
+import (
+	"sort"
+	"strings"
+)
+
+// legalFormNames are legal-form designations of EU member states, written
+// as they usually appear after a company name. Dotted and undotted spellings
+// fold alike, so only one is listed.
+var legalFormNames = []string{
+	// Germany, Austria
+	"gmbh", "gmbh & co kg", "gmbh & co ohg", "ag", "ag & co kg", "kg", "ohg", "ug", "ug (haftungsbeschränkt)",
+	"e k", "ek", "e v", "ev", "eg", "kgaa", "se", "gesmbh", "mbh",
+	// France, Belgium, Luxembourg
+	"sa", "sas", "sasu", "sarl", "eurl", "snc", "scs", "sca", "sci", "scop",
+	"bvba", "sprl", "srl", "bv", "nv", "cv", "vof", "comm v",
+	// Italy
+	"spa", "s p a", "srls", "sapa", "sas", "snc", "sc", "scarl",
+	// Spain, Portugal
+	"sl", "slu", "sa", "sau", "scoop", "lda", "unipessoal lda", "sgps sa",
+	// Netherlands
+	"bv", "nv", "vof", "cv",
+	// Ireland, Malta, Cyprus
+	"ltd", "limited", "plc", "dac", "clg", "uc",
+	// Nordic countries
+	"ab", "publ ab", "ab (publ)", "a/s", "as", "aps", "oy", "oyj", "ky", "hf", "ehf",
+	// Poland
+	"sp z oo", "spółka z oo", "sp j", "sp k", "spółka akcyjna", "spółka z ograniczoną odpowiedzialnością",
+	// Czechia, Slovakia
+	"sro", "spol s r o", "spol sro", "as", "ks", "vos",
+	// Hungary
+	"kft", "zrt", "nyrt", "bt", "kkt", "rt",
+	// Slovenia, Croatia
+	"doo", "dd", "sp", "jdoo",
+	// Romania
+	"srl", "sa", "snc", "pfa",
+	// Bulgaria
+	"eood", "ood", "ead", "ad", "et",
+	// Baltic states
+	"uab", "ab", "mb", "sia", "as", "ou", "oü", "mtü", "tu",
+	// Greece, after transliteration
+	"ae", "epe", "ike", "oe", "ee", "monoprosopi ike", "monoprosopi epe",
+	// European forms
+	"se", "sce", "eeig", "ewiv",
+}
+
+// prefixLegalFormNames are legal forms that are commonly written in front
+// of the name, e.g. "UAB Example" or "SIA Example"
+var prefixLegalFormNames = []string{"uab", "ab", "mb", "sia", "as", "ou", "oü", "et", "ood", "eood"}
+
+// legalForms and prefixLegalForms are the folded legal forms as words,
+// longest first
+var (
+	legalForms       = foldForms(legalFormNames)
+	prefixLegalForms = foldForms(prefixLegalFormNames)
+)
+
+// foldForms folds legal-form names into word lists, without duplicates and
+// sorted by decreasing length so that the longest match wins
+func foldForms(names []string) [][]string {
+	seen := make(map[string]bool)
+	var forms [][]string
+	for _, name := range names {
+		folded := Fold(name)
+		if folded == "" || seen[folded] {
+			continue
+		}
+		seen[folded] = true
+		forms = append(forms, strings.Fields(folded))
+	}
+	sort.SliceStable(forms, func(i, j int) bool {
+		return len(forms[i]) > len(forms[j])
+	})
+	return forms
+}
@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// addressFormat selects how trader addresses are rendered; empty keeps
+// them as VIES returned them
+var addressFormat = ""
+
+// AddressFormats are the styles accepted by SetAddressFormat
+var AddressFormats = []string{"oneline", "multiline", "postal"}
+
+// SetAddressFormat selects how trader addresses are rendered in all output
+// formats:
+//   - "oneline": the address lines joined with ", "
+//   - "multiline": one line per address part, blank lines removed
+//   - "postal": the member state's postal order, e.g. postcode before city,
+//     followed by the country in capitals as on international mail
+//
+// An empty style keeps addresses as VIES returned them.
+func SetAddressFormat(style string) error {
+	if style != "" && !contains(AddressFormats, style) {
+		return fmt.Errorf("unknown address format: %s (supported: %s)", style, strings.Join(AddressFormats, ", "))
+	}
+	addressFormat = style
+	return nil
+}
+
+// postalStyle is the place of postcode and city in a member state's addresses
+type postalStyle int
+
+const (
+	// postcodeCity puts the postcode before the city on one line
+	postcodeCity postalStyle = iota
+	// cityPostcode puts the city before the postcode on one line (Malta)
+	cityPostcode
+	// cityCommaPostcode separates city and postcode with a comma (Latvia)
+	cityCommaPostcode
+	// postcodeLine puts the postcode on a line of its own after the city
+	// (Ireland's Eircode)
+	postcodeLine
+	// cityFirst puts the city first and the postcode last (Hungary)
+	cityFirst
+)
+
+// postalFormat describes the postcodes of a member state
+type postalFormat struct {
+	postcode *regexp.Regexp
+	style    postalStyle
+}
+
+// postalFormats are the postcode patterns and postal order by member state
+var postalFormats = map[string]postalFormat{
+	"AT": {regexp.MustCompile(`\b\d{4}\b`), postcodeCity},
+	"BE": {regexp.MustCompile(`\b\d{4}\b`), postcodeCity},
+	"BG": {regexp.MustCompile(`\b\d{4}\b`), postcodeCity},
+	"CY": {regexp.MustCompile(`\b\d{4}\b`), postcodeCity},
+	"CZ": {regexp.MustCompile(`\b\d{3} ?\d{2}\b`), postcodeCity},
+	"DE": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"DK": {regexp.MustCompile(`\b\d{4}\b`), postcodeCity},
+	"EE": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"EL": {regexp.MustCompile(`\b\d{3} ?\d{2}\b`), postcodeCity},
+	"ES": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"FI": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"FR": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"HR": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"HU": {regexp.MustCompile(`\b\d{4}\b`), cityFirst},
+	"IE": {regexp.MustCompile(`\b[A-Z]\d[\dW] ?[0-9A-Z]{4}\b`), postcodeLine},
+	"IT": {regexp.MustCompile(`\b\d{5}\b`), postcodeCity},
+	"LT": {regexp.MustCompile(`\b(?:LT-)?\d{5}\b`), postcodeCity},
+	"LU": {regexp.MustCompile(`\b(?:L-)?\d{4}\b`), postcodeCity},
+	"LV": {regexp.MustCompile(`\b(?:LV-)?\d{4}\b`), cityCommaPostcode},
+	"MT": {regexp.MustCompile(`\b[A-Z]{3} ?\d{2,4}\b`), cityPostcode},
+	"NL": {regexp.MustCompile(`\b\d{4} ?[A-Z]{2}\b`), postcodeCity},
+	"PL": {regexp.MustCompile(`\b\d{2}-\d{3}\b`), postcodeCity},
+	"PT": {regexp.MustCompile(`\b\d{4}-\d{3}\b`), postcodeCity},
+	"RO": {regexp.MustCompile(`\b\d{6}\b`), postcodeCity},
+	"SE": {regexp.MustCompile(`\b\d{3} ?\d{2}\b`), postcodeCity},
+	"SI": {regexp.MustCompile(`\b(?:SI-)?\d{4}\b`), postcodeCity},
+	"SK": {regexp.MustCompile(`\b\d{3} ?\d{2}\b`), postcodeCity},
+}
+
+// formatAddress renders an address of a member state in the selected style
+func formatAddress(address, countryCode string) string {
+	if addressFormat == "" || address == "" || address == vies.RedactedValue {
+		return address
+	}
+	lines := addressLines(address)
+	switch addressFormat {
+	case "oneline":
+		return strings.Join(lines, ", ")
+	case "postal":
+		return strings.Join(postalLines(lines, countryCode), "\n")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addressLines splits an address into its trimmed, non-empty lines. Single
+// line addresses are split at commas, which some member states use instead
+// of line breaks.
+func addressLines(address string) []string {
+	parts := strings.Split(strings.ReplaceAll(address, "\r\n", "\n"), "\n")
+	if len(parts) == 1 {
+		parts = strings.Split(address, ",")
+	}
+	var lines []string
+	for _, part := range parts {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			lines = append(lines, part)
+		}
+	}
+	return lines
+}
+
+// postalLines puts the postcode and city of an address in the member
+// state's order and adds the country in capitals. Addresses whose postcode
+// cannot be found keep their order.
+func postalLines(lines []string, countryCode string) []string {
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	// The English ISO 3166 name, independent of the output locale
+	_, name, ok := vies.ISOCountry(countryCode)
+	if !ok {
+		name = countryCode
+	}
+	country := strings.ToUpper(name)
+
+	format, ok := postalFormats[countryCode]
+	line := -1
+	var postcode string
+	if ok {
+		for i := len(lines) - 1; i >= 0 && line < 0; i-- {
+			if postcode = format.postcode.FindString(lines[i]); postcode != "" {
+				line = i
+			}
+		}
+	}
+	if line < 0 {
+		return append(lines, country)
+	}
+
+	city := strings.Trim(strings.Replace(lines[line], postcode, "", 1), " ,-")
+	before := append([]string(nil), lines[:line]...)
+	after := lines[line+1:]
+	var place []string
+	switch format.style {
+	case cityPostcode:
+		place = []string{strings.TrimSpace(city + " " + postcode)}
+	case cityCommaPostcode:
+		if !strings.HasPrefix(postcode, countryCode+"-") {
+			postcode = countryCode + "-" + postcode
+		}
+		place = []string{strings.TrimPrefix(city+", "+postcode, ", ")}
+	case postcodeLine:
+		place = []string{city, postcode}
+	case cityFirst:
+		if city != "" {
+			before = append([]string{city}, before...)
+		}
+		place = []string{postcode}
+	default:
+		place = []string{strings.TrimSpace(postcode + " " + city)}
+	}
+
+	var out []string
+	for _, l := range append(append(append(before, place...), after...), country) {
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
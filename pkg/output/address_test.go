@@ -0,0 +1,41 @@
+package output
+
+import (
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestFormatAddress(t *testing.T) {
+	defer SetAddressFormat("")
+
+	tests := []struct {
+		style, country, address, want string
+	}{
+		{"", "DE", "Musterstraße 1 \n\n12345  Berlin", "Musterstraße 1 \n\n12345  Berlin"},
+		{"oneline", "DE", "Musterstraße 1 \n\n12345  Berlin", "Musterstraße 1, 12345 Berlin"},
+		{"multiline", "DE", "Musterstraße 1 \n\n12345  Berlin", "Musterstraße 1\n12345 Berlin"},
+		{"multiline", "DE", "MUSTERSTR. 1, 12345 BERLIN", "MUSTERSTR. 1\n12345 BERLIN"},
+		{"postal", "DE", "MUSTERSTR. 1, BERLIN 12345", "MUSTERSTR. 1\n12345 BERLIN\nGERMANY"},
+		{"postal", "NL", "Dorpsstraat 1\nAmsterdam 1012 AB", "Dorpsstraat 1\n1012 AB Amsterdam\nNETHERLANDS"},
+		{"postal", "MT", "12 Triq il-Kbira\nVLT 1117 Valletta", "12 Triq il-Kbira\nValletta VLT 1117\nMALTA"},
+		{"postal", "LV", "Brivibas iela 1\n1050 Riga", "Brivibas iela 1\nRiga, LV-1050\nLATVIA"},
+		{"postal", "HU", "Fő utca 1\n1011 Budapest", "Budapest\nFő utca 1\n1011\nHUNGARY"},
+		{"postal", "IE", "1 Main Street\nDublin 2 D02 X285", "1 Main Street\nDublin 2\nD02 X285\nIRELAND"},
+		{"postal", "FR", "1 RUE DE LA PAIX\nPARIS", "1 RUE DE LA PAIX\nPARIS\nFRANCE"},
+		{"postal", "EL", "ODOS 1\n10431 ATHINA", "ODOS 1\n10431 ATHINA\nGREECE"},
+		{"postal", "DE", vies.RedactedValue, vies.RedactedValue},
+	}
+	for _, tt := range tests {
+		if err := SetAddressFormat(tt.style); err != nil {
+			t.Fatal(err)
+		}
+		if got := formatAddress(tt.address, tt.country); got != tt.want {
+			t.Errorf("%s %s %q = %q, want %q", tt.style, tt.country, tt.address, got, tt.want)
+		}
+	}
+
+	if err := SetAddressFormat("envelope"); err == nil {
+		t.Error("expected an error for an unknown address format")
+	}
+}
@@ -0,0 +1,220 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"l22.io/viesquery/pkg/calendar"
+)
+
+// dateStyles lists the supported date styles, default first
+var dateStyles = []string{"gce-verbose", "iso-date", "rfc3339", "unix", "iso-week", "iso-ordinal", "jdn", "custom"}
+
+// calendars lists the supported calendar systems for gce-verbose, default first
+var calendars = []string{"gregorian", "julian", "buddhist", "minguo", "japanese", "islamic", "islamic-umalqura", "persian", "hebrew"}
+
+// FormatRequestDate renders the request date string according to dateStyle and calendar options.
+// Supported styles:
+// - gce-verbose (default): Natural language sentence; calendar-sensitive
+// - iso-date: "2025-09-09" (calendar-neutral; Gregorian)
+// - rfc3339: RFC 3339 timestamp of midnight of the request date (Gregorian)
+// - unix: Unix epoch seconds of midnight of the request date (Gregorian)
+// - iso-week: ISO week date, e.g., "2025-W37-2" (Gregorian)
+// - iso-ordinal: ISO ordinal date, e.g., "2025-252" (Gregorian)
+// - jdn: Julian Day Number of the request date, e.g., "2460928"
+// - custom: user-supplied layout set via SetDateFormat (Gregorian)
+// gce-verbose is written in the configured locale, including the month
+// names of the other calendars.
+// Supported calendars for gce-verbose:
+// - gregorian (default)
+// - julian
+// - buddhist (Thai solar)
+// - minguo (ROC)
+// - japanese (era-based)
+// - islamic (Hijri, tabular civil)
+// - islamic-umalqura (Hijri, Umm al-Qura tables for AH 1356-1500)
+// - persian (Solar Hijri / Jalali, arithmetic)
+// - hebrew (arithmetic, molad-based)
+//
+// With a time zone set (SetTimeZone), the request date is converted into it
+// first, so every style shows the date in that zone, which may be the day
+// before or after the date VIES reported.
+func FormatRequestDate(t time.Time) string {
+	if timeZone != nil {
+		t = t.In(timeZone)
+	}
+	switch dateStyle {
+	case "iso-date":
+		return t.Format("2006-01-02")
+	case "rfc3339":
+		return startOfDay(t).Format(time.RFC3339)
+	case "unix":
+		return fmt.Sprintf("%d", startOfDay(t).Unix())
+	case "iso-week":
+		y, w := t.ISOWeek()
+		isoWeekday := int(t.Weekday())
+		if isoWeekday == 0 {
+			isoWeekday = 7
+		}
+		return fmt.Sprintf("%04d-W%02d-%d", y, w, isoWeekday)
+	case "iso-ordinal":
+		return fmt.Sprintf("%04d-%03d", t.Year(), t.YearDay())
+	case "jdn":
+		return fmt.Sprintf("%d", calendar.GregorianToJDN(t.Year(), int(t.Month()), t.Day()))
+	case "custom":
+		return formatCustom(t, dateLayout)
+	case "gce-verbose":
+		fallthrough
+	default:
+		return verboseCalendarSentence(t)
+	}
+}
+
+// startOfDay returns midnight of the request date in the configured time
+// zone, default UTC. t is already converted into that zone.
+func startOfDay(t time.Time) time.Time {
+	loc := time.UTC
+	if timeZone != nil {
+		loc = timeZone
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// strftimeDirectives maps strftime conversion specifiers to Go layout elements
+var strftimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'e': "_2",
+	'j': "002",
+	'B': "January",
+	'b': "Jan",
+	'h': "Jan",
+	'A': "Monday",
+	'a': "Mon",
+	'H': "15",
+	'I': "03",
+	'M': "04",
+	'S': "05",
+	'p': "PM",
+	'Z': "MST",
+	'z': "-0700",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'%': "%",
+}
+
+// formatCustom renders t using a strftime pattern, or a Go reference layout
+// if the format contains no '%' directives
+func formatCustom(t time.Time, format string) string {
+	if format == "" {
+		return t.Format("2006-01-02")
+	}
+	if !strings.Contains(format, "%") {
+		return t.Format(format)
+	}
+	// Format each directive separately so literal text is never
+	// mistaken for a Go layout element
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] == '%' && i+1 < len(format) {
+			if layout, ok := strftimeDirectives[format[i+1]]; ok {
+				if layout == "%" {
+					b.WriteByte('%')
+				} else {
+					b.WriteString(t.Format(layout))
+				}
+				i++
+				continue
+			}
+		}
+		b.WriteByte(format[i])
+	}
+	return b.String()
+}
+
+// verboseCalendarSentence renders t as a sentence in the configured
+// calendar and locale
+func verboseCalendarSentence(t time.Time) string {
+	calendarName := calendarSystem
+	sentence, data := verboseSentence(calendarName)
+	if data == nil {
+		calendarName = "gregorian"
+		if sentence, data = verboseSentence(calendarName); data == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+
+	y, m, d := t.Year(), int(t.Month()), t.Day()
+	month, day, year, era := data.dateMonth(m), d, y, ""
+	switch calendarName {
+	case "buddhist":
+		year = y + 543
+	case "minguo":
+		year = y - 1911
+	case "julian":
+		jy, jm, jd := calendar.JulianFromGregorian(y, m, d)
+		month, day, year = data.dateMonth(jm), jd, jy
+	case "japanese":
+		era, year = calendar.JapaneseEra(y, m, d)
+	case "islamic":
+		iy, im, id := calendar.IslamicCivilFromGregorian(y, m, d)
+		month, day, year = data.calendarName(calendar.IslamicMonthName(im)), id, iy
+	case "islamic-umalqura":
+		iy, im, id, ok := calendar.UmmAlQuraFromGregorian(y, m, d)
+		if !ok {
+			// Outside the published tables: fall back to the tabular civil calendar
+			iy, im, id = calendar.IslamicCivilFromGregorian(y, m, d)
+		}
+		month, day, year = data.calendarName(calendar.IslamicMonthName(im)), id, iy
+	case "persian":
+		py, pm, pd := calendar.PersianFromGregorian(y, m, d)
+		month, day, year = data.calendarName(calendar.PersianMonthName(pm)), pd, py
+	case "hebrew":
+		hy, hm, hd := calendar.HebrewFromGregorian(y, m, d)
+		month, day, year = data.calendarName(calendar.HebrewMonthName(hm, hy)), hd, hy
+	}
+
+	return strings.NewReplacer(
+		"{weekday}", localizedWeekday(t.Weekday()),
+		"{month}", month,
+		"{day}", strconv.Itoa(day),
+		"{ordinal}", data.ordinal(day),
+		"{year}", strconv.Itoa(year),
+		"{era}", era,
+	).Replace(sentence)
+}
+
+func monthName(m int) string {
+	switch m {
+	case 1:
+		return "January"
+	case 2:
+		return "February"
+	case 3:
+		return "March"
+	case 4:
+		return "April"
+	case 5:
+		return "May"
+	case 6:
+		return "June"
+	case 7:
+		return "July"
+	case 8:
+		return "August"
+	case 9:
+		return "September"
+	case 10:
+		return "October"
+	case 11:
+		return "November"
+	case 12:
+		return "December"
+	default:
+		return ""
+	}
+}
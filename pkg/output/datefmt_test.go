@@ -0,0 +1,176 @@
+package output
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// withDateOptions sets the date options for a test and restores the defaults
+func withDateOptions(t *testing.T, style, cal, loc string) {
+	t.Helper()
+	if err := SetDateOptions(style, cal); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLocale(loc); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dateStyle, calendarSystem, dateLayout, timeZone = "gce-verbose", "gregorian", "", nil
+		SetLocale(defaultLocale)
+	})
+}
+
+func TestVerboseSentenceLocales(t *testing.T) {
+	// Thursday, 9 January 2025 is 9 Tevet 5785 and 9 Rajab 1446
+	date := time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale, calendar, want string
+	}{
+		{"en", "gregorian", "This request was made on Thursday, January 9th of the year 2025 of the common era."},
+		{"de", "gregorian", "Diese Abfrage erfolgte am Donnerstag, dem 9. Januar des Jahres 2025 unserer Zeitrechnung."},
+		{"fr", "gregorian", "Cette demande a été effectuée le jeudi 9 janvier de l'an 2025 de l'ère commune."},
+		{"en", "hebrew", "This request was made on Thursday, Tevet 9th in year 5785 AM of the Hebrew calendar."},
+		{"de", "hebrew", "Diese Abfrage erfolgte am Donnerstag, dem 9. Tevet des Jahres 5785 des jüdischen Kalenders."},
+		{"fr", "hebrew", "Cette demande a été effectuée le jeudi 9 Tevet de l'an 5785 du calendrier hébraïque."},
+		{"en", "islamic", "This request was made on Thursday, Rajab 9th in year 1446 AH of the Islamic (Hijri) calendar."},
+		{"de", "islamic", "Diese Abfrage erfolgte am Donnerstag, dem 9. Radschab des Jahres 1446 n. H. des islamischen Kalenders (Hidschra)."},
+		{"fr", "islamic", "Cette demande a été effectuée le jeudi 9 Rajab de l'an 1446 de l'Hégire du calendrier islamique."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale+"/"+tt.calendar, func(t *testing.T) {
+			withDateOptions(t, "gce-verbose", tt.calendar, tt.locale)
+			if got := FormatRequestDate(date); got != tt.want {
+				t.Errorf("FormatRequestDate() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerboseOrdinals(t *testing.T) {
+	tests := []struct {
+		locale string
+		day    int
+		want   string
+	}{
+		{"en", 1, "1st"}, {"en", 2, "2nd"}, {"en", 3, "3rd"}, {"en", 11, "11th"},
+		{"en", 12, "12th"}, {"en", 13, "13th"}, {"en", 21, "21st"}, {"en", 22, "22nd"},
+		{"en", 23, "23rd"}, {"en", 31, "31st"},
+		{"de", 1, "1."}, {"de", 22, "22."},
+		{"fr", 1, "1er"}, {"fr", 2, "2"},
+	}
+	for _, tt := range tests {
+		if got := strconv.Itoa(tt.day) + lookupLocale(tt.locale).ordinal(tt.day); got != tt.want {
+			t.Errorf("%s ordinal of %d = %q, want %q", tt.locale, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestVerboseSentencesComplete(t *testing.T) {
+	for _, loc := range SupportedLocales() {
+		for _, cal := range calendars {
+			if lookupLocale(loc).VerboseDate.Sentences[cal] == "" {
+				t.Errorf("locale %s has no verbose sentence for the %s calendar", loc, cal)
+			}
+		}
+	}
+}
+
+func TestFormatCustom(t *testing.T) {
+	date := time.Date(2025, 1, 9, 14, 5, 7, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		format, want string
+	}{
+		{"%Y", "2025"},
+		{"%y", "25"},
+		{"%m", "01"},
+		{"%d", "09"},
+		{"%e", " 9"},
+		{"%j", "009"},
+		{"%B", "January"},
+		{"%b", "Jan"},
+		{"%h", "Jan"},
+		{"%A", "Thursday"},
+		{"%a", "Thu"},
+		{"%H", "14"},
+		{"%I", "02"},
+		{"%M", "05"},
+		{"%S", "07"},
+		{"%p", "PM"},
+		{"%Z", "CET"},
+		{"%z", "+0100"},
+		{"%F", "2025-01-09"},
+		{"%T", "14:05:07"},
+		{"%%", "%"},
+		{"100%% on %d.%m.", "100% on 09.01."},
+		{"%%Y", "%Y"},
+		{"Monday %d", "Monday 09"}, // literal text is not a Go layout
+		{"%q %Y", "%q 2025"},       // unknown directives are kept
+		{"%", "%"},
+		{"%Y%", "2025%"},
+		{"02.01.2006", "09.01.2025"}, // a Go layout without directives
+		{"", "2025-01-09"},
+	}
+	for _, tt := range tests {
+		if got := formatCustom(date, tt.format); got != tt.want {
+			t.Errorf("formatCustom(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestOrdinalAndJDNStyles(t *testing.T) {
+	tests := []struct {
+		style string
+		date  time.Time
+		want  string
+	}{
+		{"iso-ordinal", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "2025-001"},
+		{"iso-ordinal", time.Date(2025, 9, 9, 0, 0, 0, 0, time.UTC), "2025-252"},
+		{"iso-ordinal", time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), "2025-365"},
+		{"iso-ordinal", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), "2024-366"},
+		{"iso-ordinal", time.Date(2000, 12, 31, 0, 0, 0, 0, time.UTC), "2000-366"},
+		{"iso-ordinal", time.Date(1900, 12, 31, 0, 0, 0, 0, time.UTC), "1900-365"},
+		// Reference values of the Julian Day Number at noon UT
+		{"jdn", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), "2451545"},
+		{"jdn", time.Date(1858, 11, 17, 0, 0, 0, 0, time.UTC), "2400001"}, // MJD 0 begins at midnight
+		{"jdn", time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), "2440588"},
+		{"jdn", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), "2460370"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style+"/"+tt.date.Format("2006-01-02"), func(t *testing.T) {
+			withDateOptions(t, tt.style, "gregorian", defaultLocale)
+			if got := FormatRequestDate(tt.date); got != tt.want {
+				t.Errorf("FormatRequestDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeZoneConversion(t *testing.T) {
+	// VIES reported 9 January in the member state's zone (+01:00), which is
+	// still 8 January in New York and already 9 January in Tokyo
+	date := time.Date(2025, 1, 9, 0, 0, 0, 0, time.FixedZone("", 3600))
+	tests := []struct {
+		style, zone, want string
+	}{
+		{"gce-verbose", "America/New_York", "This request was made on Wednesday, January 8th of the year 2025 of the common era."},
+		{"gce-verbose", "Asia/Tokyo", "This request was made on Thursday, January 9th of the year 2025 of the common era."},
+		{"iso-date", "America/New_York", "2025-01-08"},
+		{"rfc3339", "America/New_York", "2025-01-08T00:00:00-05:00"},
+		{"rfc3339", "Asia/Tokyo", "2025-01-09T00:00:00+09:00"},
+		{"rfc3339", "", "2025-01-09T00:00:00Z"}, // without a zone the reported date is kept
+		{"unix", "America/New_York", "1736312400"},
+		{"unix", "Asia/Tokyo", "1736348400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style+"/"+tt.zone, func(t *testing.T) {
+			withDateOptions(t, tt.style, "gregorian", defaultLocale)
+			if err := SetTimeZone(tt.zone); err != nil {
+				t.Fatal(err)
+			}
+			if got := FormatRequestDate(date); got != tt.want {
+				t.Errorf("FormatRequestDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// resultFieldNames are the JSON names of the result fields, in struct order
+var resultFieldNames = jsonFieldNames(reflect.TypeOf(vies.CheckVatResult{}))
+
+// resultFieldZeros are the values of selected result fields that were
+// omitted because they are empty: "" for strings, null for objects
+var resultFieldZeros = jsonFieldZeros(reflect.TypeOf(vies.CheckVatResult{}))
+
+// selectedFields restricts result output to these fields; nil means all
+var selectedFields map[string]bool
+
+// jsonFieldNames returns the JSON names of a struct type's fields
+func jsonFieldNames(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonFieldZeros returns the JSON zero value of each field of a struct
+// type by JSON name: nil for pointers, maps and slices, which encode as
+// null, and the Go zero value otherwise
+func jsonFieldZeros(typ reflect.Type) map[string]any {
+	zeros := make(map[string]any)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			zeros[name] = nil
+		default:
+			zeros[name] = reflect.Zero(field.Type).Interface()
+		}
+	}
+	return zeros
+}
+
+// ResultFields returns the field names accepted by SetFields
+func ResultFields() []string {
+	return append([]string(nil), resultFieldNames...)
+}
+
+// SetFields restricts result output to the named fields, using the JSON
+// field names (e.g. "valid", "name", "requestDate"). An empty list restores
+// the full output; unknown names are rejected. Error output is unaffected.
+func SetFields(fields []string) error {
+	if len(fields) == 0 {
+		selectedFields = nil
+		return nil
+	}
+	selected := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !contains(resultFieldNames, field) {
+			return fmt.Errorf("unknown field: %s (supported: %s)", field, strings.Join(resultFieldNames, ", "))
+		}
+		selected[field] = true
+	}
+	selectedFields = selected
+	return nil
+}
+
+// showField reports whether a result field is part of the output
+func showField(name string) bool {
+	return selectedFields == nil || selectedFields[name]
+}
+
+// resultObject returns the JSON object of result as generic values. With a
+// field selection only the selected fields are kept, even when empty: as ""
+// for strings and null for objects such as timing.
+func resultObject(result *vies.CheckVatResult) (map[string]any, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	if selectedFields == nil {
+		return all, nil
+	}
+	selected := make(map[string]any, len(selectedFields))
+	for name := range selectedFields {
+		value, ok := all[name]
+		if !ok {
+			value = resultFieldZeros[name] // omitted because empty
+		}
+		selected[name] = value
+	}
+	return selected, nil
+}
+
+// sortedKeys returns the keys of an extensions map in order
+func sortedKeys(extensions map[string]any) []string {
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// extensionText renders an extension value for the text based encodings:
+// strings as they are, anything else as JSON
+func extensionText(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,210 @@
+// Package output renders validation results and errors in the formats of
+// the viesquery CLI. Formatters register themselves by name with Register;
+// programs embedding viesquery can register their own formats the same way,
+// and the CLI lists every registered format in its help.
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Global date formatting options (can be configured from main)
+var (
+	dateStyle      = "gce-verbose" // default: Gregorian, Common Era, verbose sentence
+	calendarSystem = "gregorian"
+	dateLayout     = ""           // Go reference layout or strftime pattern used by the "custom" style
+	timeZone       *time.Location // zone for rendering request dates; nil means UTC
+)
+
+// redact masks trader names and addresses in all formatters when enabled
+var redact = false
+
+// deterministic replaces run-dependent values with fixed placeholders
+var deterministic = false
+
+// DeterministicDate is the request date shown in deterministic mode
+var DeterministicDate = time.Unix(0, 0).UTC()
+
+// verbose adds diagnostic details such as raw response bodies to error output
+var verbose = false
+
+// SetDateOptions configures date rendering behaviour for all formatters.
+// Empty values keep the current setting; unknown values are rejected.
+func SetDateOptions(style, cal string) error {
+	if style != "" && !contains(dateStyles, style) {
+		return fmt.Errorf("unsupported date style: %s (supported: %s)", style, strings.Join(dateStyles, ", "))
+	}
+	if cal != "" && !contains(calendars, cal) {
+		return fmt.Errorf("unsupported calendar: %s (supported: %s)", cal, strings.Join(calendars, ", "))
+	}
+	if style != "" {
+		dateStyle = style
+	}
+	if cal != "" {
+		calendarSystem = cal
+	}
+	return nil
+}
+
+// SupportedDateStyles returns the date styles accepted by SetDateOptions
+func SupportedDateStyles() []string {
+	return append([]string(nil), dateStyles...)
+}
+
+// SupportedCalendars returns the calendar systems accepted by SetDateOptions
+func SupportedCalendars() []string {
+	return append([]string(nil), calendars...)
+}
+
+// contains reports whether list includes value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDateFormat configures the layout used by the "custom" date style. Both Go
+// reference layouts ("02.01.2006") and strftime patterns ("%d.%m.%Y") are accepted.
+func SetDateFormat(format string) {
+	if format != "" {
+		dateLayout = format
+	}
+}
+
+// SetTimeZone configures the IANA time zone (e.g. "Europe/Berlin") used when
+// rendering request dates as instants. An empty name restores the UTC default.
+func SetTimeZone(name string) error {
+	if name == "" {
+		timeZone = nil
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown time zone: %s", name)
+	}
+	timeZone = loc
+	return nil
+}
+
+// SetRedaction enables or disables masking of personal data in output
+func SetRedaction(enabled bool) {
+	redact = enabled
+}
+
+// SetDeterministic enables or disables deterministic output for golden-file
+// tests: request dates are replaced by DeterministicDate and durations by 0
+func SetDeterministic(enabled bool) {
+	deterministic = enabled
+}
+
+// SetVerbose enables diagnostic details in error output
+func SetVerbose(enabled bool) {
+	verbose = enabled
+}
+
+// rawBodyForOutput returns the raw response body of a service error as it may
+// be shown to the user: only in verbose mode, and redacted if requested
+func rawBodyForOutput(e *vies.ServiceError) string {
+	if !verbose || e.RawBody == "" {
+		return ""
+	}
+	if redact {
+		return string(vies.RedactPayload([]byte(e.RawBody)))
+	}
+	return e.RawBody
+}
+
+// faultDetailForOutput returns the fault detail fields of a service error,
+// redacted if requested
+func faultDetailForOutput(e *vies.ServiceError) []vies.FaultDetailField {
+	if e.FaultDetail == nil {
+		return nil
+	}
+	if redact {
+		return vies.RedactFaultDetail(e.FaultDetail).Fields
+	}
+	return e.FaultDetail.Fields
+}
+
+// prepareResult applies output-wide transformations before formatting
+func prepareResult(result *vies.CheckVatResult) *vies.CheckVatResult {
+	if redact {
+		result = vies.Redact(result)
+	}
+	if deterministic {
+		fixed := *result
+		fixed.RequestDate = DeterministicDate
+		if fixed.RawRequestDate != "" {
+			fixed.RawRequestDate = DeterministicDate.Format("2006-01-02Z07:00")
+		}
+		fixed.DurationMs = 0
+		fixed.Timing = nil
+		result = &fixed
+	}
+	if addressFormat != "" && result.Address != "" {
+		formatted := *result
+		formatted.Address = formatAddress(result.Address, result.CountryCode)
+		result = &formatted
+	}
+	return result
+}
+
+// Formatter defines the interface for output formatting
+type Formatter interface {
+	Format(result *vies.CheckVatResult) (string, error)
+	FormatError(err error) (string, error)
+}
+
+// registry holds the formatters available by name. Formatters register
+// themselves from init functions; embedders may add their own via Register.
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Formatter)
+)
+
+// Register makes a formatter available under the given name. Like
+// database/sql.Register it panics if the formatter is nil or the name is
+// already taken, so conflicts surface at program start.
+func Register(name string, formatter Formatter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if formatter == nil {
+		panic("output: Register formatter is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("output: Register called twice for formatter " + name)
+	}
+	registry[name] = formatter
+}
+
+// GetFormatter returns a registered formatter by name
+func GetFormatter(format string) (Formatter, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	formatter, exists := registry[format]
+	if !exists {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	return formatter, nil
+}
+
+// SupportedFormats returns the names of all registered formatters, sorted
+func SupportedFormats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	formats := make([]string, 0, len(registry))
+	for name := range registry {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetDateOptions(t *testing.T) {
+	t.Cleanup(func() {
+		dateStyle, calendarSystem = "gce-verbose", "gregorian"
+	})
+	tests := []struct {
+		name, style, cal   string
+		wantStyle, wantCal string
+		wantErr            string
+	}{
+		{"both", "iso-date", "hebrew", "iso-date", "hebrew", ""},
+		{"empty keeps", "", "", "iso-date", "hebrew", ""},
+		{"style only", "rfc3339", "", "rfc3339", "hebrew", ""},
+		{"unknown style", "roman", "gregorian", "rfc3339", "hebrew", "unsupported date style: roman"},
+		{"unknown calendar", "iso-date", "mayan", "rfc3339", "hebrew", "unsupported calendar: mayan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetDateOptions(tt.style, tt.cal)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			// A rejected call leaves both settings unchanged
+			if dateStyle != tt.wantStyle || calendarSystem != tt.wantCal {
+				t.Errorf("settings = %s/%s, want %s/%s", dateStyle, calendarSystem, tt.wantStyle, tt.wantCal)
+			}
+		})
+	}
+}
+
+func TestSetTimeZone(t *testing.T) {
+	t.Cleanup(func() { timeZone = nil })
+	if err := SetTimeZone("Europe/Berlin"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Mars/Olympus_Mons", "UTC+2", "../etc/passwd"} {
+		err := SetTimeZone(name)
+		if err == nil || !strings.Contains(err.Error(), "unknown time zone: "+name) {
+			t.Errorf("SetTimeZone(%q) error = %v", name, err)
+		}
+		if timeZone == nil || timeZone.String() != "Europe/Berlin" {
+			t.Errorf("SetTimeZone(%q) changed the zone to %v", name, timeZone)
+		}
+	}
+	if err := SetTimeZone(""); err != nil || timeZone != nil {
+		t.Errorf("SetTimeZone(\"\") = %v, zone %v; want the UTC default", err, timeZone)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const name = "test-register"
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, name)
+		registryMu.Unlock()
+	})
+	Register(name, NewPlainFormatter())
+	if _, err := GetFormatter(name); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, format string
+		formatter    Formatter
+		wantPanic    string
+	}{
+		{"duplicate", name, NewPlainFormatter(), "output: Register called twice for formatter " + name},
+		{"duplicate builtin", "json", NewPlainFormatter(), "output: Register called twice for formatter json"},
+		{"nil formatter", "test-nil", nil, "output: Register formatter is nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != tt.wantPanic {
+					t.Errorf("Register panicked with %v, want %q", r, tt.wantPanic)
+				}
+			}()
+			Register(tt.format, tt.formatter)
+		})
+	}
+	if _, err := GetFormatter("test-nil"); err == nil {
+		t.Error("nil formatter was registered")
+	}
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// SchemaVersion identifies the JSON output contract. It only changes when a
+// field is removed, renamed or changes meaning; new fields may be added to
+// the same version at any time. See docs/json-output.md.
+const SchemaVersion = "1"
+
+// jsonSchema is the JSON Schema describing the envelope for SchemaVersion
+//
+//go:embed schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON Schema (draft 2020-12) for the result and error
+// payloads produced by the JSON formatter
+func JSONSchema() []byte {
+	return append([]byte(nil), jsonSchema...)
+}
+
+// Envelope is the stable top-level JSON document. Exactly one of Result and
+// Error is set.
+type Envelope struct {
+	SchemaVersion string               `json:"schemaVersion"`
+	Result        *vies.CheckVatResult `json:"result,omitempty"`
+	Error         *ErrorResponse       `json:"error,omitempty"`
+}
+
+// JSONFormatter formats output as JSON
+type JSONFormatter struct{}
+
+func init() {
+	Register("json", NewJSONFormatter())
+}
+
+// NewJSONFormatter creates a new JSON formatter
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{}
+}
+
+// Format formats a validation result as JSON
+func (f *JSONFormatter) Format(result *vies.CheckVatResult) (string, error) {
+	result = prepareResult(result)
+	if selectedFields != nil {
+		selected, err := resultObject(result)
+		if err != nil {
+			return "", err
+		}
+		return marshalEnvelope(struct {
+			SchemaVersion string         `json:"schemaVersion"`
+			Result        map[string]any `json:"result"`
+		}{SchemaVersion, selected})
+	}
+	return marshalEnvelope(Envelope{SchemaVersion: SchemaVersion, Result: result})
+}
+
+// ErrorResponse represents an error in JSON format
+type ErrorResponse struct {
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	VATNumber  string `json:"vatNumber,omitempty"`
+	HTTPStatus int    `json:"httpStatus,omitempty"`
+	FaultCode  string `json:"faultCode,omitempty"`
+	RawBody    string `json:"rawBody,omitempty"`
+	// Suggestions and Hint help correct near-miss inputs
+	Suggestions []string `json:"suggestions,omitempty"`
+	Hint        string   `json:"hint,omitempty"`
+	// TestScenario labels faults simulated by the VIES test service
+	TestScenario string `json:"testScenario,omitempty"`
+	// FaultDetail holds the member state payload of a SOAP fault
+	FaultDetail []vies.FaultDetailField `json:"faultDetail,omitempty"`
+}
+
+// FormatError formats an error as JSON
+func (f *JSONFormatter) FormatError(err error) (string, error) {
+	return marshalEnvelope(Envelope{SchemaVersion: SchemaVersion, Error: newErrorResponse(err)})
+}
+
+// newErrorResponse collects the details of err shown by the structured
+// formatters
+func newErrorResponse(err error) *ErrorResponse {
+	var errorResponse ErrorResponse
+
+	errorResponse.Message = err.Error()
+
+	// Errors may be wrapped, e.g. by a Checker adding context
+	var validationErr *vies.ValidationError
+	var serviceErr *vies.ServiceError
+	switch {
+	case errors.As(err, &validationErr):
+		errorResponse.Code = validationErr.Code
+		errorResponse.VATNumber = validationErr.VATNumber
+		errorResponse.Suggestions = validationErr.Suggestions
+		errorResponse.Hint = validationErr.Hint
+	case errors.As(err, &serviceErr):
+		errorResponse.Code = serviceErr.Code
+		errorResponse.VATNumber = serviceErr.VATNumber
+		errorResponse.HTTPStatus = serviceErr.HTTPStatus
+		errorResponse.FaultCode = serviceErr.FaultCode
+		errorResponse.RawBody = rawBodyForOutput(serviceErr)
+		errorResponse.TestScenario = serviceErr.TestScenario
+		errorResponse.FaultDetail = faultDetailForOutput(serviceErr)
+	}
+
+	return &errorResponse
+}
+
+// marshalEnvelope renders an envelope as indented JSON
+func marshalEnvelope(envelope any) (string, error) {
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
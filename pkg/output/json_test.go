@@ -0,0 +1,176 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// TestJSONSchemaCoversFields guards against the embedded schema drifting from
+// the structs the JSON formatter actually marshals
+func TestJSONSchemaCoversFields(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(JSONSchema(), &schema); err != nil {
+		t.Fatalf("embedded schema is not valid JSON: %v", err)
+	}
+
+	checks := map[string]reflect.Type{
+		"result": reflect.TypeOf(vies.CheckVatResult{}),
+		"error":  reflect.TypeOf(ErrorResponse{}),
+	}
+	for def, typ := range checks {
+		props := schema.Defs[def].Properties
+		for i := 0; i < typ.NumField(); i++ {
+			tag := typ.Field(i).Tag.Get("json")
+			name, options, _ := strings.Cut(tag, ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if _, ok := props[name]; !ok {
+				t.Errorf("schema $defs/%s is missing property %q", def, name)
+			}
+			// Fields without omitempty are always emitted, so they are required
+			omitted := slices.Contains(strings.Split(options, ","), "omitempty")
+			if required := slices.Contains(schema.Defs[def].Required, name); required == omitted {
+				t.Errorf("schema $defs/%s: %q required = %t, but omitempty = %t", def, name, required, omitted)
+			}
+		}
+	}
+}
+
+func TestFieldSelection(t *testing.T) {
+	if err := SetFields([]string{"valid", "name"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer SetFields(nil)
+
+	result := &vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true}
+
+	out, err := NewJSONFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var envelope struct {
+		Result map[string]any `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(out), &envelope); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(envelope.Result) != 2 || envelope.Result["valid"] != true || envelope.Result["name"] != "" {
+		t.Errorf("unexpected selected result: %v", envelope.Result)
+	}
+
+	out, err = NewPlainFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "Status: Valid\n" {
+		t.Errorf("unexpected plain output: %q", out)
+	}
+
+	if err := SetFields([]string{"bogus"}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestFieldSelectionEmptyObjects(t *testing.T) {
+	if err := SetFields([]string{"valid", "requestIdentifier", "timing", "extensions"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer SetFields(nil)
+
+	result := &vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true}
+	out, err := NewJSONFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{`"requestIdentifier": ""`, `"timing": null`, `"extensions": null`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in the selected result:\n%s", want, out)
+		}
+	}
+
+	// Set objects are kept as they are
+	result.Timing = &vies.RequestTiming{RequestMs: 12}
+	result.Extensions = map[string]any{"registry": "HRB 1"}
+	object, err := resultObject(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	timing, ok := object["timing"].(map[string]any)
+	if !ok || timing["requestMs"] != float64(12) || object["extensions"].(map[string]any)["registry"] != "HRB 1" {
+		t.Errorf("unexpected selected objects: %v", object)
+	}
+}
+
+func TestQueryResult(t *testing.T) {
+	if err := SetQuery(`.valid, .countryCode + .vatNumber`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer SetQuery("")
+
+	out, err := QueryResult(&vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "true\nDE266201128\n" {
+		t.Errorf("unexpected query output: %q", out)
+	}
+
+	if err := SetQuery(".["); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
+
+func TestDeterministicDate(t *testing.T) {
+	SetDeterministic(true)
+	defer SetDeterministic(false)
+
+	result := &vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true, RequestDate: time.Now(), RawRequestDate: "2025-01-09+01:00"}
+	got, err := NewJSONFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, `"requestDate": "1970-01-01T00:00:00Z"`) || !strings.Contains(got, `"rawRequestDate": "1970-01-01Z"`) {
+		t.Errorf("Format() = %s, want the placeholder dates", got)
+	}
+	if result.RequestDate.Equal(DeterministicDate) {
+		t.Error("Format() modified the caller's result")
+	}
+}
+
+func TestFormatWrappedError(t *testing.T) {
+	serviceErr := &vies.ServiceError{Code: vies.CodeSOAPFault, Message: "SOAP fault: MS_UNAVAILABLE", VATNumber: "DE266201128", FaultCode: "MS_UNAVAILABLE", HTTPStatus: 500}
+	err := fmt.Errorf("checking row 3: %w", serviceErr)
+
+	got, fErr := NewJSONFormatter().FormatError(err)
+	if fErr != nil {
+		t.Fatalf("unexpected error: %v", fErr)
+	}
+	var envelope struct{ Error ErrorResponse }
+	if err := json.Unmarshal([]byte(got), &envelope); err != nil {
+		t.Fatalf("invalid JSON %q: %v", got, err)
+	}
+	want := ErrorResponse{Message: "checking row 3: SOAP fault: MS_UNAVAILABLE", Code: vies.CodeSOAPFault, VATNumber: "DE266201128", HTTPStatus: 500, FaultCode: "MS_UNAVAILABLE"}
+	if !reflect.DeepEqual(envelope.Error, want) {
+		t.Errorf("JSON error = %+v, want %+v", envelope.Error, want)
+	}
+
+	got, _ = NewPlainFormatter().FormatError(err)
+	for _, line := range []string{"Error: checking row 3: SOAP fault: MS_UNAVAILABLE\n", "VAT Number: DE266201128\n", "Fault Code: MS_UNAVAILABLE\n", "HTTP Status: 500\n"} {
+		if !strings.Contains(got, line) {
+			t.Errorf("plain error = %q, missing %q", got, line)
+		}
+	}
+}
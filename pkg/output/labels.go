@@ -0,0 +1,86 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultLabels are the labels and status words of plain output, by name.
+// They are the English wording, used when the locale does not have a label.
+var defaultLabels = map[string]string{
+	"vatNumber":           "VAT Number",
+	"country":             "Country",
+	"status":              "Status",
+	"valid":               "Valid",
+	"invalid":             "Invalid",
+	"company":             "Company",
+	"address":             "Address",
+	"normalizedVatNumber": "Normalized VAT Number",
+	"canonicalVatNumber":  "Canonical VAT Number",
+	"addressCountryCode":  "Address Country Code",
+	"addressCountry":      "Address Country",
+	"rawRequestDate":      "Raw Request Date",
+	"assumedCountry":      "Assumed Country",
+	"noCountryPrefix":     "no country prefix in the input",
+	"duration":            "Duration",
+	"timing":              "Timing",
+	"consultationNumber":  "Consultation Number",
+	"testScenario":        "Test Scenario",
+	"error":               "Error",
+	"expectedFormat":      "Expected Format",
+	"hint":                "Hint",
+	"didYouMean":          "Did you mean",
+	"faultCode":           "Fault Code",
+	"faultDetail":         "Fault Detail",
+	"httpStatus":          "HTTP Status",
+	"responseBody":        "Response Body",
+	"timeoutHint":         "Try increasing timeout with --timeout flag",
+	"unavailableHint":     "Please retry later or check VIES service status",
+}
+
+// labelOverrides replace default labels, e.g. to match the wording of local
+// accounting documentation
+var labelOverrides map[string]string
+
+// LabelNames returns the names of the plain output labels, sorted
+func LabelNames() []string {
+	names := make([]string, 0, len(defaultLabels))
+	for name := range defaultLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLabels replaces labels of plain output, keyed by the names returned by
+// LabelNames, e.g. {"valid": "Gültig", "status": "Status der USt-IdNr."}.
+// Labels not in overrides keep the wording of the configured locale; unknown
+// names are rejected.
+// JSON and the other structured formats are not affected.
+func SetLabels(overrides map[string]string) error {
+	for name, text := range overrides {
+		if _, ok := defaultLabels[name]; !ok {
+			return fmt.Errorf("unknown label: %s (supported: %s)", name, strings.Join(LabelNames(), ", "))
+		}
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("empty label: %s", name)
+		}
+	}
+	labelOverrides = overrides
+	return nil
+}
+
+// label returns the text of a plain output label: the override if set,
+// else the configured locale's wording, else the English default
+func label(name string) string {
+	if text, ok := labelOverrides[name]; ok {
+		return text
+	}
+	if data := lookupLocale(locale); data != nil {
+		if text, ok := data.Labels[name]; ok {
+			return text
+		}
+	}
+	return defaultLabels[name]
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestPlainLabels(t *testing.T) {
+	if err := SetLabels(map[string]string{"status": "Ergebnis", "valid": "gültig", "error": "Fehler"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLabels(nil)
+
+	out, err := NewPlainFormatter().Format(&vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true, Name: "Example GmbH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Ergebnis: gültig\n") || !strings.Contains(out, "Company: Example GmbH\n") {
+		t.Errorf("labels not applied or defaults lost:\n%s", out)
+	}
+	out, _ = NewPlainFormatter().FormatError(errors.New("boom"))
+	if out != "Fehler: boom\n" {
+		t.Errorf("FormatError = %q", out)
+	}
+
+	if err := SetLabels(map[string]string{"vailid": "ok"}); err == nil {
+		t.Error("expected an error for an unknown label")
+	}
+	if err := SetLabels(map[string]string{"valid": " "}); err == nil {
+		t.Error("expected an error for an empty label")
+	}
+}
+
+func TestLocaleLabels(t *testing.T) {
+	for _, loc := range SupportedLocales() {
+		for _, name := range LabelNames() {
+			if strings.TrimSpace(lookupLocale(loc).Labels[name]) == "" {
+				t.Errorf("locale %s has no label %s", loc, name)
+			}
+		}
+	}
+	for name, text := range defaultLabels {
+		if got := lookupLocale(defaultLocale).Labels[name]; got != text {
+			t.Errorf("en label %s = %q, want the default %q", name, got, text)
+		}
+	}
+
+	if err := SetLocale("de"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLocale(defaultLocale)
+	if err := SetLabels(map[string]string{"status": "Ergebnis"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLabels(nil)
+
+	out, err := NewPlainFormatter().Format(&vies.CheckVatResult{CountryCode: "IT", VatNumber: "12345670017", Valid: true, AssumedCountryCode: "IT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"USt-IdNr.: IT12345670017\n", "Angenommenes Land: IT (kein Länderpräfix in der Eingabe)\n", "Ergebnis: Gültig\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("plain output misses %q:\n%s", line, out)
+		}
+	}
+	errorLines := map[error]string{
+		&vies.ValidationError{Code: vies.CodeInvalidFormat, Message: "invalid format", VATNumber: "DE123"}: "Erwartetes Format: DE + 9 Ziffern\n",
+		&vies.ServiceError{Code: vies.CodeNetworkTimeout, Message: "timeout"}:                              "Erhöhen Sie das Zeitlimit mit --timeout\n",
+	}
+	for err, line := range errorLines {
+		out, ferr := NewPlainFormatter().FormatError(err)
+		if ferr != nil {
+			t.Fatal(ferr)
+		}
+		if !strings.Contains(out, line) {
+			t.Errorf("plain error misses %q:\n%s", line, out)
+		}
+	}
+}
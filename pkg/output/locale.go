@@ -0,0 +1,227 @@
+package output
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// localeFiles holds the embedded per-locale translation data
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// defaultLocale is used when no locale is configured or a lookup misses
+const defaultLocale = "en"
+
+// locale selects the language used for localized output (the verbose date
+// sentence, country names and plain output labels)
+var locale = defaultLocale
+
+// localeData represents the translations available for one locale
+type localeData struct {
+	Countries map[string]string `json:"countries"`
+	Months    []string          `json:"months"`   // January..December
+	Weekdays  []string          `json:"weekdays"` // Sunday..Saturday
+	Labels    map[string]string `json:"labels"`   // plain output labels by name
+	// FormatWords translates the words of the VAT number format
+	// descriptions, e.g. "digits", keyed by the English word or phrase
+	FormatWords map[string]string `json:"formatWords"`
+
+	VerboseDate verboseDate `json:"verboseDate"`
+}
+
+// verboseDate is the gce-verbose sentence of a locale
+type verboseDate struct {
+	// Sentences by calendar, with {weekday}, {month}, {day}, {ordinal},
+	// {year} and {era} placeholders
+	Sentences map[string]string `json:"sentences"`
+	// Ordinal follows the day number; Ordinals overrides it for single
+	// days, e.g. "1": "er" in French
+	Ordinal  string            `json:"ordinal"`
+	Ordinals map[string]string `json:"ordinals"`
+	// DateMonths replaces Months where a date needs another grammatical
+	// case, e.g. the Polish genitive
+	DateMonths []string `json:"dateMonths"`
+	// Names translates the month names of the calendar package, keyed by
+	// their English transliteration
+	Names map[string]string `json:"names"`
+}
+
+var (
+	localesOnce sync.Once
+	locales     map[string]*localeData
+)
+
+// SetLocale configures the language used for localized output (e.g. "de", "fr-BE").
+// Locales without translations are rejected and keep the current locale.
+func SetLocale(loc string) error {
+	if loc == "" {
+		return nil
+	}
+	normalized := normalizeLocale(loc)
+	if lookupLocale(normalized) == nil {
+		return fmt.Errorf("unknown locale: %s (supported: %s)", loc, strings.Join(SupportedLocales(), ", "))
+	}
+	locale = normalized
+	return nil
+}
+
+// formatWordPattern matches the words of the English VAT number format
+// descriptions, e.g. "digits" in "DE + 9 digits"
+var formatWordPattern = regexp.MustCompile(`[A-Za-z]{2,}(?: [a-z]+)*`)
+
+// formatDescription translates a VAT number format description of the
+// country catalog into the configured locale. Phrases are looked up as a
+// whole before their single words; untranslated words, such as the country
+// prefix, are kept.
+func formatDescription(description string) string {
+	data := lookupLocale(locale)
+	if data == nil || len(data.FormatWords) == 0 {
+		return description
+	}
+	return formatWordPattern.ReplaceAllStringFunc(description, func(phrase string) string {
+		if text, ok := data.FormatWords[phrase]; ok {
+			return text
+		}
+		words := strings.Split(phrase, " ")
+		for i, word := range words {
+			if text, ok := data.FormatWords[word]; ok {
+				words[i] = text
+			}
+		}
+		return strings.Join(words, " ")
+	})
+}
+
+// SupportedLocales returns the locales with embedded translation data, sorted
+func SupportedLocales() []string {
+	loadLocales()
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CountryName returns the name of a member state in the configured locale,
+// falling back to English and finally to the country code itself
+func CountryName(countryCode string) string {
+	countryCode = strings.ToUpper(countryCode)
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	if data := lookupLocale(locale); data != nil {
+		if name, ok := data.Countries[countryCode]; ok {
+			return name
+		}
+	}
+	if data := lookupLocale(defaultLocale); data != nil {
+		if name, ok := data.Countries[countryCode]; ok {
+			return name
+		}
+	}
+	if info, err := vies.GetCountryInfo(countryCode); err == nil {
+		return info.Name
+	}
+	return countryCode
+}
+
+// localizedMonth returns the name of month m (1-12) in the configured locale
+func localizedMonth(m int) string {
+	if data := lookupLocale(locale); data != nil && len(data.Months) == 12 && m >= 1 && m <= 12 {
+		return data.Months[m-1]
+	}
+	return monthName(m)
+}
+
+// verboseSentence returns the gce-verbose sentence for a calendar in the
+// configured locale and the data it comes from, falling back to English
+func verboseSentence(calendarName string) (string, *localeData) {
+	for _, loc := range []string{locale, defaultLocale} {
+		if data := lookupLocale(loc); data != nil {
+			if sentence, ok := data.VerboseDate.Sentences[calendarName]; ok {
+				return sentence, data
+			}
+		}
+	}
+	return "", nil
+}
+
+// dateMonth returns the name of month m (1-12) as used in a date
+func (d *localeData) dateMonth(m int) string {
+	if len(d.VerboseDate.DateMonths) == 12 && m >= 1 && m <= 12 {
+		return d.VerboseDate.DateMonths[m-1]
+	}
+	return localizedMonth(m)
+}
+
+// ordinal returns the suffix following day in a date
+func (d *localeData) ordinal(day int) string {
+	if suffix, ok := d.VerboseDate.Ordinals[strconv.Itoa(day)]; ok {
+		return suffix
+	}
+	return d.VerboseDate.Ordinal
+}
+
+// calendarName translates a month name of the calendar package
+func (d *localeData) calendarName(name string) string {
+	if translated, ok := d.VerboseDate.Names[name]; ok {
+		return translated
+	}
+	return name
+}
+
+// localizedWeekday returns the name of the weekday in the configured locale
+func localizedWeekday(d time.Weekday) string {
+	if data := lookupLocale(locale); data != nil && len(data.Weekdays) == 7 {
+		return data.Weekdays[d]
+	}
+	return d.String()
+}
+
+// normalizeLocale reduces a locale tag such as "de_AT.UTF-8" to its language ("de")
+func normalizeLocale(loc string) string {
+	loc = strings.ToLower(loc)
+	if i := strings.IndexAny(loc, "_-."); i > 0 {
+		loc = loc[:i]
+	}
+	return loc
+}
+
+// lookupLocale returns the translation data for a locale, or nil if unknown
+func lookupLocale(loc string) *localeData {
+	loadLocales()
+	return locales[loc]
+}
+
+// loadLocales parses the embedded locale files once
+func loadLocales() {
+	localesOnce.Do(func() {
+		locales = make(map[string]*localeData)
+		entries, err := localeFiles.ReadDir("locales")
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			raw, err := localeFiles.ReadFile("locales/" + entry.Name())
+			if err != nil {
+				continue
+			}
+			var data localeData
+			if err := json.Unmarshal(raw, &data); err != nil {
+				continue
+			}
+			locales[strings.TrimSuffix(entry.Name(), ".json")] = &data
+		}
+	})
+}
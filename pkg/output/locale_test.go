@@ -0,0 +1,62 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetLocale(t *testing.T) {
+	defer SetLocale(defaultLocale)
+	tests := []struct {
+		input, want string
+	}{
+		{"de", "de"},
+		{"fr-BE", "fr"},
+		{"pt_BR.UTF-8", "pt"},
+		{"NL", "nl"},
+		{"", "nl"}, // empty keeps the current locale
+	}
+	for _, tt := range tests {
+		if err := SetLocale(tt.input); err != nil {
+			t.Fatalf("SetLocale(%q): %v", tt.input, err)
+		}
+		if locale != tt.want {
+			t.Errorf("SetLocale(%q) set %q, want %q", tt.input, locale, tt.want)
+		}
+	}
+
+	err := SetLocale("xx-XX")
+	if err == nil {
+		t.Fatal("SetLocale accepted an unknown locale")
+	}
+	for _, want := range []string{"unknown locale: xx-XX", strings.Join(SupportedLocales(), ", ")} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q misses %q", err, want)
+		}
+	}
+	if locale != "nl" {
+		t.Errorf("unknown locale changed the locale to %q", locale)
+	}
+}
+
+func TestCountryName(t *testing.T) {
+	defer SetLocale(defaultLocale)
+	tests := []struct {
+		locale, code, want string
+	}{
+		{"en", "DE", "Germany"},
+		{"en", "de", "Germany"},
+		{"de", "DE", "Deutschland"},
+		{"fr", "EL", "Grèce"},
+		{"fr", "GR", "Grèce"},
+		{"de", "XX", "XX"},
+	}
+	for _, tt := range tests {
+		if err := SetLocale(tt.locale); err != nil {
+			t.Fatal(err)
+		}
+		if got := CountryName(tt.code); got != tt.want {
+			t.Errorf("CountryName(%q) in %s = %q, want %q", tt.code, tt.locale, got, tt.want)
+		}
+	}
+}
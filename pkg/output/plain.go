@@ -0,0 +1,230 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// PlainFormatter formats output as plain text
+type PlainFormatter struct{}
+
+func init() {
+	Register("plain", NewPlainFormatter())
+}
+
+// NewPlainFormatter creates a new plain text formatter
+func NewPlainFormatter() *PlainFormatter {
+	return &PlainFormatter{}
+}
+
+// plainLine renders the plain text line, or lines, of one result field
+type plainLine struct {
+	field string
+	// explicit lines are only shown by default when their field is selected
+	// with SetFields
+	explicit bool
+	render   func(b *strings.Builder, result *vies.CheckVatResult)
+}
+
+// plainLines are the lines of plain output in their default order
+var plainLines = []plainLine{
+	{field: "vatNumber", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s%s\n", label("vatNumber"), result.CountryCode, result.VatNumber)
+	}},
+	// Country (rendered in the configured locale)
+	{field: "countryCode", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("country"), CountryName(result.CountryCode))
+	}},
+	// Notice that the input had no prefix and the default country was used
+	{field: "assumedCountryCode", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if result.AssumedCountryCode != "" {
+			fmt.Fprintf(b, "%s: %s (%s)\n", label("assumedCountry"), result.AssumedCountryCode, label("noCountryPrefix"))
+		}
+	}},
+	{field: "valid", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		status := label("invalid")
+		if result.Valid {
+			status = label("valid")
+		}
+		fmt.Fprintf(b, "%s: %s\n", label("status"), status)
+	}},
+	// Company information (only if valid and available)
+	{field: "name", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if result.Valid && result.Name != "" {
+			fmt.Fprintf(b, "%s: %s\n", label("company"), result.Name)
+		}
+	}},
+	{field: "address", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if result.Valid && result.Address != "" {
+			fmt.Fprintf(b, "%s: %s\n", label("address"), result.Address)
+		}
+	}},
+	// Input and canonical forms are only shown when selected explicitly
+	{field: "normalizedVatNumber", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("normalizedVatNumber"), result.NormalizedVATNumber)
+	}},
+	{field: "canonicalVatNumber", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("canonicalVatNumber"), result.CanonicalVATNumber)
+	}},
+	{field: "addressCountryCode", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("addressCountryCode"), result.AddressCountryCode)
+	}},
+	{field: "addressCountry", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("addressCountry"), result.AddressCountry)
+	}},
+	{field: "rawRequestDate", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %s\n", label("rawRequestDate"), result.RawRequestDate)
+	}},
+	{field: "durationMs", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s: %d ms\n", label("duration"), result.DurationMs)
+	}},
+	{field: "timing", explicit: true, render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if t := result.Timing; t != nil {
+			fmt.Fprintf(b, "%s: wait %d ms, request %d ms, parse %d ms\n", label("timing"), t.WaitMs, t.RequestMs, t.ParseMs)
+		}
+	}},
+	// Consultation number (only issued for checks made on behalf of a requester)
+	{field: "requestIdentifier", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if result.RequestIdentifier != "" {
+			fmt.Fprintf(b, "%s: %s\n", label("consultationNumber"), result.RequestIdentifier)
+		}
+	}},
+	// Scripted outcome of a VIES test service number
+	{field: "testScenario", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		if result.TestScenario != "" {
+			fmt.Fprintf(b, "%s: %s\n", label("testScenario"), result.TestScenario)
+		}
+	}},
+	// Enrichment data, in key order
+	{field: "extensions", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		for _, key := range sortedKeys(result.Extensions) {
+			fmt.Fprintf(b, "%s: %s\n", key, extensionText(result.Extensions[key]))
+		}
+	}},
+	// Request date (rendered per configured style and calendar)
+	{field: "requestDate", render: func(b *strings.Builder, result *vies.CheckVatResult) {
+		fmt.Fprintf(b, "%s\n", FormatRequestDate(result.RequestDate))
+	}},
+}
+
+// plainLayout orders plain output and leaves out unlisted fields; nil means
+// the default layout
+var plainLayout []plainLine
+
+// SetPlainLayout sets the fields shown in plain output and their order,
+// using the JSON field names (e.g. "vatNumber", "valid", "name",
+// "requestDate"). Fields not listed are left out; listed fields that are
+// only shown on request by default, such as canonicalVatNumber, are shown.
+// An empty list restores the default layout. A field selection made with
+// SetFields further restricts the output.
+func SetPlainLayout(fields []string) error {
+	if len(fields) == 0 {
+		plainLayout = nil
+		return nil
+	}
+	layout := make([]plainLine, 0, len(fields))
+	for _, field := range fields {
+		i := slices.IndexFunc(plainLines, func(line plainLine) bool { return line.field == field })
+		if i < 0 {
+			return fmt.Errorf("unknown field in plain layout: %s (supported: %s)", field, strings.Join(resultFieldNames, ", "))
+		}
+		line := plainLines[i]
+		line.explicit = false
+		layout = append(layout, line)
+	}
+	plainLayout = layout
+	return nil
+}
+
+// Format formats a validation result as plain text
+func (f *PlainFormatter) Format(result *vies.CheckVatResult) (string, error) {
+	result = prepareResult(result)
+	var b strings.Builder
+
+	lines := plainLines
+	if plainLayout != nil {
+		lines = plainLayout
+	}
+	for _, line := range lines {
+		if line.explicit && !selectedFields[line.field] || !showField(line.field) {
+			continue
+		}
+		line.render(&b, result)
+	}
+	return b.String(), nil
+}
+
+// FormatError formats an error as plain text
+func (f *PlainFormatter) FormatError(err error) (string, error) {
+	var b strings.Builder
+
+	// Errors may be wrapped, e.g. by a Checker adding context
+	var validationErr *vies.ValidationError
+	var serviceErr *vies.ServiceError
+	switch {
+	case errors.As(err, &validationErr):
+		fmt.Fprintf(&b, "%s: %s\n", label("error"), err.Error())
+		if validationErr.VATNumber != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("vatNumber"), validationErr.VATNumber)
+		}
+
+		// Add format hint for validation errors
+		if validationErr.Code == vies.CodeInvalidFormat {
+			// Try to get country info for format hint
+			if len(validationErr.VATNumber) >= 2 {
+				countryCode := validationErr.VATNumber[:2]
+				if countryInfo, err := vies.GetCountryInfo(countryCode); err == nil {
+					fmt.Fprintf(&b, "%s: %s\n", label("expectedFormat"), formatDescription(countryInfo.Description))
+				}
+			}
+		}
+		if validationErr.Hint != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("hint"), validationErr.Hint)
+		}
+		if len(validationErr.Suggestions) > 0 {
+			fmt.Fprintf(&b, "%s: %s?\n", label("didYouMean"), strings.Join(validationErr.Suggestions, ", "))
+		}
+
+	case errors.As(err, &serviceErr):
+		fmt.Fprintf(&b, "%s: %s\n", label("error"), err.Error())
+		if serviceErr.VATNumber != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("vatNumber"), serviceErr.VATNumber)
+		}
+		if serviceErr.FaultCode != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("faultCode"), serviceErr.FaultCode)
+		}
+		for _, field := range faultDetailForOutput(serviceErr) {
+			if field.Path == "" {
+				fmt.Fprintf(&b, "%s: %s\n", label("faultDetail"), field.Value)
+			} else {
+				fmt.Fprintf(&b, "%s: %s = %s\n", label("faultDetail"), field.Path, field.Value)
+			}
+		}
+		if serviceErr.HTTPStatus != 0 {
+			fmt.Fprintf(&b, "%s: %d\n", label("httpStatus"), serviceErr.HTTPStatus)
+		}
+		if body := rawBodyForOutput(serviceErr); body != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("responseBody"), body)
+		}
+		if serviceErr.TestScenario != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label("testScenario"), serviceErr.TestScenario)
+		}
+
+		// Add specific suggestions for service errors
+		switch serviceErr.Code {
+		case vies.CodeNetworkTimeout:
+			fmt.Fprintf(&b, "%s\n", label("timeoutHint"))
+		case vies.CodeServiceUnavailable:
+			fmt.Fprintf(&b, "%s\n", label("unavailableHint"))
+		}
+
+	default:
+		fmt.Fprintf(&b, "%s: %s\n", label("error"), err.Error())
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"slices"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestPlainLinesCoverFields(t *testing.T) {
+	for _, field := range resultFieldNames {
+		if !slices.ContainsFunc(plainLines, func(line plainLine) bool { return line.field == field }) {
+			t.Errorf("plain output has no line for result field %q", field)
+		}
+	}
+}
+
+func TestPlainLayout(t *testing.T) {
+	if err := SetPlainLayout([]string{"valid", "canonicalVatNumber", "vatNumber"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetPlainLayout(nil)
+
+	result := &vies.CheckVatResult{CountryCode: "DE", VatNumber: "266201128", Valid: true, Name: "Example GmbH", CanonicalVATNumber: "DE266201128"}
+	out, err := NewPlainFormatter().Format(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Status: Valid\nCanonical VAT Number: DE266201128\nVAT Number: DE266201128\n"; out != want {
+		t.Errorf("Format() = %q, want %q", out, want)
+	}
+
+	// A field selection still applies on top of the layout
+	if err := SetFields([]string{"vatNumber"}); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = NewPlainFormatter().Format(result)
+	SetFields(nil)
+	if want := "VAT Number: DE266201128\n"; out != want {
+		t.Errorf("Format() with fields = %q, want %q", out, want)
+	}
+
+	if err := SetPlainLayout([]string{"valid", "company"}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
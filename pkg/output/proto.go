@@ -0,0 +1,164 @@
+package output
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// protoDefinition holds the messages written by the proto formatter
+//
+//go:embed viesquery.proto
+var protoDefinition []byte
+
+// ProtoDefinition returns the .proto file describing the proto output
+func ProtoDefinition() []byte {
+	return append([]byte(nil), protoDefinition...)
+}
+
+// ProtoFormatter writes length-delimited protobuf Envelope messages as
+// defined in viesquery.proto. The encoding is done by hand: the messages
+// only hold strings, bools and ints, which does not justify a protobuf
+// runtime dependency.
+type ProtoFormatter struct{}
+
+func init() {
+	Register("proto", NewProtoFormatter())
+}
+
+// NewProtoFormatter creates a new protobuf formatter
+func NewProtoFormatter() *ProtoFormatter {
+	return &ProtoFormatter{}
+}
+
+// Format encodes a validation result as a length-delimited Envelope.
+// Fields deselected with SetFields are left out.
+func (f *ProtoFormatter) Format(result *vies.CheckVatResult) (string, error) {
+	result = prepareResult(result)
+
+	var msg protoMessage
+	stringField := func(num int, name, value string) {
+		if showField(name) {
+			msg.string(num, value)
+		}
+	}
+	stringField(1, "countryCode", result.CountryCode)
+	stringField(2, "vatNumber", result.VatNumber)
+	if showField("requestDate") && !result.RequestDate.IsZero() {
+		msg.string(3, result.RequestDate.Format(time.RFC3339Nano))
+	}
+	if showField("valid") {
+		msg.bool(4, result.Valid)
+	}
+	stringField(5, "name", result.Name)
+	stringField(6, "address", result.Address)
+	stringField(7, "normalizedVatNumber", result.NormalizedVATNumber)
+	stringField(8, "canonicalVatNumber", result.CanonicalVATNumber)
+	stringField(9, "requestIdentifier", result.RequestIdentifier)
+	stringField(10, "testScenario", result.TestScenario)
+	stringField(11, "addressCountryCode", result.AddressCountryCode)
+	stringField(12, "addressCountry", result.AddressCountry)
+	stringField(14, "rawRequestDate", result.RawRequestDate)
+	stringField(15, "assumedCountryCode", result.AssumedCountryCode)
+	if showField("durationMs") {
+		msg.varint(16, uint64(result.DurationMs))
+	}
+	if showField("timing") && result.Timing != nil {
+		var timing protoMessage
+		timing.varint(1, uint64(result.Timing.WaitMs))
+		timing.varint(2, uint64(result.Timing.RequestMs))
+		timing.varint(3, uint64(result.Timing.ParseMs))
+		msg.bytes(17, timing)
+	}
+	if showField("extensions") {
+		// Map entries are messages of key (1) and value (2)
+		for _, key := range sortedKeys(result.Extensions) {
+			var entry protoMessage
+			entry.string(1, key)
+			entry.string(2, extensionText(result.Extensions[key]))
+			msg.bytes(13, entry)
+		}
+	}
+
+	return protoEnvelope(2, msg), nil
+}
+
+// FormatError encodes an error as a length-delimited Envelope
+func (f *ProtoFormatter) FormatError(err error) (string, error) {
+	e := newErrorResponse(err)
+
+	var msg protoMessage
+	msg.string(1, e.Message)
+	msg.string(2, e.Code)
+	msg.string(3, e.VATNumber)
+	msg.varint(4, uint64(e.HTTPStatus))
+	msg.string(5, e.FaultCode)
+	msg.string(6, e.RawBody)
+	for _, suggestion := range e.Suggestions {
+		msg.bytes(7, []byte(suggestion))
+	}
+	msg.string(8, e.Hint)
+	msg.string(9, e.TestScenario)
+	for _, field := range e.FaultDetail {
+		var entry protoMessage
+		entry.string(1, field.Path)
+		entry.string(2, field.Value)
+		msg.bytes(10, entry)
+	}
+
+	return protoEnvelope(3, msg), nil
+}
+
+// protoEnvelope wraps a payload message in an Envelope and prefixes it with
+// its length
+func protoEnvelope(payloadField int, payload protoMessage) string {
+	var envelope protoMessage
+	envelope.string(1, SchemaVersion)
+	envelope.bytes(payloadField, payload)
+
+	out := binary.AppendUvarint(nil, uint64(len(envelope)))
+	return string(append(out, envelope...))
+}
+
+// protoMessage is an encoded protobuf message. Like proto3, the helpers
+// skip fields holding their zero value.
+type protoMessage []byte
+
+// Protobuf wire types
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (m *protoMessage) tag(num, wireType int) {
+	*m = binary.AppendUvarint(*m, uint64(num)<<3|uint64(wireType))
+}
+
+func (m *protoMessage) varint(num int, v uint64) {
+	if v == 0 {
+		return
+	}
+	m.tag(num, wireVarint)
+	*m = binary.AppendUvarint(*m, v)
+}
+
+func (m *protoMessage) bool(num int, v bool) {
+	if v {
+		m.varint(num, 1)
+	}
+}
+
+func (m *protoMessage) string(num int, v string) {
+	if v != "" {
+		m.bytes(num, []byte(v))
+	}
+}
+
+// bytes writes a length-delimited field, even when empty
+func (m *protoMessage) bytes(num int, v []byte) {
+	m.tag(num, wireBytes)
+	*m = binary.AppendUvarint(*m, uint64(len(v)))
+	*m = append(*m, v...)
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// TestProtoDefinitionCoversFields guards against the published .proto
+// drifting from the JSON contract
+func TestProtoDefinitionCoversFields(t *testing.T) {
+	upper := regexp.MustCompile(`[A-Z]`)
+	checks := map[string]reflect.Type{
+		"CheckVatResult": reflect.TypeOf(vies.CheckVatResult{}),
+		"Error":          reflect.TypeOf(ErrorResponse{}),
+	}
+	for message, typ := range checks {
+		start := bytes.Index(protoDefinition, []byte("message "+message+" {"))
+		if start < 0 {
+			t.Fatalf("viesquery.proto has no message %s", message)
+		}
+		body := string(protoDefinition[start:])
+		body = body[:strings.Index(body, "}")]
+		for _, name := range jsonFieldNames(typ) {
+			field := strings.ToLower(upper.ReplaceAllString(name, "_$0"))
+			if !strings.Contains(body, " "+field+" = ") {
+				t.Errorf("message %s is missing field %s (JSON %q)", message, field, name)
+			}
+		}
+	}
+}
+
+func TestProtoFormat(t *testing.T) {
+	result := &vies.CheckVatResult{
+		CountryCode: "DE",
+		VatNumber:   "266201128",
+		RequestDate: time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC),
+		Valid:       true,
+	}
+	got, err := NewProtoFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payload := "\x0a\x02DE" + "\x12\x09266201128" + "\x1a\x142025-01-09T00:00:00Z" + "\x20\x01"
+	envelope := "\x0a\x011" + "\x12" + string(rune(len(payload))) + payload
+	want := string(rune(len(envelope))) + envelope
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestProtoFormatExtensions(t *testing.T) {
+	result := &vies.CheckVatResult{
+		Extensions: map[string]any{"register": "HRB 12345", "employees": 42},
+	}
+	got, err := NewProtoFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	employees := "\x0a\x09employees" + "\x12\x0242"
+	register := "\x0a\x08register" + "\x12\x09HRB 12345"
+	want := "\x6a" + string(rune(len(employees))) + employees + "\x6a" + string(rune(len(register))) + register
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("Format() = %q, want extension entries %q in key order", got, want)
+	}
+}
+
+func TestProtoFormatError(t *testing.T) {
+	err := &vies.ValidationError{Message: "bad", Code: "INVALID_FORMAT", Suggestions: []string{"ATU12345678"}}
+	got, fErr := NewProtoFormatter().FormatError(err)
+	if fErr != nil {
+		t.Fatalf("unexpected error: %v", fErr)
+	}
+	if !strings.Contains(got, "\x12\x0eINVALID_FORMAT") || !strings.Contains(got, "\x3a\x0bATU12345678") {
+		t.Errorf("FormatError() = %q, missing code or suggestion", got)
+	}
+	if !strings.HasPrefix(got[1:], "\x0a\x011\x1a") {
+		t.Errorf("FormatError() = %q, want an Envelope with the error payload", got)
+	}
+}
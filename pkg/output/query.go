@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// query is applied to results instead of a formatter when set
+var query *gojq.Code
+
+// SetQuery compiles a jq expression (e.g. ".valid" or ".name // empty") that
+// QueryResult applies to the JSON result object. An empty expression clears
+// the query.
+func SetQuery(expr string) error {
+	if expr == "" {
+		query = nil
+		return nil
+	}
+	parsed, err := gojq.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	code, err := gojq.Compile(parsed)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	query = code
+	return nil
+}
+
+// HasQuery reports whether a query has been set with SetQuery
+func HasQuery() bool {
+	return query != nil
+}
+
+// QueryResult runs the query against the result object, as it appears in
+// the JSON output (after redaction and field selection). Each emitted value
+// is written on its own line: strings raw, other values as JSON, like jq -r.
+func QueryResult(result *vies.CheckVatResult) (string, error) {
+	if query == nil {
+		return "", errors.New("no query set")
+	}
+	input, err := resultObject(prepareResult(result))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	iter := query.Run(input)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := value.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				break
+			}
+			return "", fmt.Errorf("query failed: %w", err)
+		}
+		if s, ok := value.(string); ok {
+			b.WriteString(s)
+		} else {
+			data, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return "", err
+			}
+			b.Write(data)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,9 @@
+package vies
+
+// RequestBudget limits the number of requests sent to VIES, protecting
+// callers from being blocked by the EC for excessive use
+type RequestBudget interface {
+	// Take reserves one request. It returns an error wrapping
+	// ErrBudgetExceeded when the budget is used up.
+	Take() error
+}
@@ -0,0 +1,148 @@
+package vies
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+)
+
+// Cache stores VIES results between calls. Implementations must be safe for
+// concurrent use. Values are opaque JSON documents, so any key-value store
+// (DynamoDB, Redis, ristretto, ...) can back a Cache.
+type Cache interface {
+	// Get returns the value stored under key, and false if there is none or
+	// it has expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl; a ttl of 0 means no expiry
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, if present
+	Delete(ctx context.Context, key string) error
+}
+
+// cacheKey identifies the result for a VAT number as sent to an endpoint,
+// so results of the acceptance service or a per-call endpoint never answer
+// checks against another one. The endpoint is hashed to keep keys short.
+func cacheKey(endpoint string, testMode bool, countryCode, number string) string {
+	h := fnv.New64a()
+	io.WriteString(h, endpoint)
+	scope := fmt.Sprintf("%016x", h.Sum64())
+	if testMode {
+		scope = "test:" + scope
+	}
+	return "viesquery:v3:" + scope + ":" + countryCode + number
+}
+
+// cacheEntry is the document stored under a cache key: a result, or the
+// fault VIES returned instead
+type cacheEntry struct {
+	Result *CheckVatResult `json:"result,omitempty"`
+	Error  *cachedError    `json:"error,omitempty"`
+}
+
+// cachedError is the part of a ServiceError replayed from the cache
+type cachedError struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	HTTPStatus int    `json:"httpStatus,omitempty"`
+	FaultCode  string `json:"faultCode,omitempty"`
+}
+
+// serviceError recreates the cached error
+func (e *cachedError) serviceError() *ServiceError {
+	return &ServiceError{
+		Code:       e.Code,
+		Message:    e.Message,
+		HTTPStatus: e.HTTPStatus,
+		FaultCode:  e.FaultCode,
+	}
+}
+
+// CacheStats are counters for tuning cache TTLs
+type CacheStats struct {
+	Hits      uint64 // lookups answered from the cache
+	Misses    uint64 // lookups of absent or expired keys
+	Evictions uint64 // entries dropped because they expired
+	Size      int    // entries currently held, including expired ones not yet dropped
+}
+
+// HitRate returns the share of lookups answered from the cache, or 0 before
+// the first lookup
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// MemoryCache is an in-process Cache
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	stats   CacheStats
+	now     func() time.Time
+}
+
+// memoryEntry is a cached value with its expiry; a zero expiry never expires
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache creates an empty in-process cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// Get implements Cache
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		return nil, false, nil
+	}
+	if !entry.expires.IsZero() && !m.now().Before(entry.expires) {
+		delete(m.entries, key)
+		m.stats.Misses++
+		m.stats.Evictions++
+		return nil, false, nil
+	}
+	m.stats.Hits++
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Set implements Cache
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = m.now().Add(ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry
+	return nil
+}
+
+// Delete implements Cache
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// Stats returns the cache counters since creation
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Size = len(m.entries)
+	return stats
+}
@@ -0,0 +1,56 @@
+package vies
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+
+	cache.Set(ctx, "short", []byte("a"), time.Minute)
+	cache.Set(ctx, "forever", []byte("b"), 0)
+	if value, ok, _ := cache.Get(ctx, "short"); !ok || string(value) != "a" {
+		t.Errorf("Get(short) = %q, %t", value, ok)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok, _ := cache.Get(ctx, "short"); ok {
+		t.Error("expected the short entry to expire")
+	}
+	if _, ok, _ := cache.Get(ctx, "forever"); !ok {
+		t.Error("expected the entry without TTL to remain")
+	}
+
+	cache.Delete(ctx, "forever")
+	if _, ok, _ := cache.Get(ctx, "forever"); ok {
+		t.Error("expected the deleted entry to be gone")
+	}
+}
+
+func TestMemoryCacheStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC)
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+
+	cache.Set(ctx, "a", []byte("1"), time.Minute)
+	cache.Set(ctx, "b", []byte("2"), 0)
+	cache.Get(ctx, "a")
+	cache.Get(ctx, "b")
+	cache.Get(ctx, "missing")
+	now = now.Add(time.Hour)
+	cache.Get(ctx, "a")
+
+	want := CacheStats{Hits: 2, Misses: 2, Evictions: 1, Size: 1}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if rate := cache.Stats().HitRate(); rate != 0.5 {
+		t.Errorf("HitRate() = %v, want 0.5", rate)
+	}
+}
@@ -0,0 +1,83 @@
+package vies
+
+// ErrorInfo describes an error code reported by the client
+type ErrorInfo struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Retryable   bool   `json:"retryable"`
+	ExitCode    int    `json:"exitCode"`
+}
+
+// errorCatalog lists every error code in the order they are documented
+var errorCatalog = []ErrorInfo{
+	{
+		Code:        CodeInvalidFormat,
+		Description: "The VAT number does not match the format rules of its member state",
+		Retryable:   false,
+		ExitCode:    3,
+	},
+	{
+		Code:        CodeUnsupportedCountry,
+		Description: "The country prefix is not an EU member state known to VIES",
+		Retryable:   false,
+		ExitCode:    3,
+	},
+	{
+		Code:        CodeInvalidChecksum,
+		Description: "The VAT number has the right format but its check digits are wrong",
+		Retryable:   false,
+		ExitCode:    3,
+	},
+	{
+		Code:        CodeServiceError,
+		Description: "The request failed or VIES returned an unexpected response",
+		Retryable:   false,
+		ExitCode:    2,
+	},
+	{
+		Code:        CodeNetworkTimeout,
+		Description: "No response from VIES before the request timeout",
+		Retryable:   true,
+		ExitCode:    2,
+	},
+	{
+		Code:        CodeServiceUnavailable,
+		Description: "VIES or the member state service is temporarily unavailable",
+		Retryable:   true,
+		ExitCode:    4,
+	},
+	{
+		Code:        CodeBudgetExceeded,
+		Description: "The configured request budget is used up; the request was not sent",
+		Retryable:   true,
+		ExitCode:    5,
+	},
+	{
+		Code:        CodeSOAPFault,
+		Description: "VIES answered with a SOAP fault; the message carries the fault string",
+		Retryable:   false,
+		ExitCode:    2,
+	},
+	{
+		Code:        CodeInternalError,
+		Description: "A bug in viesquery or an enrichment hook panicked while checking the number; the check was abandoned",
+		Retryable:   false,
+		ExitCode:    2,
+	},
+}
+
+// ErrorCatalog returns all error codes with their descriptions, retryability
+// and the exit code the CLI uses for them
+func ErrorCatalog() []ErrorInfo {
+	return append([]ErrorInfo(nil), errorCatalog...)
+}
+
+// LookupError returns the catalog entry for an error code
+func LookupError(code string) (ErrorInfo, bool) {
+	for _, info := range errorCatalog {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return ErrorInfo{}, false
+}
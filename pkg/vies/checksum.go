@@ -0,0 +1,245 @@
+package vies
+
+import (
+	"strconv"
+	"strings"
+)
+
+// checksumValidators verify the check digits of VAT numbers per country.
+// They receive the number without country prefix after it has passed the
+// country's format pattern, so they may assume its shape.
+var checksumValidators = map[string]func(number string) bool{
+	"DE": checkDE,
+	"IT": checkIT,
+	"ES": checkES,
+	"NL": checkNL,
+	"BE": checkBE,
+	"PL": checkPL,
+	"DK": checkDK,
+	"FI": checkFI,
+	"SE": checkSE,
+}
+
+// validChecksum reports whether the number passes its country's check-digit
+// algorithm; countries without a known algorithm always pass
+func validChecksum(countryCode, number string) bool {
+	check, ok := checksumValidators[countryCode]
+	return !ok || check(number)
+}
+
+// HasCheckDigits reports whether ValidateFormat verifies the check digits
+// of the country's VAT numbers
+func HasCheckDigits(countryCode string) bool {
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	_, ok := checksumValidators[countryCode]
+	return ok
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// digit returns the numeric value of the ASCII digit at position i
+func digit(s string, i int) int {
+	return int(s[i] - '0')
+}
+
+// checkDE verifies a German USt-IdNr. (9 digits) using ISO 7064 MOD 11,10
+func checkDE(number string) bool {
+	product := 10
+	for i := 0; i < 8; i++ {
+		sum := (digit(number, i) + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	check := 11 - product
+	if check == 10 {
+		check = 0
+	}
+	return check == digit(number, 8)
+}
+
+// checkIT verifies an Italian Partita IVA (11 digits): digits 8-10 must be a
+// valid provincial office code and the last digit is a Luhn check digit
+func checkIT(number string) bool {
+	office := digit(number, 7)*100 + digit(number, 8)*10 + digit(number, 9)
+	if !(office >= 1 && office <= 100) && office != 120 && office != 121 && office != 888 && office != 999 {
+		return false
+	}
+	return luhnValid(number)
+}
+
+// luhnValid reports whether a digit string passes the Luhn algorithm, the
+// last digit being the check digit
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := digit(number, i)
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// dniLetters are the control letters of Spanish DNI/NIE numbers, indexed by
+// the number modulo 23
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// checkES verifies a Spanish NIF (9 characters). The first character selects
+// the algorithm: DNI for natural persons (digit, or K/L/M), NIE for foreigners
+// (X/Y/Z) and CIF for legal entities (other letters).
+func checkES(number string) bool {
+	first, body, control := number[0], number[1:8], number[8]
+	if !isDigits(body) {
+		return false
+	}
+	switch {
+	case first >= '0' && first <= '9':
+		return dniControl(number[:8]) == control
+	case first == 'K' || first == 'L' || first == 'M':
+		return dniControl(body) == control
+	case first == 'X' || first == 'Y' || first == 'Z':
+		return dniControl(string('0'+first-'X')+body) == control
+	case strings.IndexByte("ABCDEFGHJNPQRSUVW", first) >= 0:
+		return cifControlValid(first, body, control)
+	default:
+		return false
+	}
+}
+
+// dniControl returns the DNI control letter for a digit string
+func dniControl(digits string) byte {
+	n, _ := strconv.Atoi(digits)
+	return dniLetters[n%23]
+}
+
+// cifControlValid verifies the control character of a CIF. Depending on the
+// entity type it must be a digit, a letter, or may be either.
+func cifControlValid(entity byte, body string, control byte) bool {
+	sum := 0
+	for i := 0; i < 7; i++ {
+		d := digit(body, i)
+		if i%2 == 0 {
+			d *= 2
+			d = d/10 + d%10
+		}
+		sum += d
+	}
+	check := (10 - sum%10) % 10
+	letter, number := "JABCDEFGHI"[check], byte('0'+check)
+
+	switch {
+	case strings.IndexByte("NPQRSW", entity) >= 0:
+		return control == letter
+	case strings.IndexByte("ABEH", entity) >= 0:
+		return control == number
+	default:
+		return control == letter || control == number
+	}
+}
+
+// checkNL verifies a Dutch VAT number (9 digits, "B", 2 digits). Numbers
+// issued before 2020 pass the 11-proef on the first nine digits; the newer
+// sole-proprietor numbers instead pass ISO 7064 mod 97-10 over "NL" + number.
+func checkNL(number string) bool {
+	sum := 0
+	for i := 0; i < 8; i++ {
+		sum += digit(number, i) * (9 - i)
+	}
+	sum -= digit(number, 8)
+	if sum%11 == 0 {
+		return true
+	}
+	return mod97("NL"+number) == 1
+}
+
+// mod97 returns s modulo 97 with letters converted to numbers (A=10 ... Z=35)
+// as in ISO 7064 mod 97-10; s must consist of digits and upper-case letters
+func mod97(s string) int {
+	rem := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			v := int(c-'A') + 10
+			rem = (rem*100 + v) % 97
+		} else {
+			rem = (rem*10 + int(c-'0')) % 97
+		}
+	}
+	return rem
+}
+
+// checkBE verifies a Belgian enterprise number (10 digits): the last two
+// digits are 97 minus the first eight modulo 97
+func checkBE(number string) bool {
+	base, _ := strconv.Atoi(number[:8])
+	check, _ := strconv.Atoi(number[8:])
+	return 97-base%97 == check
+}
+
+// plWeights are the NIP check-digit weights for the first nine digits
+var plWeights = [9]int{6, 5, 7, 2, 3, 4, 5, 6, 7}
+
+// checkPL verifies a Polish NIP (10 digits): the weighted sum of the first
+// nine digits modulo 11 is the last digit (a remainder of 10 is never issued)
+func checkPL(number string) bool {
+	sum := 0
+	for i, w := range plWeights {
+		sum += digit(number, i) * w
+	}
+	return sum%11 == digit(number, 9)
+}
+
+// dkWeights are the CVR modulus-11 weights
+var dkWeights = [8]int{2, 7, 6, 5, 4, 3, 2, 1}
+
+// checkDK verifies a Danish CVR number (8 digits): the weighted sum of all
+// digits is divisible by 11
+func checkDK(number string) bool {
+	sum := 0
+	for i, w := range dkWeights {
+		sum += digit(number, i) * w
+	}
+	return sum%11 == 0
+}
+
+// fiWeights are the Y-tunnus check-digit weights for the first seven digits
+var fiWeights = [7]int{7, 9, 10, 5, 8, 4, 2}
+
+// checkFI verifies a Finnish Y-tunnus (8 digits): the last digit is 11 minus
+// the weighted sum modulo 11, or 0 when the remainder is 0; a remainder of 1
+// is never issued
+func checkFI(number string) bool {
+	sum := 0
+	for i, w := range fiWeights {
+		sum += digit(number, i) * w
+	}
+	check := 0
+	if rem := sum % 11; rem != 0 {
+		check = 11 - rem
+	}
+	return check != 10 && check == digit(number, 7)
+}
+
+// checkSE verifies a Swedish VAT number (12 digits): the organisation or
+// personal number in the first ten digits passes Luhn and the suffix is 01
+func checkSE(number string) bool {
+	return luhnValid(number[:10]) && number[10:] == "01"
+}
@@ -0,0 +1,92 @@
+package vies
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestChecksums(t *testing.T) {
+	tests := []struct {
+		vatNumber string
+		valid     bool
+	}{
+		{"DE136695976", true},
+		{"DE266201128", true},
+		{"DE123456788", true},
+		{"DE123456789", false},
+		{"DE136695977", false},
+		{"IT00743110157", true},
+		{"IT01114601006", true},
+		{"IT12345670017", true},
+		{"IT00743110158", false},
+		{"IT12345678901", false}, // office code 890 does not exist
+		{"ES12345678Z", true},
+		{"ESX1234567L", true},
+		{"ESA58818501", true},
+		{"ESQ2826000H", true},
+		{"ESB12345674", true},
+		{"ES12345678A", false},
+		{"ESA5881850A", false}, // type A requires a numeric control
+		{"ESQ28260008", false}, // type Q requires a letter control
+		{"NL004495445B01", true},
+		{"NL000099998B57", true}, // mod 97 (sole proprietor)
+		{"NL123456789B01", false},
+		{"NL004495446B01", false},
+		{"BE0403019261", true},
+		{"BE0123456789", false},
+		{"PL5260250274", true},
+		{"PL8567346215", true},
+		{"PL1234567890", false},
+		{"DK13585628", true},
+		{"DK12345678", false},
+		{"FI20774740", true},
+		{"FI12345678", false},
+		{"SE556036079301", true},
+		{"SE556036079302", false}, // suffix must be 01
+		{"SE556036079401", false},
+	}
+	for _, tt := range tests {
+		err := ValidateFormat(tt.vatNumber)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateFormat(%q) = %v, want valid", tt.vatNumber, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidChecksum) {
+			t.Errorf("ValidateFormat(%q) = %v, want checksum error", tt.vatNumber, err)
+		}
+	}
+}
+
+func TestHasCheckDigits(t *testing.T) {
+	if !HasCheckDigits("DE") {
+		t.Error("HasCheckDigits(DE) = false, want true")
+	}
+	if HasCheckDigits("FR") {
+		t.Error("HasCheckDigits(FR) = true, want false")
+	}
+}
+
+func TestGenerateVATNumber(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, country := range append(GetSupportedCountries(), "GR") {
+		for i := 0; i < 20; i++ {
+			vatNumber, err := GenerateVATNumber(country, rng)
+			if err != nil {
+				t.Fatalf("GenerateVATNumber(%s) error: %v", country, err)
+			}
+			if err := ValidateFormat(vatNumber); err != nil {
+				t.Errorf("GenerateVATNumber(%s) = %s, which fails validation: %v", country, vatNumber, err)
+			}
+		}
+	}
+
+	a, _ := GenerateVATNumber("DE", rand.New(rand.NewPCG(7, 7)))
+	b, _ := GenerateVATNumber("DE", rand.New(rand.NewPCG(7, 7)))
+	if a != b {
+		t.Errorf("expected the same seed to give the same number, got %s and %s", a, b)
+	}
+
+	if _, err := GenerateVATNumber("GB", rng); err == nil {
+		t.Error("expected an error for an unsupported country")
+	}
+}
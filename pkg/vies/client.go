@@ -0,0 +1,753 @@
+package vies
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEndpoint  = "https://ec.europa.eu/taxation_customs/vies/services/checkVatService"
+	defaultUserAgent = "viesquery/dev"
+	soapNamespace    = "urn:ec.europa.eu:taxud:vies:services:checkVat:types"
+	soap11Envelope   = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Envelope   = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Checker validates VAT numbers. Client implements it; viesmock provides a
+// programmable fake for tests.
+type Checker interface {
+	CheckVAT(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error)
+}
+
+var _ Checker = (*Client)(nil)
+
+// Client represents a VIES API client
+type Client struct {
+	httpClient     *http.Client
+	endpoint       string
+	userAgent      string
+	verbose        bool
+	redact         bool
+	testMode       bool
+	soap12         bool
+	validate       bool
+	inputPrefix    bool         // keep the GR prefix of Greek input in results
+	defaultCountry string       // prepended to input without country prefix
+	limiter        *rateLimiter // paces requests per member state; nil if unlimited
+	budget         RequestBudget
+	cache          Cache
+	cacheTTL       time.Duration
+	invalidTTL     time.Duration // cacheTTL for invalid results, if set
+	errorTTL       time.Duration // cacheTTL for VIES faults; 0: not cached
+	enrichers      []Enricher
+	logger         *log.Logger
+
+	responseDump io.Writer
+}
+
+// NewClient creates a new VIES client with the given options
+func NewClient(options ...ClientOption) *Client {
+	opts := &ClientOptions{
+		Timeout:     30 * time.Second,
+		UserAgent:   defaultUserAgent,
+		Verbose:     false,
+		Endpoint:    defaultEndpoint,
+		SOAPVersion: SOAP11,
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(opts)
+	}
+
+	// Create HTTP transport with security settings unless one was supplied
+	transport := opts.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+			},
+			DisableKeepAlives:     false,
+			MaxIdleConns:          10,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       30 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+		}
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: transport,
+		},
+		endpoint:       opts.Endpoint,
+		userAgent:      opts.UserAgent,
+		verbose:        opts.Verbose,
+		redact:         opts.Redact,
+		testMode:       opts.TestService,
+		soap12:         opts.SOAPVersion == SOAP12,
+		validate:       opts.ValidateRequests,
+		inputPrefix:    opts.InputCountryPrefix,
+		defaultCountry: strings.ToUpper(strings.TrimSpace(opts.DefaultCountry)),
+		budget:         opts.Budget,
+		cache:          opts.Cache,
+		cacheTTL:       opts.CacheTTL,
+		invalidTTL:     opts.CacheInvalidTTL,
+		errorTTL:       opts.CacheErrorTTL,
+		enrichers:      opts.Enrichers,
+		logger:         log.New(os.Stderr, "[VIES] ", log.LstdFlags),
+
+		responseDump: opts.ResponseDump,
+	}
+
+	if opts.Logger != nil {
+		client.logger = opts.Logger
+	}
+
+	if opts.RateLimit > 0 || len(opts.CountryRateLimits) > 0 {
+		client.limiter = newRateLimiter(opts.RateLimit, opts.CountryRateLimits)
+	}
+
+	return client
+}
+
+// preparedRequest is a checkVat call ready to be sent
+type preparedRequest struct {
+	httpRequest  *http.Request
+	countryCode  string
+	number       string
+	isTestNumber bool
+	// assumedCountry is the default country prepended to the input
+	assumedCountry string
+}
+
+// parseInput validates the VAT number and resolves the member state and
+// number sent to VIES. Test service numbers are passed through as they
+// cannot match the real format rules.
+func (c *Client) parseInput(vatNumber string) (*preparedRequest, error) {
+	prepared := &preparedRequest{}
+	if c.testMode {
+		prepared.countryCode, prepared.number, prepared.isTestNumber = parseTestVATNumber(vatNumber)
+	}
+	if !prepared.isTestNumber {
+		if c.defaultCountry != "" && !hasCountryPrefix(NormalizeInput(vatNumber)) {
+			prepared.assumedCountry = c.defaultCountry
+			vatNumber = c.defaultCountry + NormalizeInput(vatNumber)
+		}
+		var err error
+		prepared.countryCode, prepared.number, err = ParseVATNumber(vatNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return prepared, nil
+}
+
+// CountryCode returns the VIES code of the member state a check of
+// vatNumber is sent to (EL for Greece), taking the default country into
+// account. It fails like CheckVAT for malformed numbers.
+func (c *Client) CountryCode(vatNumber string) (string, error) {
+	prepared, err := c.parseInput(vatNumber)
+	if err != nil {
+		return "", err
+	}
+	return prepared.countryCode, nil
+}
+
+// prepareRequest validates the VAT number and builds the SOAP HTTP request
+func (c *Client) prepareRequest(ctx context.Context, vatNumber string, reqOpts *RequestOptions) (*preparedRequest, error) {
+	prepared, err := c.parseInput(vatNumber)
+	if err != nil {
+		return nil, err
+	}
+	if prepared.assumedCountry != "" {
+		vatNumber = prepared.assumedCountry + NormalizeInput(vatNumber)
+		if c.verbose {
+			c.logger.Printf("No country prefix in input, assuming %s", prepared.assumedCountry)
+		}
+	}
+
+	if c.verbose {
+		c.logger.Printf("Parsed VAT: Country=%s, Number=%s", prepared.countryCode, prepared.number)
+	}
+
+	// Create SOAP request, on behalf of the requester if one is given
+	envelopeNamespace := soap11Envelope
+	if c.soap12 {
+		envelopeNamespace = soap12Envelope
+	}
+	soapRequest := createSOAPRequest(envelopeNamespace, prepared.countryCode, prepared.number)
+	soapAction := "checkVat"
+	if reqOpts.Requester != "" {
+		requesterCountry, requesterNumber, err := ParseVATNumber(reqOpts.Requester)
+		if err != nil {
+			return nil, err
+		}
+		soapRequest = createSOAPApproxRequest(envelopeNamespace, prepared.countryCode, prepared.number, requesterCountry, requesterNumber)
+		soapAction = "checkVatApprox"
+	}
+
+	// Marshal to XML
+	requestBody, err := xml.Marshal(soapRequest)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:      CodeServiceError,
+			Message:   fmt.Sprintf("Failed to create SOAP request: %v", err),
+			VATNumber: vatNumber,
+			Err:       err,
+		}
+	}
+
+	// Add XML declaration
+	fullRequest := []byte(xml.Header + string(requestBody))
+
+	if c.verbose {
+		c.logger.Printf("SOAP Request: %s", string(fullRequest))
+	}
+
+	if c.validate {
+		if err := ValidateEnvelope(fullRequest); err != nil {
+			return nil, &ServiceError{
+				Code:      CodeServiceError,
+				Message:   fmt.Sprintf("Generated SOAP request does not conform to the checkVat schema: %v", err),
+				VATNumber: vatNumber,
+				Err:       err,
+			}
+		}
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", reqOpts.Endpoint, bytes.NewReader(fullRequest))
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    CodeServiceError,
+			Message: fmt.Sprintf("Failed to create HTTP request: %v", err),
+			Err:     err,
+		}
+	}
+
+	// Set headers; SOAP 1.2 carries the action in the content type
+	if c.soap12 {
+		req.Header.Set("Content-Type", fmt.Sprintf("application/soap+xml; charset=utf-8; action=%q", soapAction))
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", soapAction)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	prepared.httpRequest = req
+	return prepared, nil
+}
+
+// NewRequest returns the HTTP request CheckVAT would send for vatNumber,
+// without sending it. It is meant for dry runs and debugging.
+func (c *Client) NewRequest(ctx context.Context, vatNumber string, options ...RequestOption) (*http.Request, error) {
+	reqOpts := &RequestOptions{
+		Endpoint: c.endpoint,
+	}
+	for _, option := range options {
+		option(reqOpts)
+	}
+	prepared, err := c.prepareRequest(ctx, vatNumber, reqOpts)
+	if err != nil {
+		return nil, err
+	}
+	return prepared.httpRequest, nil
+}
+
+// CheckVAT validates a VAT number using the VIES service. Request options
+// override client settings for this call only.
+func (c *Client) CheckVAT(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+	startTime := time.Now()
+
+	reqOpts := &RequestOptions{
+		Endpoint: c.endpoint,
+	}
+	for _, option := range options {
+		option(reqOpts)
+	}
+
+	if c.verbose {
+		c.logger.Printf("Validating VAT number: %s", vatNumber)
+	}
+
+	// A per-call timeout replaces the client timeout in both directions
+	httpClient := c.httpClient
+	if reqOpts.Timeout > 0 {
+		perCall := *c.httpClient
+		perCall.Timeout = 0
+		httpClient = &perCall
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reqOpts.Timeout)
+		defer cancel()
+	}
+
+	prepared, err := c.prepareRequest(ctx, vatNumber, reqOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve from the cache if possible. Checks on behalf of a requester are
+	// never cached, as each must yield its own consultation number.
+	useCache := c.cache != nil && !reqOpts.NoCache && reqOpts.Requester == "" && !prepared.isTestNumber
+	key := cacheKey(reqOpts.Endpoint, c.testMode, prepared.countryCode, prepared.number)
+	if useCache && !reqOpts.Refresh {
+		if entry := c.cachedEntry(ctx, key); entry != nil {
+			if entry.Error != nil {
+				return nil, entry.Error.serviceError()
+			}
+			cached := entry.Result
+			c.finishResult(cached, prepared, vatNumber)
+			c.enrich(ctx, cached)
+			cached.DurationMs = time.Since(startTime).Milliseconds()
+			cached.Timing = nil
+			return cached, nil
+		}
+	}
+	waitStart := time.Now()
+
+	// Wait for the member state's rate limit
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx, prepared.countryCode); err != nil {
+			code := CodeServiceError
+			if errors.Is(err, context.DeadlineExceeded) {
+				code = CodeNetworkTimeout
+			}
+			return nil, &ServiceError{
+				Code:      code,
+				Message:   fmt.Sprintf("Request cancelled while waiting for the %s rate limit: %v", prepared.countryCode, err),
+				VATNumber: vatNumber,
+				Err:       err,
+			}
+		}
+	}
+
+	// Charge the request budget once the request is about to be sent, so a
+	// cancelled wait costs nothing; refused requests are never sent
+	if c.budget != nil {
+		if err := c.budget.Take(); err != nil {
+			code := CodeServiceError
+			if errors.Is(err, ErrBudgetExceeded) {
+				code = CodeBudgetExceeded
+			}
+			return nil, &ServiceError{
+				Code:      code,
+				Message:   fmt.Sprintf("Request not sent: %v", err),
+				VATNumber: vatNumber,
+				Err:       err,
+			}
+		}
+	}
+
+	// Send HTTP request
+	wait := time.Since(waitStart)
+	result, err := c.sendSOAPRequest(ctx, httpClient, prepared.httpRequest)
+	if prepared.isTestNumber {
+		labelTestScenario(prepared.number, result, err)
+	}
+	if err != nil {
+		if useCache {
+			c.storeError(ctx, key, err)
+		}
+		return nil, err
+	}
+	result.Timing.WaitMs = wait.Milliseconds()
+
+	c.finishResult(result, prepared, vatNumber)
+	if useCache {
+		c.storeResult(ctx, key, result)
+	}
+	c.enrich(ctx, result)
+
+	duration := time.Since(startTime)
+	result.DurationMs = duration.Milliseconds()
+	if c.verbose {
+		c.logger.Printf("Validation completed in %v. Valid: %t", duration, result.Valid)
+	}
+
+	return result, nil
+}
+
+// hasCountryPrefix reports whether normalized input starts with a country
+// code. Numbers of some member states start with one letter (e.g. Spanish
+// "A12345678"), but none with two.
+func hasCountryPrefix(normalized string) bool {
+	return len(normalized) >= 2 &&
+		normalized[0] >= 'A' && normalized[0] <= 'Z' &&
+		normalized[1] >= 'A' && normalized[1] <= 'Z'
+}
+
+// finishResult sets the VAT number fields of a result for the given input
+func (c *Client) finishResult(result *CheckVatResult, prepared *preparedRequest, vatNumber string) {
+	// Set original VAT number for display
+	result.VatNumber = prepared.number
+	result.CountryCode = prepared.countryCode
+	result.NormalizedVATNumber = NormalizeInput(vatNumber)
+	result.CanonicalVATNumber = prepared.countryCode + prepared.number
+	result.AssumedCountryCode = prepared.assumedCountry
+	if result.Address != "" {
+		result.AddressCountryCode, result.AddressCountry, _ = ISOCountry(prepared.countryCode)
+	}
+	if c.inputPrefix && prepared.countryCode == "EL" && strings.HasPrefix(result.NormalizedVATNumber, "GR") {
+		result.CountryCode = "GR"
+	}
+}
+
+// cachedEntry returns the cache entry for key, or nil. Cache failures are
+// logged and treated as misses.
+func (c *Client) cachedEntry(ctx context.Context, key string) *cacheEntry {
+	data, ok, err := c.cache.Get(ctx, key)
+	if err != nil && c.verbose {
+		c.logger.Printf("Cache lookup failed: %v", err)
+	}
+	if err != nil || !ok {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || (entry.Result == nil && entry.Error == nil) {
+		if c.verbose {
+			c.logger.Printf("Ignoring malformed cache entry %s: %v", key, err)
+		}
+		return nil
+	}
+	if c.verbose {
+		c.logger.Printf("Cache hit: %s", key)
+	}
+	return &entry
+}
+
+// storeResult caches a result, invalid ones for invalidTTL if set
+func (c *Client) storeResult(ctx context.Context, key string, result *CheckVatResult) {
+	ttl := c.cacheTTL
+	if !result.Valid && c.invalidTTL > 0 {
+		ttl = c.invalidTTL
+	}
+	c.storeEntry(ctx, key, cacheEntry{Result: result}, ttl)
+}
+
+// storeError caches a fault reported by VIES for errorTTL. Other errors,
+// such as network failures, say nothing about the VAT number and are never
+// cached.
+func (c *Client) storeError(ctx context.Context, key string, err error) {
+	var serviceErr *ServiceError
+	if c.errorTTL <= 0 || !errors.As(err, &serviceErr) || serviceErr.FaultCode == "" {
+		return
+	}
+	c.storeEntry(ctx, key, cacheEntry{Error: &cachedError{
+		Code:       serviceErr.Code,
+		Message:    serviceErr.Message,
+		HTTPStatus: serviceErr.HTTPStatus,
+		FaultCode:  serviceErr.FaultCode,
+	}}, c.errorTTL)
+}
+
+// storeEntry writes a cache entry; failures are logged and otherwise ignored
+func (c *Client) storeEntry(ctx context.Context, key string, entry cacheEntry, ttl time.Duration) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = c.cache.Set(ctx, key, data, ttl)
+	}
+	if err != nil && c.verbose {
+		c.logger.Printf("Cache store failed: %v", err)
+	}
+}
+
+// sendSOAPRequest sends a SOAP request and parses the response
+func (c *Client) sendSOAPRequest(ctx context.Context, httpClient *http.Client, req *http.Request) (*CheckVatResult, error) {
+	var trace *requestTrace
+	if c.verbose {
+		c.logger.Printf("Sending request to: %s", req.URL)
+		req, trace = traceRequest(req)
+		defer func() { c.logger.Printf("Request timing: %s", trace) }()
+	}
+
+	// Send request
+	requestStart := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, &ServiceError{
+				Code:    CodeNetworkTimeout,
+				Message: "Request timeout exceeded",
+				Err:     err,
+			}
+		}
+		return nil, &ServiceError{
+			Code:    CodeServiceError,
+			Message: fmt.Sprintf("HTTP request failed: %v", err),
+			Err:     err,
+		}
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    CodeServiceError,
+			Message: fmt.Sprintf("Failed to read response body: %v", err),
+			Err:     err,
+		}
+	}
+	requestTime := time.Since(requestStart)
+	if trace != nil {
+		trace.mark(&trace.bodyRead)
+	}
+
+	if c.responseDump != nil {
+		c.dumpResponse(resp, responseBody)
+	}
+
+	if c.verbose {
+		c.logger.Printf("Response Status: %s", resp.Status)
+		loggedBody := responseBody
+		if c.redact {
+			loggedBody = RedactPayload(responseBody)
+		}
+		c.logger.Printf("Response Body: %s", string(loggedBody))
+	}
+
+	// Check HTTP status
+	if resp.StatusCode != http.StatusOK {
+		// VIES reports SOAP faults with HTTP 500; surface the fault itself
+		if _, parseErr := ParseSOAPResponse(responseBody); parseErr != nil {
+			var serviceErr *ServiceError
+			if errors.As(parseErr, &serviceErr) && serviceErr.Code == CodeSOAPFault {
+				serviceErr.HTTPStatus = resp.StatusCode
+				return nil, serviceErr
+			}
+		}
+		if resp.StatusCode == http.StatusServiceUnavailable ||
+			resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusGatewayTimeout {
+			return nil, &ServiceError{
+				Code:       CodeServiceUnavailable,
+				Message:    "VIES service is temporarily unavailable",
+				HTTPStatus: resp.StatusCode,
+				RawBody:    string(responseBody),
+			}
+		}
+		return nil, &ServiceError{
+			Code:       CodeServiceError,
+			Message:    fmt.Sprintf("HTTP error: %s", resp.Status),
+			HTTPStatus: resp.StatusCode,
+			RawBody:    string(responseBody),
+		}
+	}
+
+	// Parse SOAP response
+	parseStart := time.Now()
+	result, err := ParseSOAPResponse(responseBody)
+	if trace != nil {
+		trace.mark(&trace.parsed)
+	}
+	if err != nil {
+		var serviceErr *ServiceError
+		if errors.As(err, &serviceErr) {
+			serviceErr.HTTPStatus = resp.StatusCode
+		}
+		return nil, err
+	}
+	result.Timing = &RequestTiming{
+		RequestMs: requestTime.Milliseconds(),
+		ParseMs:   time.Since(parseStart).Milliseconds(),
+	}
+	return result, nil
+}
+
+// dumpResponse writes the raw HTTP response to the configured dump writer,
+// redacting trader data if requested
+func (c *Client) dumpResponse(resp *http.Response, body []byte) {
+	if c.redact {
+		body = RedactPayload(body)
+	}
+	fmt.Fprintf(c.responseDump, "%s %s\r\n", resp.Proto, resp.Status)
+	resp.Header.Write(c.responseDump)
+	fmt.Fprintf(c.responseDump, "\r\n%s\n", body)
+}
+
+// ParseSOAPResponse parses a checkVat or checkVatApprox SOAP response from
+// VIES. SOAP faults and malformed documents are returned as *ServiceError.
+func ParseSOAPResponse(responseBody []byte) (*CheckVatResult, error) {
+	// Use inline structs to avoid namespace conflicts
+	var envelope struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Body    struct {
+			CheckVatResponse *struct {
+				XMLName     xml.Name `xml:"checkVatResponse"`
+				CountryCode string   `xml:"countryCode"`
+				VatNumber   string   `xml:"vatNumber"`
+				RequestDate string   `xml:"requestDate"` // Parse as string first, then convert to time.Time
+				Valid       bool     `xml:"valid"`
+				Name        string   `xml:"name"`
+				Address     string   `xml:"address"`
+			} `xml:"checkVatResponse"`
+			CheckVatApproxResponse *struct {
+				XMLName           xml.Name `xml:"checkVatApproxResponse"`
+				CountryCode       string   `xml:"countryCode"`
+				VatNumber         string   `xml:"vatNumber"`
+				RequestDate       string   `xml:"requestDate"`
+				Valid             bool     `xml:"valid"`
+				TraderName        string   `xml:"traderName"`
+				TraderAddress     string   `xml:"traderAddress"`
+				RequestIdentifier string   `xml:"requestIdentifier"`
+			} `xml:"checkVatApproxResponse"`
+			Fault *struct {
+				XMLName xml.Name     `xml:"Fault"`
+				Code    string       `xml:"faultcode"`
+				String  string       `xml:"faultstring"`
+				Detail  *FaultDetail `xml:"detail"`
+				// SOAP 1.2 equivalents of faultcode, faultstring and detail
+				Value    string       `xml:"Code>Value"`
+				Reason   string       `xml:"Reason>Text"`
+				Detail12 *FaultDetail `xml:"Detail"`
+			} `xml:"Fault"`
+		} `xml:"Body"`
+	}
+
+	err := xml.Unmarshal(responseBody, &envelope)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    CodeServiceError,
+			Message: fmt.Sprintf("Failed to parse SOAP response: %v", err),
+			RawBody: string(responseBody),
+			Err:     err,
+		}
+	}
+
+	// Check for SOAP fault
+	if fault := envelope.Body.Fault; fault != nil {
+		code, reason, detail := fault.Code, fault.String, fault.Detail
+		if code == "" && reason == "" {
+			code, reason, detail = fault.Value, fault.Reason, fault.Detail12
+		}
+		if detail != nil && len(detail.Fields) == 0 {
+			detail = nil
+		}
+		return nil, &ServiceError{
+			Code:        CodeSOAPFault,
+			Message:     fmt.Sprintf("SOAP fault: %s - %s", code, reason),
+			FaultCode:   strings.TrimSpace(reason),
+			RawBody:     string(responseBody),
+			FaultDetail: detail,
+		}
+	}
+
+	// Check for valid response
+	var result *CheckVatResult
+	var rawDate string
+	switch {
+	case envelope.Body.CheckVatResponse != nil:
+		resp := envelope.Body.CheckVatResponse
+		rawDate = resp.RequestDate
+		result = &CheckVatResult{
+			CountryCode: resp.CountryCode,
+			VatNumber:   resp.VatNumber,
+			Valid:       resp.Valid,
+			Name:        strings.TrimSpace(resp.Name),
+			Address:     strings.TrimSpace(resp.Address),
+		}
+	case envelope.Body.CheckVatApproxResponse != nil:
+		resp := envelope.Body.CheckVatApproxResponse
+		rawDate = resp.RequestDate
+		result = &CheckVatResult{
+			CountryCode:       resp.CountryCode,
+			VatNumber:         resp.VatNumber,
+			Valid:             resp.Valid,
+			Name:              strings.TrimSpace(resp.TraderName),
+			Address:           strings.TrimSpace(resp.TraderAddress),
+			RequestIdentifier: strings.TrimSpace(resp.RequestIdentifier),
+		}
+	default:
+		return nil, &ServiceError{
+			Code:    CodeServiceError,
+			Message: "Invalid SOAP response: missing checkVatResponse",
+			RawBody: string(responseBody),
+		}
+	}
+
+	// Parse request date (xsd:date format: YYYY-MM-DD)
+	requestDate, err := time.Parse("2006-01-02", rawDate)
+	if err != nil {
+		// Try parsing with timezone suffix if present
+		requestDate, err = time.Parse("2006-01-02-07:00", rawDate)
+		if err != nil {
+			return nil, &ServiceError{
+				Code:    CodeServiceError,
+				Message: fmt.Sprintf("Failed to parse request date '%s': %v", rawDate, err),
+				RawBody: string(responseBody),
+				Err:     err,
+			}
+		}
+	}
+	result.RequestDate = requestDate
+	result.RawRequestDate = rawDate
+
+	return result, nil
+}
+
+// createSOAPRequest creates a SOAP envelope for VAT validation
+func createSOAPRequest(envelopeNamespace, countryCode, vatNumber string) *SOAPEnvelope {
+	return &SOAPEnvelope{
+		XmlnsSoapenv: envelopeNamespace,
+		XmlnsUrn:     soapNamespace,
+		Body: SOAPBody{
+			CheckVat: &CheckVatRequest{
+				CountryCode: countryCode,
+				VatNumber:   vatNumber,
+			},
+		},
+	}
+}
+
+// createSOAPApproxRequest creates a SOAP envelope for VAT validation on
+// behalf of a requester
+func createSOAPApproxRequest(envelopeNamespace, countryCode, vatNumber, requesterCountryCode, requesterVatNumber string) *SOAPEnvelope {
+	return &SOAPEnvelope{
+		XmlnsSoapenv: envelopeNamespace,
+		XmlnsUrn:     soapNamespace,
+		Body: SOAPBody{
+			CheckVatApprox: &CheckVatApproxRequest{
+				CountryCode:          countryCode,
+				VatNumber:            vatNumber,
+				RequesterCountryCode: requesterCountryCode,
+				RequesterVatNumber:   requesterVatNumber,
+			},
+		},
+	}
+}
+
+// Ping tests connectivity to the VIES service
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", c.endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("service returned status: %s", resp.Status)
+	}
+
+	return nil
+}
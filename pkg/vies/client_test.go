@@ -0,0 +1,613 @@
+package vies_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+	"l22.io/viesquery/pkg/vies/viesmock"
+	"l22.io/viesquery/pkg/vies/viestest"
+)
+
+func TestGreekPrefix(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("EL094259216", viestest.Result{Valid: true, Address: "ATHINA 10431"})
+
+	result, err := vies.NewClient(vies.WithEndpoint(srv.URL)).CheckVAT(context.Background(), "GR094259216")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.CountryCode != "EL" || result.NormalizedVATNumber != "GR094259216" || result.CanonicalVATNumber != "EL094259216" {
+		t.Errorf("unexpected canonical result: %+v", result)
+	}
+	if result.AddressCountryCode != "GR" || result.AddressCountry != "Greece" {
+		t.Errorf("expected the ISO address country GR/Greece: %+v", result)
+	}
+
+	result, err = vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithInputCountryPrefix()).CheckVAT(context.Background(), "GR094259216")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.CountryCode != "GR" || result.CanonicalVATNumber != "EL094259216" {
+		t.Errorf("expected the GR input prefix to be kept: %+v", result)
+	}
+}
+
+func TestRequestBudget(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithRequestBudget(&countingBudget{left: 1}))
+	if _, err := client.CheckVAT(context.Background(), "DE266201128"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.CheckVAT(context.Background(), "DE266201128"); !errors.Is(err, vies.ErrBudgetExceeded) {
+		t.Errorf("expected BUDGET_EXCEEDED, got %v", err)
+	}
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", got)
+	}
+}
+
+func TestRequestBudgetCancelledWait(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+
+	budget := &countingBudget{left: 2}
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithRequestBudget(budget), vies.WithCountryRateLimit("DE", 0.01))
+	if _, err := client.CheckVAT(context.Background(), "DE266201128"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The second check waits for the rate limit until it is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.CheckVAT(ctx, "DE266201128"); !errors.Is(err, vies.ErrNetworkTimeout) {
+		t.Fatalf("expected a timeout while waiting for the rate limit, got %v", err)
+	}
+	if budget.left != 1 {
+		t.Errorf("expected the cancelled wait to leave the budget at 1, got %d", budget.left)
+	}
+}
+
+// countingBudget allows a fixed number of requests
+type countingBudget struct{ left int }
+
+func (b *countingBudget) Take() error {
+	if b.left == 0 {
+		return vies.ErrBudgetExceeded
+	}
+	b.left--
+	return nil
+}
+
+func TestClientCache(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", viestest.Result{Valid: true, Name: "Example GmbH"})
+
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithCache(vies.NewMemoryCache(), time.Hour))
+	ctx := context.Background()
+	for _, input := range []string{"DE266201128", "de 266 201 128"} {
+		result, err := client.CheckVAT(ctx, input)
+		if err != nil || !result.Valid || result.Name != "Example GmbH" {
+			t.Fatalf("CheckVAT(%q) = %+v, %v", input, result, err)
+		}
+		if result.NormalizedVATNumber != "DE266201128" {
+			t.Errorf("CheckVAT(%q) normalized = %q", input, result.NormalizedVATNumber)
+		}
+	}
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("expected the second check to be served from cache, got %d requests", got)
+	}
+
+	if _, err := client.CheckVAT(ctx, "DE266201128", vies.WithNoCache()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("expected WithNoCache to bypass the cache, got %d requests", got)
+	}
+}
+
+func TestClientCacheRefresh(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithCache(vies.NewMemoryCache(), time.Hour))
+	ctx := context.Background()
+
+	check := func(options ...vies.RequestOption) bool {
+		t.Helper()
+		result, err := client.CheckVAT(ctx, "DE266201128", options...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.Valid
+	}
+
+	// The number is registered after the first, cached check
+	if check() {
+		t.Fatal("expected the unregistered number to be invalid")
+	}
+	mock.SetValid("DE266201128", "Example GmbH", "Berlin")
+	if check() {
+		t.Error("expected the cached invalid result before the refresh")
+	}
+	if !check(vies.WithRefresh()) {
+		t.Error("expected WithRefresh to ask VIES again")
+	}
+	if !check() || len(mock.Calls()) != 2 {
+		t.Errorf("expected the refreshed result from cache after 2 calls, got %d calls", len(mock.Calls()))
+	}
+
+	// WithNoCache neither reads nor replaces the entry
+	mock.SetResult("DE266201128", &vies.CheckVatResult{})
+	if check(vies.WithNoCache()) {
+		t.Error("expected WithNoCache to ask VIES")
+	}
+	if !check() || len(mock.Calls()) != 3 {
+		t.Errorf("expected WithNoCache to leave the cached entry, got %d calls", len(mock.Calls()))
+	}
+}
+
+func TestClientCacheEndpoint(t *testing.T) {
+	production, other := viesmock.New(), viesmock.New()
+	productionSrv := httptest.NewServer(production.Handler())
+	defer productionSrv.Close()
+	otherSrv := httptest.NewServer(other.Handler())
+	defer otherSrv.Close()
+	production.SetValid("DE136695976", "Example GmbH", "Berlin")
+	cache := vies.NewMemoryCache()
+	ctx := context.Background()
+
+	client := vies.NewClient(vies.WithEndpoint(productionSrv.URL), vies.WithCache(cache, time.Hour))
+	if result, err := client.CheckVAT(ctx, "DE136695976"); err != nil || !result.Valid {
+		t.Fatalf("CheckVAT() = %+v, %v", result, err)
+	}
+
+	// The cached result of one endpoint does not answer another
+	result, err := client.CheckVAT(ctx, "DE136695976", vies.WithRequestEndpoint(otherSrv.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid || len(other.Calls()) != 1 {
+		t.Errorf("expected the per-call endpoint to be asked, got %+v after %d calls", result, len(other.Calls()))
+	}
+	if _, err := client.CheckVAT(ctx, "DE136695976", vies.WithRequestEndpoint(otherSrv.URL)); err != nil || len(other.Calls()) != 1 {
+		t.Errorf("expected the per-call endpoint result to be cached, got %d calls, %v", len(other.Calls()), err)
+	}
+
+	// Neither does the same endpoint in test mode
+	testClient := vies.NewClient(vies.WithTestService(), vies.WithEndpoint(productionSrv.URL), vies.WithCache(cache, time.Hour))
+	if _, err := testClient.CheckVAT(ctx, "DE136695976"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(production.Calls()) != 2 {
+		t.Errorf("expected test mode to bypass the production entry, got %d calls", len(production.Calls()))
+	}
+}
+
+// ttlCache is a MemoryCache recording the TTL of every stored key
+type ttlCache struct {
+	*vies.MemoryCache
+	ttls map[string]time.Duration
+}
+
+func (c *ttlCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return c.MemoryCache.Set(ctx, key, value, ttl)
+}
+
+func TestClientCacheTTLs(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", viestest.Result{Valid: true, Name: "Example GmbH"})
+	srv.SetFault("FR40303265045", viestest.FaultMSUnavailable)
+
+	tests := []struct {
+		name     string
+		negative []vies.ClientOption
+		input    string
+		wantTTL  time.Duration
+		cached   bool
+	}{
+		{"valid", nil, "DE266201128", time.Hour, true},
+		{"invalid", []vies.ClientOption{vies.WithNegativeCache(10*time.Minute, 0)}, "DE136695976", 10 * time.Minute, true},
+		{"invalid without own TTL", nil, "DE136695976", time.Hour, true},
+		{"error", []vies.ClientOption{vies.WithNegativeCache(0, time.Minute)}, "FR40303265045", time.Minute, true},
+		{"error without TTL", nil, "FR40303265045", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &ttlCache{MemoryCache: vies.NewMemoryCache(), ttls: make(map[string]time.Duration)}
+			options := append([]vies.ClientOption{vies.WithEndpoint(srv.URL), vies.WithCache(cache, time.Hour)}, tt.negative...)
+			client := vies.NewClient(options...)
+
+			before := len(srv.Requests())
+			first, firstErr := client.CheckVAT(context.Background(), tt.input)
+			second, secondErr := client.CheckVAT(context.Background(), tt.input)
+
+			var ttl time.Duration
+			var stored bool
+			for key, keyTTL := range cache.ttls {
+				if strings.HasSuffix(key, ":"+tt.input) {
+					ttl, stored = keyTTL, true
+				}
+			}
+			if stored != tt.cached || ttl != tt.wantTTL {
+				t.Errorf("stored = %t with TTL %v, want %t with %v", stored, ttl, tt.cached, tt.wantTTL)
+			}
+			requests := len(srv.Requests()) - before
+			if tt.cached && requests != 1 || !tt.cached && requests != 2 {
+				t.Errorf("got %d requests, cached = %t", requests, tt.cached)
+			}
+			if firstErr != nil {
+				var serviceErr *vies.ServiceError
+				if !errors.As(secondErr, &serviceErr) || !errors.Is(secondErr, vies.ErrSOAPFault) ||
+					serviceErr.FaultCode != "MS_UNAVAILABLE" || serviceErr.Error() != firstErr.Error() {
+					t.Errorf("expected the MS_UNAVAILABLE fault again, got %v", secondErr)
+				}
+				return
+			}
+			if secondErr != nil || second.Valid != first.Valid {
+				t.Errorf("second check = %+v, %v; first = %+v", second, secondErr, first)
+			}
+		})
+	}
+}
+
+func TestClientEnricher(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", viestest.Result{Valid: true, Name: "Example GmbH"})
+
+	calls := 0
+	registry := vies.EnricherFunc(func(ctx context.Context, result *vies.CheckVatResult) (map[string]any, error) {
+		calls++
+		return map[string]any{"registry": "HRB 12345", "source": "registry"}, nil
+	})
+	override := vies.EnricherFunc(func(ctx context.Context, result *vies.CheckVatResult) (map[string]any, error) {
+		return map[string]any{"source": "override"}, nil
+	})
+	failing := vies.EnricherFunc(func(ctx context.Context, result *vies.CheckVatResult) (map[string]any, error) {
+		return map[string]any{"ignored": true}, errors.New("lookup failed")
+	})
+	client := vies.NewClient(
+		vies.WithEndpoint(srv.URL),
+		vies.WithCache(vies.NewMemoryCache(), time.Hour),
+		vies.WithEnricher(registry),
+		vies.WithEnricher(override),
+		vies.WithEnricher(failing),
+	)
+
+	for i := 0; i < 2; i++ {
+		result, err := client.CheckVAT(context.Background(), "DE266201128")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]any{"registry": "HRB 12345", "source": "override"}
+		if !reflect.DeepEqual(result.Extensions, want) {
+			t.Errorf("check %d: Extensions = %v, want %v", i+1, result.Extensions, want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected cached results to be enriched too, got %d calls", calls)
+	}
+
+	if _, err := client.CheckVAT(context.Background(), "DE12345"); err == nil || calls != 2 {
+		t.Errorf("expected failed checks not to be enriched, got %v after %d calls", err, calls)
+	}
+}
+
+func TestDefaultCountry(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("IT12345670017", viestest.Result{Valid: true})
+	srv.SetResult("ESA00112235", viestest.Result{Valid: true})
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithDefaultCountry("it"))
+
+	result, err := client.CheckVAT(context.Background(), "123 4567 0017")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || result.CanonicalVATNumber != "IT12345670017" || result.AssumedCountryCode != "IT" {
+		t.Errorf("expected the default country to be prepended: %+v", result)
+	}
+
+	// Numbers with a prefix, even a single-letter Spanish one, are kept
+	result, err = client.CheckVAT(context.Background(), "ESA00112235")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.CanonicalVATNumber != "ESA00112235" || result.AssumedCountryCode != "" {
+		t.Errorf("expected the prefixed number to be kept: %+v", result)
+	}
+	if _, err := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithDefaultCountry("ES")).CheckVAT(context.Background(), "A00112235"); err != nil {
+		t.Errorf("expected ES to be prepended to a number starting with one letter: %v", err)
+	}
+}
+
+func TestClientCountryCode(t *testing.T) {
+	client := vies.NewClient(vies.WithDefaultCountry("IT"))
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"123 4567 0017", "IT"},
+		{"DE266201128", "DE"},
+		{"GR094259216", "EL"},
+	}
+	for _, tt := range tests {
+		if got, err := client.CountryCode(tt.input); err != nil || got != tt.want {
+			t.Errorf("CountryCode(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := client.CountryCode("DE1"); !errors.Is(err, vies.ErrInvalidFormat) {
+		t.Errorf("CountryCode(DE1) error = %v, want INVALID_FORMAT", err)
+	}
+}
+
+func TestResultDuration(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", viestest.Result{Valid: true})
+	srv.Inject(viestest.Injection{Latency: 50 * time.Millisecond})
+
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithCache(vies.NewMemoryCache(), time.Hour))
+	ctx := context.Background()
+	result, err := client.CheckVAT(ctx, "DE266201128")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DurationMs < 50 || result.Timing == nil || result.Timing.RequestMs < 50 {
+		t.Errorf("expected the injected latency in the duration and timing: %d ms, %+v", result.DurationMs, result.Timing)
+	}
+	if result.Timing != nil && result.Timing.RequestMs > result.DurationMs {
+		t.Errorf("request time %d ms exceeds the total %d ms", result.Timing.RequestMs, result.DurationMs)
+	}
+
+	cached, err := client.CheckVAT(ctx, "DE266201128")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cached.DurationMs >= 50 || cached.Timing != nil {
+		t.Errorf("expected a cached result without request timing: %d ms, %+v", cached.DurationMs, cached.Timing)
+	}
+}
+
+func TestVerboseRequestTiming(t *testing.T) {
+	srv := viestest.NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", viestest.Result{Valid: true})
+
+	var logs strings.Builder
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithVerbose(true), vies.WithLogger(log.New(&logs, "", 0)))
+	for range 2 {
+		if _, err := client.CheckVAT(context.Background(), "DE266201128"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lines := strings.Split(logs.String(), "\n")
+	var timings []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Request timing: ") {
+			timings = append(timings, line)
+		}
+	}
+	if len(timings) != 2 {
+		t.Fatalf("expected a timing line per request, got:\n%s", logs.String())
+	}
+	for _, phase := range []string{"connect ", "TTFB ", "read ", "parse "} {
+		if !strings.Contains(timings[0], phase) {
+			t.Errorf("first request timing lacks %q: %s", phase, timings[0])
+		}
+	}
+	if !strings.Contains(timings[1], "connection reused") || strings.Contains(timings[1], "connect ") {
+		t.Errorf("expected the second request to reuse the connection: %s", timings[1])
+	}
+}
+
+func TestWithRequester(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	mock.SetResult("DE136695976", &vies.CheckVatResult{Valid: true, Name: "Example GmbH", RequestIdentifier: "WAPIAAAAY3fP1Nqh"})
+	mock.SetValid("FR40303265045", "Exemple SA", "Paris")
+	client := vies.NewClient(vies.WithEndpoint(srv.URL))
+
+	result, err := client.CheckVAT(context.Background(), "DE136695976", vies.WithRequester("FR40303265045"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequestIdentifier != "WAPIAAAAY3fP1Nqh" || result.Name != "Example GmbH" {
+		t.Errorf("expected the consultation number and trader name: %+v", result)
+	}
+	body := mock.Bodies()[0]
+	for _, want := range []string{"checkVatApprox", "countryCode>DE<", "vatNumber>136695976<", "requesterCountryCode>FR<", "requesterVatNumber>40303265045<"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request body lacks %q:\n%s", want, body)
+		}
+	}
+
+	// Without a requester VIES issues no consultation number
+	result, err = client.CheckVAT(context.Background(), "FR40303265045")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequestIdentifier != "" || strings.Contains(mock.Bodies()[1], "checkVatApprox") {
+		t.Errorf("expected a plain checkVat without consultation number: %+v", result)
+	}
+
+	// A generated consultation number is parsed as well
+	result, err = client.CheckVAT(context.Background(), "FR40303265045", vies.WithRequester("DE136695976"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.RequestIdentifier, "WAPIMOCK") {
+		t.Errorf("RequestIdentifier = %q, want the generated WAPIMOCK number", result.RequestIdentifier)
+	}
+
+	if _, err := client.CheckVAT(context.Background(), "DE136695976", vies.WithRequester("XX123")); err == nil {
+		t.Error("expected an invalid requester to be rejected")
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	mock.SetValid("DE136695976", "Example GmbH", "Berlin")
+	mock.SetLatency(100 * time.Millisecond)
+	ctx := context.Background()
+
+	short := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithTimeout(20*time.Millisecond))
+	if _, err := short.CheckVAT(ctx, "DE136695976"); !errors.Is(err, vies.ErrNetworkTimeout) {
+		t.Errorf("expected the client timeout to expire, got %v", err)
+	}
+	if _, err := short.CheckVAT(ctx, "DE136695976", vies.WithRequestTimeout(5*time.Second)); err != nil {
+		t.Errorf("expected the longer per-call timeout to win: %v", err)
+	}
+
+	long := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithTimeout(5*time.Second))
+	if _, err := long.CheckVAT(ctx, "DE136695976", vies.WithRequestTimeout(20*time.Millisecond)); !errors.Is(err, vies.ErrNetworkTimeout) {
+		t.Errorf("expected the shorter per-call timeout to expire, got %v", err)
+	}
+}
+
+func TestWithRequestEndpoint(t *testing.T) {
+	primary, other := viesmock.New(), viesmock.New()
+	primarySrv := httptest.NewServer(primary.Handler())
+	defer primarySrv.Close()
+	otherSrv := httptest.NewServer(other.Handler())
+	defer otherSrv.Close()
+	other.SetValid("DE136695976", "Example GmbH", "Berlin")
+	client := vies.NewClient(vies.WithEndpoint(primarySrv.URL))
+
+	result, err := client.CheckVAT(context.Background(), "DE136695976", vies.WithRequestEndpoint(otherSrv.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || len(other.Bodies()) != 1 || len(primary.Bodies()) != 0 {
+		t.Errorf("expected the call on the per-call endpoint only, got %d/%d requests", len(other.Bodies()), len(primary.Bodies()))
+	}
+
+	if _, err := client.CheckVAT(context.Background(), "DE136695976"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(primary.Bodies()) != 1 {
+		t.Error("expected later calls to use the client endpoint again")
+	}
+}
+
+func TestClientDeadline(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	mock.SetValid("DE136695976", "Example GmbH", "Berlin")
+
+	// A deadline derived from a cancellable parent, as servers hand out
+	type ctxKey struct{}
+	derived := func() (context.Context, context.CancelFunc) {
+		parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+		ctx, cancel := context.WithTimeout(parent, 20*time.Millisecond)
+		return ctx, func() { cancel(); cancelParent() }
+	}
+
+	t.Run("rate limit wait", func(t *testing.T) {
+		client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithCountryRateLimit("DE", 0.01))
+		if _, err := client.CheckVAT(context.Background(), "DE136695976"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ctx, cancel := derived()
+		defer cancel()
+		if _, err := client.CheckVAT(ctx, "DE136695976"); !errors.Is(err, vies.ErrNetworkTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected a timeout while waiting for the rate limit, got %v", err)
+		}
+	})
+
+	t.Run("request", func(t *testing.T) {
+		mock.SetLatency(time.Second)
+		defer mock.SetLatency(0)
+		ctx, cancel := derived()
+		defer cancel()
+		_, err := vies.NewClient(vies.WithEndpoint(srv.URL)).CheckVAT(ctx, "DE136695976")
+		if !errors.Is(err, vies.ErrNetworkTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected a timeout of the HTTP request, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := vies.NewClient(vies.WithEndpoint(srv.URL)).CheckVAT(ctx, "DE136695976")
+		if err == nil || errors.Is(err, vies.ErrNetworkTimeout) {
+			t.Errorf("expected a cancellation that is not a timeout, got %v", err)
+		}
+	})
+}
+
+func TestNewRequest(t *testing.T) {
+	client := vies.NewClient(vies.WithEndpoint("https://vies.example/checkVatService"))
+
+	req, err := client.NewRequest(context.Background(), "DE136695976", vies.WithRequester("FR40303265045"))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://vies.example/checkVatService" {
+		t.Errorf("request = %s %s", req.Method, req.URL)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Envelope", "checkVatApprox", "countryCode>DE<", "vatNumber>136695976<", "requesterCountryCode>FR<"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("request body lacks %q:\n%s", want, body)
+		}
+	}
+
+	if _, err := client.NewRequest(context.Background(), "DE123"); err == nil {
+		t.Error("expected a malformed number to be rejected before building a request")
+	}
+}
+
+func TestResponseDumpRedact(t *testing.T) {
+	mock := viesmock.New()
+	srv := httptest.NewServer(mock.Handler())
+	defer srv.Close()
+	mock.SetValid("DE136695976", "Example GmbH", "Musterstraße 1, Berlin")
+
+	for _, redact := range []bool{false, true} {
+		var dump, logs strings.Builder
+		client := vies.NewClient(
+			vies.WithEndpoint(srv.URL),
+			vies.WithResponseDump(&dump),
+			vies.WithRedact(redact),
+			vies.WithVerbose(true),
+			vies.WithLogger(log.New(&logs, "", 0)),
+		)
+		if _, err := client.CheckVAT(context.Background(), "DE136695976"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(dump.String(), "HTTP/1.1 200 OK\r\n") || !strings.Contains(dump.String(), "checkVatResponse") {
+			t.Errorf("expected the raw response in the dump:\n%s", dump.String())
+		}
+		for name, text := range map[string]string{"dump": dump.String(), "log": logs.String()} {
+			leaked := strings.Contains(text, "Example GmbH") || strings.Contains(text, "Musterstraße")
+			if leaked == redact {
+				t.Errorf("redact = %t: trader data in the %s = %t:\n%s", redact, name, leaked, text)
+			}
+			if redact && !strings.Contains(text, vies.RedactedValue) {
+				t.Errorf("expected %s in the %s:\n%s", vies.RedactedValue, name, text)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package vies
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// checkVatSchema holds the request types of the VIES checkVatService WSDL
+//
+//go:embed checkVat.xsd
+var checkVatSchema []byte
+
+// xsdElement is the subset of an XSD element declaration used by VIES:
+// named elements with an optional sequence of child elements
+type xsdElement struct {
+	Name      string       `xml:"name,attr"`
+	MinOccurs string       `xml:"minOccurs,attr"`
+	Sequence  []xsdElement `xml:"complexType>sequence>element"`
+}
+
+var (
+	schemaOnce     sync.Once
+	schemaElements map[string]xsdElement
+)
+
+// loadSchema parses the embedded XSD into its top-level elements
+func loadSchema() map[string]xsdElement {
+	schemaOnce.Do(func() {
+		var schema struct {
+			Elements []xsdElement `xml:"element"`
+		}
+		if err := xml.Unmarshal(checkVatSchema, &schema); err != nil {
+			panic(fmt.Sprintf("vies: invalid embedded checkVat.xsd: %v", err))
+		}
+		schemaElements = make(map[string]xsdElement, len(schema.Elements))
+		for _, element := range schema.Elements {
+			schemaElements[element.Name] = element
+		}
+	})
+	return schemaElements
+}
+
+// xmlNode is a parsed element of a SOAP request
+type xmlNode struct {
+	Name     xml.Name
+	Children []*xmlNode
+}
+
+// ValidateEnvelope checks a SOAP request against the embedded checkVat
+// schema: a SOAP 1.1 or 1.2 envelope whose body holds exactly one checkVat
+// or checkVatApprox element, with the schema's child elements in order and
+// in the checkVat types namespace.
+func ValidateEnvelope(data []byte) error {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return fmt.Errorf("malformed envelope: %w", err)
+	}
+	if root.Name.Local != "Envelope" || (root.Name.Space != soap11Envelope && root.Name.Space != soap12Envelope) {
+		return fmt.Errorf("root element is {%s}%s, want a SOAP Envelope", root.Name.Space, root.Name.Local)
+	}
+
+	var body *xmlNode
+	for _, child := range root.Children {
+		if child.Name.Space != root.Name.Space {
+			return fmt.Errorf("envelope child {%s}%s is not in the SOAP envelope namespace", child.Name.Space, child.Name.Local)
+		}
+		if child.Name.Local == "Body" {
+			body = child
+		}
+	}
+	if body == nil {
+		return fmt.Errorf("envelope has no Body")
+	}
+	if len(body.Children) != 1 {
+		return fmt.Errorf("body has %d elements, want 1", len(body.Children))
+	}
+
+	operation := body.Children[0]
+	if operation.Name.Space != soapNamespace {
+		return fmt.Errorf("%s is in namespace %q, want %q", operation.Name.Local, operation.Name.Space, soapNamespace)
+	}
+	declaration, ok := loadSchema()[operation.Name.Local]
+	if !ok {
+		return fmt.Errorf("unknown operation %s", operation.Name.Local)
+	}
+	return validateSequence(operation, declaration.Sequence)
+}
+
+// validateSequence checks the children of node against an xsd:sequence
+func validateSequence(node *xmlNode, sequence []xsdElement) error {
+	i := 0
+	for _, expected := range sequence {
+		if i < len(node.Children) && node.Children[i].Name.Local == expected.Name {
+			child := node.Children[i]
+			if child.Name.Space != soapNamespace {
+				return fmt.Errorf("%s/%s is in namespace %q, want %q", node.Name.Local, child.Name.Local, child.Name.Space, soapNamespace)
+			}
+			if len(child.Children) > 0 {
+				return fmt.Errorf("%s/%s must hold text only", node.Name.Local, child.Name.Local)
+			}
+			i++
+			continue
+		}
+		if expected.MinOccurs != "0" {
+			return fmt.Errorf("%s is missing required element %s", node.Name.Local, expected.Name)
+		}
+	}
+	if i < len(node.Children) {
+		return fmt.Errorf("%s has unexpected element %s", node.Name.Local, node.Children[i].Name.Local)
+	}
+	return nil
+}
+
+// parseXMLTree decodes a document into its element structure
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("multiple root elements")
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return root, nil
+}
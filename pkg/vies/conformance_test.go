@@ -0,0 +1,43 @@
+package vies
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedEnvelopesConform(t *testing.T) {
+	envelopes := map[string]*SOAPEnvelope{
+		"checkVat 1.1":       createSOAPRequest(soap11Envelope, "DE", "266201128"),
+		"checkVat 1.2":       createSOAPRequest(soap12Envelope, "DE", "266201128"),
+		"checkVatApprox 1.1": createSOAPApproxRequest(soap11Envelope, "DE", "266201128", "DE", "136695976"),
+		"checkVatApprox 1.2": createSOAPApproxRequest(soap12Envelope, "DE", "266201128", "DE", "136695976"),
+	}
+	for name, envelope := range envelopes {
+		data, err := xml.Marshal(envelope)
+		if err != nil {
+			t.Fatalf("%s: marshal failed: %v", name, err)
+		}
+		if err := ValidateEnvelope([]byte(xml.Header + string(data))); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestValidateEnvelopeRejects(t *testing.T) {
+	const ns = `xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" xmlns:urn="urn:ec.europa.eu:taxud:vies:services:checkVat:types"`
+	tests := map[string]string{
+		"unqualified operation": `<s:Envelope ` + ns + `><s:Body><checkVat><urn:countryCode>DE</urn:countryCode><urn:vatNumber>1</urn:vatNumber></checkVat></s:Body></s:Envelope>`,
+		"unqualified child":     `<s:Envelope ` + ns + `><s:Body><urn:checkVat><countryCode>DE</countryCode><urn:vatNumber>1</urn:vatNumber></urn:checkVat></s:Body></s:Envelope>`,
+		"wrong order":           `<s:Envelope ` + ns + `><s:Body><urn:checkVat><urn:vatNumber>1</urn:vatNumber><urn:countryCode>DE</urn:countryCode></urn:checkVat></s:Body></s:Envelope>`,
+		"missing element":       `<s:Envelope ` + ns + `><s:Body><urn:checkVat><urn:countryCode>DE</urn:countryCode></urn:checkVat></s:Body></s:Envelope>`,
+		"unknown operation":     `<s:Envelope ` + ns + `><s:Body><urn:checkTin/></s:Body></s:Envelope>`,
+		"no envelope":           `<urn:checkVat ` + ns + `/>`,
+		"unbound prefix":        `<soapenv:Envelope><soapenv:Body/></soapenv:Envelope>`,
+	}
+	for name, doc := range tests {
+		if err := ValidateEnvelope([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error for %s", name, strings.TrimSpace(doc))
+		}
+	}
+}
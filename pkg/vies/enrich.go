@@ -0,0 +1,40 @@
+package vies
+
+import "context"
+
+// Enricher attaches additional data to successful results, such as company
+// registry details or geocoded addresses. The returned entries are merged
+// into CheckVatResult.Extensions, which the output formatters include
+// without knowing their meaning.
+type Enricher interface {
+	Enrich(ctx context.Context, result *CheckVatResult) (map[string]any, error)
+}
+
+// EnricherFunc adapts a function to the Enricher interface
+type EnricherFunc func(ctx context.Context, result *CheckVatResult) (map[string]any, error)
+
+// Enrich implements Enricher
+func (f EnricherFunc) Enrich(ctx context.Context, result *CheckVatResult) (map[string]any, error) {
+	return f(ctx, result)
+}
+
+// enrich runs the configured enrichers in order; later entries replace
+// earlier ones with the same key. Enricher failures do not fail the check:
+// they are logged in verbose mode and their entries are left out.
+func (c *Client) enrich(ctx context.Context, result *CheckVatResult) {
+	for _, enricher := range c.enrichers {
+		extensions, err := enricher.Enrich(ctx, result)
+		if err != nil {
+			if c.verbose {
+				c.logger.Printf("Enrichment failed: %v", err)
+			}
+			continue
+		}
+		for key, value := range extensions {
+			if result.Extensions == nil {
+				result.Extensions = make(map[string]any, len(extensions))
+			}
+			result.Extensions[key] = value
+		}
+	}
+}
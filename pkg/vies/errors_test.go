@@ -0,0 +1,69 @@
+package vies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	_, _, err := ParseVATNumber("DE12345")
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+	if errors.Is(err, ErrUnsupportedCountry) {
+		t.Error("format error must not match ErrUnsupportedCountry")
+	}
+
+	_, _, err = ParseVATNumber("XX123456789")
+	if !errors.Is(err, ErrUnsupportedCountry) {
+		t.Errorf("expected ErrUnsupportedCountry, got %v", err)
+	}
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Code != CodeUnsupportedCountry {
+		t.Errorf("expected *ValidationError with code %s, got %v", CodeUnsupportedCountry, err)
+	}
+}
+
+func TestServiceErrorWrapping(t *testing.T) {
+	err := fmt.Errorf("lookup failed: %w", &ServiceError{
+		Code:    CodeNetworkTimeout,
+		Message: "Request timeout exceeded",
+		Err:     context.DeadlineExceeded,
+	})
+
+	if !errors.Is(err, ErrNetworkTimeout) {
+		t.Error("expected wrapped ServiceError to match ErrNetworkTimeout")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected wrapped ServiceError to expose its cause")
+	}
+	if errors.Is(err, ErrServiceUnavailable) {
+		t.Error("timeout must not match ErrServiceUnavailable")
+	}
+}
+
+func TestCheckRecovered(t *testing.T) {
+	// A nil result dereference, like a bug triggered by a malformed response
+	checker := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		var result *CheckVatResult
+		return &CheckVatResult{Name: result.Name}, nil
+	})
+	result, err := CheckRecovered(context.Background(), checker, "DE266201128")
+	if result != nil || !errors.Is(err, ErrInternal) {
+		t.Fatalf("CheckRecovered() = %v, %v; want ErrInternal", result, err)
+	}
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != CodeInternalError || serviceErr.VATNumber != "DE266201128" {
+		t.Errorf("expected a ServiceError with code %s, got %#v", CodeInternalError, err)
+	}
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Errorf("expected the panic and its stack to be kept, got %v", err)
+	}
+	if _, ok := LookupError(CodeInternalError); !ok {
+		t.Errorf("%s is missing from the error catalog", CodeInternalError)
+	}
+}
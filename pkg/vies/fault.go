@@ -0,0 +1,87 @@
+package vies
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Element names that member state payloads commonly use for their own error
+// code and message
+var (
+	faultDetailCodeNames    = []string{"code", "errorCode", "faultCode", "returnCode"}
+	faultDetailMessageNames = []string{"message", "errorMessage", "description", "reason", "text"}
+)
+
+// UnmarshalXML flattens the detail element into its leaf fields
+func (d *FaultDetail) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	type element struct {
+		path     string
+		text     strings.Builder
+		children bool
+	}
+	stack := []*element{{}}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			parent := stack[len(stack)-1]
+			parent.children = true
+			path := t.Name.Local
+			if parent.path != "" {
+				path = parent.path + "/" + path
+			}
+			stack = append(stack, &element{path: path})
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+		case xml.EndElement:
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			value := strings.TrimSpace(current.text.String())
+			if !current.children && (value != "" || current.path != "") {
+				d.Fields = append(d.Fields, FaultDetailField{Path: current.path, Value: value})
+			}
+			if len(stack) == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// Get returns the value of the first field with the given path
+func (d *FaultDetail) Get(path string) string {
+	for _, field := range d.Fields {
+		if field.Path == path {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Code returns the member state's error code, if the payload has a leaf
+// element with a common name for it such as "errorCode"
+func (d *FaultDetail) Code() string {
+	return d.lookup(faultDetailCodeNames)
+}
+
+// Message returns the member state's error message, if the payload has a
+// leaf element with a common name for it such as "message"
+func (d *FaultDetail) Message() string {
+	return d.lookup(faultDetailMessageNames)
+}
+
+// lookup returns the first non-empty field whose element name is one of
+// names, compared case-insensitively
+func (d *FaultDetail) lookup(names []string) string {
+	for _, field := range d.Fields {
+		name := field.Path[strings.LastIndex(field.Path, "/")+1:]
+		for _, candidate := range names {
+			if strings.EqualFold(name, candidate) && field.Value != "" {
+				return field.Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package vies
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSOAPFaultDetail(t *testing.T) {
+	soap11 := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>
+<faultcode>soap:Server</faultcode><faultstring>MS_UNAVAILABLE</faultstring>
+<detail><ms:error xmlns:ms="urn:example:ms"><ms:errorCode> E042 </ms:errorCode><ms:message>Back-end maintenance</ms:message><ms:empty/></ms:error></detail>
+</soap:Fault></soap:Body></soap:Envelope>`
+	_, err := ParseSOAPResponse([]byte(soap11))
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.FaultCode != "MS_UNAVAILABLE" {
+		t.Fatalf("ParseSOAPResponse() error = %v, want the MS_UNAVAILABLE fault", err)
+	}
+	detail := serviceErr.FaultDetail
+	if detail == nil {
+		t.Fatal("expected a parsed fault detail")
+	}
+	want := []FaultDetailField{
+		{Path: "error/errorCode", Value: "E042"},
+		{Path: "error/message", Value: "Back-end maintenance"},
+		{Path: "error/empty", Value: ""},
+	}
+	if !reflect.DeepEqual(detail.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", detail.Fields, want)
+	}
+	if detail.Code() != "E042" || detail.Message() != "Back-end maintenance" || detail.Get("error/empty") != "" {
+		t.Errorf("Code() = %q, Message() = %q", detail.Code(), detail.Message())
+	}
+
+	soap12 := `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>
+<env:Code><env:Value>env:Receiver</env:Value></env:Code><env:Reason><env:Text xml:lang="en">TIMEOUT</env:Text></env:Reason>
+<env:Detail>member state did not answer</env:Detail>
+</env:Fault></env:Body></env:Envelope>`
+	_, err = ParseSOAPResponse([]byte(soap12))
+	if !errors.As(err, &serviceErr) || serviceErr.FaultDetail == nil {
+		t.Fatalf("ParseSOAPResponse() error = %v, want a fault with detail", err)
+	}
+	if got := serviceErr.FaultDetail.Fields; len(got) != 1 || got[0] != (FaultDetailField{Value: "member state did not answer"}) {
+		t.Errorf("text-only detail parsed as %+v", got)
+	}
+
+	noDetail := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>
+<faultcode>soap:Server</faultcode><faultstring>INVALID_INPUT</faultstring><detail/></soap:Fault></soap:Body></soap:Envelope>`
+	_, err = ParseSOAPResponse([]byte(noDetail))
+	if !errors.As(err, &serviceErr) || serviceErr.FaultDetail != nil {
+		t.Errorf("expected no fault detail for an empty <detail/>, got %+v", serviceErr.FaultDetail)
+	}
+}
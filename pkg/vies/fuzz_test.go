@@ -0,0 +1,48 @@
+package vies
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseSOAPResponse(f *testing.F) {
+	f.Add([]byte(`<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types"><ns2:countryCode>DE</ns2:countryCode><ns2:vatNumber>266201128</ns2:vatNumber><ns2:requestDate>2025-01-09+01:00</ns2:requestDate><ns2:valid>true</ns2:valid><ns2:name>Example GmbH</ns2:name><ns2:address>Berlin</ns2:address></ns2:checkVatResponse></env:Body></env:Envelope>`))
+	f.Add([]byte(`<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><env:Fault><faultcode>env:Server</faultcode><faultstring>MS_UNAVAILABLE</faultstring></env:Fault></env:Body></env:Envelope>`))
+	f.Add([]byte(`<Envelope><Body><checkVatApproxResponse><requestDate>2025-01-09</requestDate><requestIdentifier>WAPI</requestIdentifier></checkVatApproxResponse></Body></Envelope>`))
+	f.Add([]byte(`<Envelope><Body/></Envelope>`))
+	f.Add([]byte(``))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		result, err := ParseSOAPResponse(data)
+		if (result == nil) == (err == nil) {
+			t.Fatalf("expected exactly one of result and error, got %v, %v", result, err)
+		}
+		if err != nil {
+			if _, ok := err.(*ServiceError); !ok {
+				t.Fatalf("expected *ServiceError, got %T", err)
+			}
+		}
+	})
+}
+
+func FuzzValidateAndNormalize(f *testing.F) {
+	for _, seed := range []string{"DE266201128", "atu 12345678", "GR123456789", "FR12345678901", "NL123456789B01", "DE", "", "ÄÖ123"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		normalized, err := ValidateAndNormalize(input)
+		if err != nil {
+			if _, ok := err.(*ValidationError); !ok {
+				t.Fatalf("expected *ValidationError, got %T", err)
+			}
+			return
+		}
+		if normalized != strings.ToUpper(normalized) || strings.ContainsAny(normalized, " \t") {
+			t.Fatalf("normalized form %q is not canonical", normalized)
+		}
+		if _, ok := countryValidators[normalized[:2]]; !ok {
+			t.Fatalf("normalized form %q has unknown country code", normalized)
+		}
+	})
+}
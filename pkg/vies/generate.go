@@ -0,0 +1,94 @@
+package vies
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxGenerateAttempts bounds the random draws per generated number. The
+// least likely check-digit scheme (Belgium, mod 97) accepts about one draw
+// in a hundred.
+const maxGenerateAttempts = 10000
+
+// GenerateVATNumber returns a random VAT number of the given member state
+// that passes ValidateFormat, check digits included. The numbers are meant
+// for load tests and fixtures: they are not taken from any register, but
+// may coincide with a number that has been issued. GR is generated as EL.
+func GenerateVATNumber(countryCode string, rng *rand.Rand) (string, error) {
+	countryCode = strings.ToUpper(countryCode)
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	validator, ok := countryValidators[countryCode]
+	if !ok {
+		return "", &ValidationError{
+			Code:    CodeUnsupportedCountry,
+			Message: fmt.Sprintf("Unsupported country code: %s", countryCode),
+		}
+	}
+	re, err := syntax.Parse(validator.Pattern.String(), syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		b.Reset()
+		generateMatch(&b, re, rng)
+		if candidate := b.String(); validateFormat(candidate) == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no valid %s VAT number found in %d attempts", validator.Name, maxGenerateAttempts)
+}
+
+// generateMatch writes a random string matched by re. It supports the
+// constructs used by the country patterns: literals, character classes,
+// repetition, alternation and anchors.
+func generateMatch(b *strings.Builder, re *syntax.Regexp, rng *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		// re.Rune holds inclusive ranges as pairs
+		size := 0
+		for i := 0; i < len(re.Rune); i += 2 {
+			size += int(re.Rune[i+1]-re.Rune[i]) + 1
+		}
+		n := rng.IntN(size)
+		for i := 0; i < len(re.Rune); i += 2 {
+			width := int(re.Rune[i+1]-re.Rune[i]) + 1
+			if n < width {
+				b.WriteRune(re.Rune[i] + rune(n))
+				break
+			}
+			n -= width
+		}
+	case syntax.OpCapture:
+		generateMatch(b, re.Sub[0], rng)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateMatch(b, sub, rng)
+		}
+	case syntax.OpAlternate:
+		generateMatch(b, re.Sub[rng.IntN(len(re.Sub))], rng)
+	case syntax.OpRepeat, syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, 1
+		case syntax.OpPlus:
+			lo, hi = 1, 2
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < lo {
+			hi = lo // unbounded repetition such as {2,}
+		}
+		for n := lo + rng.IntN(hi-lo+1); n > 0; n-- {
+			generateMatch(b, re.Sub[0], rng)
+		}
+	}
+}
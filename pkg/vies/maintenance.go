@@ -0,0 +1,137 @@
+package vies
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a daily period during which a member state service,
+// or VIES as a whole, is known to be unavailable
+type MaintenanceWindow struct {
+	// CountryCode is the member state; empty for all of them
+	CountryCode string
+	// Start and End are offsets from local midnight. A window with End
+	// before Start spans midnight, e.g. 23:00-05:00.
+	Start, End time.Duration
+	// Location is the time zone of Start and End; UTC if nil
+	Location *time.Location
+}
+
+// MaintenanceSchedule is a list of known maintenance windows. VIES does not
+// publish the member states' windows in a machine-readable form, so the
+// schedule is supplied by the user, e.g. from the availability notices on
+// the VIES website.
+type MaintenanceSchedule []MaintenanceWindow
+
+// ParseMaintenanceSchedule parses a comma-separated list of windows in the
+// form COUNTRY=HH:MM-HH:MM[@ZONE], e.g. "DE=23:00-05:00@Europe/Berlin". The
+// country "*" matches all member states; GR is read as EL.
+func ParseMaintenanceSchedule(s string) (MaintenanceSchedule, error) {
+	var schedule MaintenanceSchedule
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		window, err := parseMaintenanceWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window '%s': %w", item, err)
+		}
+		schedule = append(schedule, window)
+	}
+	return schedule, nil
+}
+
+// parseMaintenanceWindow parses a single COUNTRY=HH:MM-HH:MM[@ZONE] entry
+func parseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	country, span, ok := strings.Cut(s, "=")
+	if !ok {
+		return MaintenanceWindow{}, fmt.Errorf("expected COUNTRY=HH:MM-HH:MM[@ZONE]")
+	}
+	var window MaintenanceWindow
+	switch country = strings.ToUpper(strings.TrimSpace(country)); country {
+	case "*":
+	case "GR":
+		window.CountryCode = "EL"
+	default:
+		if _, known := countryValidators[country]; !known {
+			return MaintenanceWindow{}, fmt.Errorf("unsupported country code %s", country)
+		}
+		window.CountryCode = country
+	}
+
+	span, zone, hasZone := strings.Cut(span, "@")
+	if hasZone {
+		loc, err := time.LoadLocation(strings.TrimSpace(zone))
+		if err != nil {
+			return MaintenanceWindow{}, err
+		}
+		window.Location = loc
+	}
+	start, end, ok := strings.Cut(span, "-")
+	if !ok {
+		return MaintenanceWindow{}, fmt.Errorf("expected a time range such as 23:00-05:00")
+	}
+	var err error
+	if window.Start, err = parseTimeOfDay(start); err != nil {
+		return MaintenanceWindow{}, err
+	}
+	if window.End, err = parseTimeOfDay(end); err != nil {
+		return MaintenanceWindow{}, err
+	}
+	if window.Start == window.End {
+		return MaintenanceWindow{}, fmt.Errorf("empty time range")
+	}
+	return window, nil
+}
+
+// parseTimeOfDay parses HH:MM into an offset from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", strings.TrimSpace(s))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// EndAfter reports whether t falls within the window and, if so, when the
+// window ends
+func (w MaintenanceWindow) EndAfter(t time.Time) (time.Time, bool) {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	offset := local.Sub(midnight)
+
+	switch {
+	case w.Start < w.End && offset >= w.Start && offset < w.End:
+		return midnight.Add(w.End), true
+	case w.Start > w.End && offset >= w.Start:
+		return midnight.AddDate(0, 0, 1).Add(w.End), true
+	case w.Start > w.End && offset < w.End:
+		return midnight.Add(w.End), true
+	}
+	return time.Time{}, false
+}
+
+// Active reports whether the service of a member state is within one of
+// the schedule's windows at t and, if so, when the last overlapping window
+// ends
+func (s MaintenanceSchedule) Active(countryCode string, t time.Time) (time.Time, bool) {
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	var end time.Time
+	for _, window := range s {
+		if window.CountryCode != "" && window.CountryCode != countryCode {
+			continue
+		}
+		if windowEnd, ok := window.EndAfter(t); ok && windowEnd.After(end) {
+			end = windowEnd
+		}
+	}
+	return end, !end.IsZero()
+}
@@ -0,0 +1,89 @@
+package vies
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseMaintenanceSchedule(t *testing.T) {
+	schedule, err := ParseMaintenanceSchedule("DE=23:00-05:00@Europe/Berlin, gr=01:00-02:30, *=12:00-12:15")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schedule) != 3 {
+		t.Fatalf("expected 3 windows, got %+v", schedule)
+	}
+	if w := schedule[0]; w.CountryCode != "DE" || w.Start != 23*time.Hour || w.End != 5*time.Hour || w.Location.String() != "Europe/Berlin" {
+		t.Errorf("unexpected first window: %+v", w)
+	}
+	if w := schedule[1]; w.CountryCode != "EL" || w.End != 2*time.Hour+30*time.Minute || w.Location != nil {
+		t.Errorf("unexpected second window: %+v", w)
+	}
+	if w := schedule[2]; w.CountryCode != "" {
+		t.Errorf("expected * to match all countries, got %+v", w)
+	}
+
+	for _, input := range []string{"DE", "DE=23:00", "XX=01:00-02:00", "DE=25:00-02:00", "DE=01:00-01:00", "DE=01:00-02:00@Mars/Base"} {
+		if _, err := ParseMaintenanceSchedule(input); err == nil {
+			t.Errorf("ParseMaintenanceSchedule(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestMaintenanceScheduleActive(t *testing.T) {
+	schedule, err := ParseMaintenanceSchedule("DE=23:00-05:00@Europe/Berlin,FR=10:00-11:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		country string
+		at      time.Time
+		end     time.Time
+	}{
+		{"DE", time.Date(2025, 3, 10, 23, 30, 0, 0, berlin), time.Date(2025, 3, 11, 5, 0, 0, 0, berlin)},
+		{"DE", time.Date(2025, 3, 11, 4, 59, 0, 0, berlin), time.Date(2025, 3, 11, 5, 0, 0, 0, berlin)},
+		{"DE", time.Date(2025, 3, 11, 5, 0, 0, 0, berlin), time.Time{}},
+		{"DE", time.Date(2025, 3, 10, 12, 0, 0, 0, berlin), time.Time{}},
+		{"FR", time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC), time.Date(2025, 3, 10, 11, 0, 0, 0, time.UTC)},
+		{"IT", time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC), time.Time{}},
+	}
+	for _, tt := range tests {
+		end, ok := schedule.Active(tt.country, tt.at)
+		if ok != !tt.end.IsZero() || !end.Equal(tt.end) {
+			t.Errorf("Active(%s, %v) = %v, %t; want %v", tt.country, tt.at, end, ok, tt.end)
+		}
+	}
+}
+
+func TestServiceWaiterMaintenance(t *testing.T) {
+	defer func(initial time.Duration) { waitInitialBackoff = initial }(waitInitialBackoff)
+	waitInitialBackoff = time.Millisecond
+
+	calls := 0
+	unavailable := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		calls++
+		return nil, &ServiceError{Code: CodeSOAPFault, FaultCode: "MS_UNAVAILABLE"}
+	})
+	// Two windows covering the whole day: the wait until the end of the
+	// current one exceeds MaxWait, so the outage is reported at once
+	allDay, _ := ParseMaintenanceSchedule("DE=00:00-12:00,DE=12:00-00:00")
+	waiter := &ServiceWaiter{Checker: unavailable, MaxWait: time.Second, Maintenance: allDay}
+	if _, err := waiter.CheckVAT(context.Background(), "DE266201128"); err == nil || calls != 1 {
+		t.Errorf("CheckVAT() = %v after %d calls, want the outage after one call", err, calls)
+	}
+}
+
+func TestServiceWaiterMaintenanceDefaultCountry(t *testing.T) {
+	// Numbers without a prefix are checked with the client's default
+	// country, so its maintenance windows apply
+	waiter := &ServiceWaiter{Checker: NewClient(WithDefaultCountry("IT"))}
+	if got := waiter.maintenanceCountry("12345670017"); got != "IT" {
+		t.Errorf("maintenanceCountry() = %q, want IT", got)
+	}
+	waiter = &ServiceWaiter{Checker: checkerFunc(nil)}
+	if got := waiter.maintenanceCountry("de 266201128"); got != "DE" {
+		t.Errorf("maintenanceCountry() = %q, want DE", got)
+	}
+}
@@ -0,0 +1,62 @@
+package vies
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter paces requests per member state. Member state backends
+// throttle independently of the VIES gateway, so every country gets its own
+// schedule.
+type rateLimiter struct {
+	mu          sync.Mutex
+	defaultRate float64            // requests per second, 0 for unlimited
+	rates       map[string]float64 // per-country overrides
+	next        map[string]time.Time
+}
+
+func newRateLimiter(defaultRate float64, rates map[string]float64) *rateLimiter {
+	return &rateLimiter{
+		defaultRate: defaultRate,
+		rates:       rates,
+		next:        make(map[string]time.Time),
+	}
+}
+
+// wait blocks until a request to countryCode may be sent
+func (l *rateLimiter) wait(ctx context.Context, countryCode string) error {
+	delay := l.reserve(countryCode, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve claims the next request slot for countryCode and returns how long
+// to wait for it
+func (l *rateLimiter) reserve(countryCode string, now time.Time) time.Duration {
+	rate, ok := l.rates[countryCode]
+	if !ok {
+		rate = l.defaultRate
+	}
+	if rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slot := l.next[countryCode]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[countryCode] = slot.Add(time.Duration(float64(time.Second) / rate))
+	return slot.Sub(now)
+}
@@ -0,0 +1,34 @@
+package vies
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPacesCountriesIndependently(t *testing.T) {
+	limiter := newRateLimiter(2, map[string]float64{"FR": 1, "LU": 0})
+	now := time.Date(2025, 1, 9, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		country string
+		want    time.Duration
+	}{
+		{"DE", 0},
+		{"DE", 500 * time.Millisecond},
+		{"DE", time.Second},
+		{"FR", 0},
+		{"FR", time.Second},
+		{"LU", 0},
+		{"LU", 0},
+	}
+	for _, tt := range tests {
+		if got := limiter.reserve(tt.country, now); got != tt.want {
+			t.Errorf("reserve(%s) = %v, want %v", tt.country, got, tt.want)
+		}
+	}
+
+	// Slots do not accumulate while a country is idle
+	if got := limiter.reserve("DE", now.Add(time.Minute)); got != 0 {
+		t.Errorf("reserve after idle period = %v, want 0", got)
+	}
+}
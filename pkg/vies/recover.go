@@ -0,0 +1,36 @@
+package vies
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the cause of a CodeInternalError: a panic recovered while
+// checking a VAT number, with the stack of the panicking goroutine
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// CheckRecovered calls checker.CheckVAT and turns a panic into a
+// ServiceError with CodeInternalError, so a bug triggered by one malformed
+// response cannot take down a server or a whole batch run. The stack of the
+// panic is kept in the PanicError the ServiceError wraps.
+func CheckRecovered(ctx context.Context, checker Checker, vatNumber string, options ...RequestOption) (result *CheckVatResult, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			result, err = nil, &ServiceError{
+				Code:      CodeInternalError,
+				Message:   fmt.Sprintf("Internal error while checking %s: %v", vatNumber, value),
+				VATNumber: vatNumber,
+				Err:       &PanicError{Value: value, Stack: debug.Stack()},
+			}
+		}
+	}()
+	return checker.CheckVAT(ctx, vatNumber, options...)
+}
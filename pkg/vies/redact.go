@@ -0,0 +1,74 @@
+package vies
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces trader names and addresses when redaction is enabled
+const RedactedValue = "[REDACTED]"
+
+// traderDataPattern matches trader name and address elements in SOAP payloads
+var traderDataPattern = regexp.MustCompile(`(?s)(<(?:[\w-]+:)?(?:name|address|traderName|traderAddress|traderStreet|traderPostcode|traderCity)>)(.*?)(</(?:[\w-]+:)?(?:name|address|traderName|traderAddress|traderStreet|traderPostcode|traderCity)>)`)
+
+// faultDetailPattern matches the member state payload of a SOAP 1.1 or 1.2
+// fault, which may echo the trader data of the request
+var faultDetailPattern = regexp.MustCompile(`(?s)(<(?:[\w-]+:)?[dD]etail(?:\s[^>]*)?>)(.*?)(</(?:[\w-]+:)?[dD]etail>)`)
+
+// RedactPayload masks trader names and addresses, and the fault detail, in a
+// raw SOAP payload
+func RedactPayload(body []byte) []byte {
+	body = traderDataPattern.ReplaceAll(body, []byte("${1}"+RedactedValue+"${3}"))
+	return faultDetailPattern.ReplaceAll(body, []byte("${1}"+RedactedValue+"${3}"))
+}
+
+// Redact returns a copy of the result with personal data masked, keeping
+// only the validity information. Enricher extensions keep their keys but
+// have their values masked.
+func Redact(result *CheckVatResult) *CheckVatResult {
+	if result == nil {
+		return nil
+	}
+	redacted := *result
+	if redacted.Name != "" {
+		redacted.Name = RedactedValue
+	}
+	if redacted.Address != "" {
+		redacted.Address = RedactedValue
+	}
+	if result.Extensions != nil {
+		redacted.Extensions = make(map[string]any, len(result.Extensions))
+		for key := range result.Extensions {
+			redacted.Extensions[key] = RedactedValue
+		}
+	}
+	return &redacted
+}
+
+// RedactFaultDetail returns a copy of the fault detail with the field values
+// masked, except for error codes (see FaultDetail.Code), which carry no
+// trader data
+func RedactFaultDetail(detail *FaultDetail) *FaultDetail {
+	if detail == nil {
+		return nil
+	}
+	redacted := &FaultDetail{Fields: make([]FaultDetailField, len(detail.Fields))}
+	for i, field := range detail.Fields {
+		redacted.Fields[i] = field
+		if !isFaultDetailCode(field.Path) && field.Value != "" {
+			redacted.Fields[i].Value = RedactedValue
+		}
+	}
+	return redacted
+}
+
+// isFaultDetailCode reports whether the field path names an error code
+func isFaultDetailCode(path string) bool {
+	name := path[strings.LastIndex(path, "/")+1:]
+	for _, candidate := range faultDetailCodeNames {
+		if strings.EqualFold(name, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package vies
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	result := &CheckVatResult{
+		CountryCode: "DE",
+		VatNumber:   "136695976",
+		Valid:       true,
+		Name:        "Example GmbH",
+		Address:     "Musterstraße 1, 10115 Berlin",
+		Extensions: map[string]any{
+			"registry": map[string]any{"name": "Example GmbH"},
+			"director": "Erika Mustermann",
+		},
+	}
+	redacted := Redact(result)
+
+	if redacted.Name != RedactedValue || redacted.Address != RedactedValue {
+		t.Errorf("Name = %q, Address = %q, want both redacted", redacted.Name, redacted.Address)
+	}
+	if redacted.CountryCode != "DE" || redacted.VatNumber != "136695976" || !redacted.Valid {
+		t.Errorf("validity information changed: %+v", redacted)
+	}
+	want := map[string]any{"registry": RedactedValue, "director": RedactedValue}
+	if !reflect.DeepEqual(redacted.Extensions, want) {
+		t.Errorf("Extensions = %v, want %v", redacted.Extensions, want)
+	}
+	if result.Name != "Example GmbH" || result.Extensions["director"] != "Erika Mustermann" {
+		t.Error("Redact modified the original result")
+	}
+
+	empty := Redact(&CheckVatResult{CountryCode: "DE", VatNumber: "136695976"})
+	if empty.Name != "" || empty.Address != "" || empty.Extensions != nil {
+		t.Errorf("Redact() of a result without trader data = %+v", empty)
+	}
+	if Redact(nil) != nil {
+		t.Error("Redact(nil) should be nil")
+	}
+}
+
+func TestRedactPayload(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{
+			"checkVat",
+			`<ns2:checkVatResponse><ns2:valid>true</ns2:valid><ns2:name>Example GmbH</ns2:name><ns2:address>Musterstraße 1
+10115 Berlin</ns2:address></ns2:checkVatResponse>`,
+			`<ns2:checkVatResponse><ns2:valid>true</ns2:valid><ns2:name>[REDACTED]</ns2:name><ns2:address>[REDACTED]</ns2:address></ns2:checkVatResponse>`,
+		},
+		{
+			"checkVatApprox",
+			`<checkVatApproxResponse><traderName>Example</traderName><traderStreet>Musterstraße 1</traderStreet><traderPostcode>10115</traderPostcode><traderCity>Berlin</traderCity><requestIdentifier>WAPIAAAA</requestIdentifier></checkVatApproxResponse>`,
+			`<checkVatApproxResponse><traderName>[REDACTED]</traderName><traderStreet>[REDACTED]</traderStreet><traderPostcode>[REDACTED]</traderPostcode><traderCity>[REDACTED]</traderCity><requestIdentifier>WAPIAAAA</requestIdentifier></checkVatApproxResponse>`,
+		},
+		{
+			"SOAP 1.1 fault",
+			`<soap:Fault><faultstring>MS_UNAVAILABLE</faultstring><detail><ms:error><ms:trader>Example GmbH</ms:trader></ms:error></detail></soap:Fault>`,
+			`<soap:Fault><faultstring>MS_UNAVAILABLE</faultstring><detail>[REDACTED]</detail></soap:Fault>`,
+		},
+		{
+			"SOAP 1.2 fault",
+			`<env:Fault><env:Reason><env:Text>TIMEOUT</env:Text></env:Reason><env:Detail xml:lang="en">Example GmbH</env:Detail></env:Fault>`,
+			`<env:Fault><env:Reason><env:Text>TIMEOUT</env:Text></env:Reason><env:Detail xml:lang="en">[REDACTED]</env:Detail></env:Fault>`,
+		},
+		{
+			"no trader data",
+			`<ns2:checkVatResponse><ns2:valid>false</ns2:valid></ns2:checkVatResponse>`,
+			`<ns2:checkVatResponse><ns2:valid>false</ns2:valid></ns2:checkVatResponse>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RedactPayload([]byte(tt.body))); got != tt.want {
+				t.Errorf("RedactPayload() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactFaultDetail(t *testing.T) {
+	detail := &FaultDetail{Fields: []FaultDetailField{
+		{Path: "error/errorCode", Value: "E042"},
+		{Path: "error/message", Value: "No match for Example GmbH"},
+		{Path: "error/empty", Value: ""},
+	}}
+	want := []FaultDetailField{
+		{Path: "error/errorCode", Value: "E042"},
+		{Path: "error/message", Value: RedactedValue},
+		{Path: "error/empty", Value: ""},
+	}
+	if got := RedactFaultDetail(detail).Fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactFaultDetail() = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(detail.Fields[1].Value, "Example") {
+		t.Error("RedactFaultDetail modified the original detail")
+	}
+	if RedactFaultDetail(nil) != nil {
+		t.Error("RedactFaultDetail(nil) should be nil")
+	}
+}
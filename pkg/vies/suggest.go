@@ -0,0 +1,64 @@
+package vies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// confusables maps letters commonly typed in place of digits
+var confusables = strings.NewReplacer("O", "0", "Q", "0", "I", "1", "L", "1")
+
+// suggestCorrections returns likely corrections for a normalized VAT number
+// that failed format validation, and a hint explaining the likely mistake.
+// Suggested numbers always pass the format and checksum rules of their country.
+func suggestCorrections(vatNumber string) ([]string, string) {
+	if len(vatNumber) < 2 {
+		return nil, ""
+	}
+	countryCode, number := vatNumber[:2], vatNumber[2:]
+
+	validator, exists := countryValidators[countryCode]
+	if !exists {
+		if countryCode == "GB" {
+			return nil, "GB numbers are no longer in VIES; Northern Ireland traders use the XI prefix, which is not supported"
+		}
+		return nil, ""
+	}
+
+	var suggestions []string
+	add := func(candidate string) {
+		if candidate == vatNumber || !validator.Pattern.MatchString(candidate) || !validChecksum(countryCode, candidate[2:]) {
+			return
+		}
+		for _, s := range suggestions {
+			if s == candidate {
+				return
+			}
+		}
+		suggestions = append(suggestions, candidate)
+	}
+
+	// Letters typed in place of digits (O vs 0, I vs 1)
+	add(countryCode + confusables.Replace(number))
+
+	switch countryCode {
+	case "AT":
+		// Austrian numbers carry a "U" before the digits
+		add("ATU" + number)
+		add("ATU" + confusables.Replace(number))
+	case "NL":
+		// Dutch numbers have a "B" before the last two digits
+		if len(number) == 11 {
+			add("NL" + number[:9] + "B" + number[9:])
+		}
+	}
+
+	var hint string
+	switch {
+	case len(vatNumber) == validator.MinLength-1:
+		hint = fmt.Sprintf("The number is one character too short (expected %s)", validator.Description)
+	case len(vatNumber) == validator.MaxLength+1:
+		hint = fmt.Sprintf("The number is one character too long (expected %s)", validator.Description)
+	}
+	return suggestions, hint
+}
@@ -0,0 +1,93 @@
+package vies
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TestEndpoint is the VIES acceptance service. It does not look up real
+// numbers; instead the number part selects a scripted outcome (see
+// LookupTestNumber).
+const TestEndpoint = "https://ec.europa.eu/taxation_customs/vies/services/checkVatTestService"
+
+// TestNumber describes the scripted outcome of a VIES test service number
+type TestNumber struct {
+	Number      string
+	Valid       bool
+	Fault       string // fault returned instead of a result, if any
+	Description string
+}
+
+// testNumbers lists the numbers documented for the VIES test service
+var testNumbers = []TestNumber{
+	{Number: "100", Valid: true, Description: "Valid request with valid VAT number"},
+	{Number: "200", Description: "Valid request with an invalid VAT number"},
+	{Number: "201", Fault: "INVALID_INPUT", Description: "Error: invalid country code or empty VAT number"},
+	{Number: "202", Fault: "INVALID_REQUESTER_INFO", Description: "Error: invalid requester information"},
+	{Number: "300", Fault: "SERVICE_UNAVAILABLE", Description: "Error: VIES service unavailable"},
+	{Number: "301", Fault: "MS_UNAVAILABLE", Description: "Error: member state service unavailable"},
+	{Number: "302", Fault: "TIMEOUT", Description: "Error: member state did not reply in time"},
+	{Number: "400", Fault: "VAT_BLOCKED", Description: "Error: VAT number blocked"},
+	{Number: "401", Fault: "IP_BLOCKED", Description: "Error: client IP blocked"},
+	{Number: "500", Fault: "GLOBAL_MAX_CONCURRENT_REQ", Description: "Error: too many concurrent requests"},
+	{Number: "501", Fault: "GLOBAL_MAX_CONCURRENT_REQ_TIME", Description: "Error: too many concurrent requests in time window"},
+	{Number: "600", Fault: "MS_MAX_CONCURRENT_REQ", Description: "Error: too many concurrent requests to member state"},
+	{Number: "601", Fault: "MS_MAX_CONCURRENT_REQ_TIME", Description: "Error: too many concurrent requests to member state in time window"},
+}
+
+// TestNumbers returns the documented VIES test service numbers
+func TestNumbers() []TestNumber {
+	out := make([]TestNumber, len(testNumbers))
+	copy(out, testNumbers)
+	return out
+}
+
+// LookupTestNumber returns the scripted outcome for a test service number,
+// given without country prefix
+func LookupTestNumber(number string) (TestNumber, bool) {
+	for _, tn := range testNumbers {
+		if tn.Number == number {
+			return tn, true
+		}
+	}
+	return TestNumber{}, false
+}
+
+// parseTestVATNumber splits a test service input such as "DE100" into
+// country code and number. It succeeds only for supported countries
+// combined with a documented test number, which would fail format validation.
+func parseTestVATNumber(vatNumber string) (string, string, bool) {
+	vatNumber = NormalizeInput(vatNumber)
+	if len(vatNumber) < 3 {
+		return "", "", false
+	}
+	countryCode, number := vatNumber[:2], vatNumber[2:]
+	if countryCode == "GR" {
+		countryCode = "EL"
+	}
+	if _, ok := countryValidators[countryCode]; !ok {
+		return "", "", false
+	}
+	if _, ok := LookupTestNumber(strings.TrimPrefix(number, "U")); !ok {
+		return "", "", false
+	}
+	return countryCode, strings.TrimPrefix(number, "U"), true
+}
+
+// labelTestScenario records the scripted outcome of a test service number on
+// the result or service error returned for it
+func labelTestScenario(number string, result *CheckVatResult, err error) {
+	tn, ok := LookupTestNumber(number)
+	if !ok {
+		return
+	}
+	label := fmt.Sprintf("%s: %s", tn.Number, tn.Description)
+	var serviceErr *ServiceError
+	switch {
+	case result != nil:
+		result.TestScenario = label
+	case errors.As(err, &serviceErr) && serviceErr.Code == CodeSOAPFault:
+		serviceErr.TestScenario = label
+	}
+}
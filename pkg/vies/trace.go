@@ -0,0 +1,103 @@
+package vies
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTrace records the phases of a VIES request with httptrace, so
+// verbose logs can tell slow DNS, connects or TLS handshakes on the user's
+// side from a slow answer by VIES
+type requestTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	reused       bool
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyRead     time.Time
+	parsed       time.Time
+}
+
+// traceRequest returns req with a trace of its phases attached
+func traceRequest(req *http.Request) (*http.Request, *requestTrace) {
+	t := &requestTrace{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		// Dialing several addresses calls these once per address; the
+		// first start and the last successful connect count
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				t.mark(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				t.mark(&t.tlsDone)
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+// mark records the current time in one of the phase fields
+func (t *requestTrace) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+// String lists the durations of the phases completed so far, e.g.
+// "DNS 1.2ms, connect 20.5ms, TLS 41.0ms, TTFB 180.3ms, read 0.4ms, parse 0.1ms".
+// TTFB is the time from sending the request to the first byte of the answer,
+// i.e. the time VIES took.
+func (t *requestTrace) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var phases []string
+	phase := func(name string, from, to time.Time) {
+		if !from.IsZero() && !to.IsZero() {
+			phases = append(phases, fmt.Sprintf("%s %.1fms", name, float64(to.Sub(from))/float64(time.Millisecond)))
+		}
+	}
+	if t.reused {
+		phases = append(phases, "connection reused")
+	}
+	phase("DNS", t.dnsStart, t.dnsDone)
+	phase("connect", t.connectStart, t.connectDone)
+	phase("TLS", t.tlsStart, t.tlsDone)
+	phase("TTFB", t.wroteRequest, t.firstByte)
+	phase("read", t.firstByte, t.bodyRead)
+	phase("parse", t.bodyRead, t.parsed)
+	if t.firstByte.IsZero() {
+		phases = append(phases, fmt.Sprintf("no answer after %.1fms", float64(time.Since(t.start))/float64(time.Millisecond)))
+	}
+	return strings.Join(phases, ", ")
+}
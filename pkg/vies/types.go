@@ -0,0 +1,489 @@
+// Package vies is a client for the EU VIES VAT number validation service,
+// with offline format and check digit validation for all member states.
+// Programs embedding it check numbers through the Checker interface, which
+// Client implements, and tune the client with ClientOption values.
+package vies
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// CheckVatRequest represents the SOAP request for VAT validation
+type CheckVatRequest struct {
+	XMLName     xml.Name `xml:"urn:checkVat"`
+	CountryCode string   `xml:"urn:countryCode"`
+	VatNumber   string   `xml:"urn:vatNumber"`
+}
+
+// CheckVatApproxRequest represents the SOAP request for VAT validation on
+// behalf of a requester, which makes VIES issue a consultation number
+type CheckVatApproxRequest struct {
+	XMLName              xml.Name `xml:"urn:checkVatApprox"`
+	CountryCode          string   `xml:"urn:countryCode"`
+	VatNumber            string   `xml:"urn:vatNumber"`
+	RequesterCountryCode string   `xml:"urn:requesterCountryCode"`
+	RequesterVatNumber   string   `xml:"urn:requesterVatNumber"`
+}
+
+// CheckVatResponse represents the SOAP response from VIES
+type CheckVatResponse struct {
+	XMLName     xml.Name  `xml:"checkVatResponse"`
+	CountryCode string    `xml:"countryCode"`
+	VatNumber   string    `xml:"vatNumber"`
+	RequestDate time.Time `xml:"requestDate"`
+	Valid       bool      `xml:"valid"`
+	Name        string    `xml:"name"`
+	Address     string    `xml:"address"`
+}
+
+// CheckVatResult represents the processed validation result
+type CheckVatResult struct {
+	CountryCode string    `json:"countryCode"`
+	VatNumber   string    `json:"vatNumber"`
+	RequestDate time.Time `json:"requestDate"`
+	Valid       bool      `json:"valid"`
+	Name        string    `json:"name,omitempty"`
+	Address     string    `json:"address,omitempty"`
+	// NormalizedVATNumber is the input after NormalizeInput, e.g.
+	// "DE123456789" for "de 123.456.789"
+	NormalizedVATNumber string `json:"normalizedVatNumber,omitempty"`
+	// CanonicalVATNumber is the number as sent to VIES, e.g. "EL094259216"
+	// for "GR094259216" or "AT12345678" for "ATU12345678"
+	CanonicalVATNumber string `json:"canonicalVatNumber,omitempty"`
+	// RequestIdentifier is the VIES consultation number, only issued when
+	// the request names a requester (see WithRequester)
+	RequestIdentifier string `json:"requestIdentifier,omitempty"`
+	// TestScenario labels the scripted outcome when a test service number
+	// was checked against the acceptance service (see WithTestService)
+	TestScenario string `json:"testScenario,omitempty"`
+	// AddressCountryCode and AddressCountry give the country of Address as
+	// ISO 3166-1 code and English name, e.g. "GR" and "Greece" for an EL
+	// number. VIES addresses rarely name the country; it is the queried
+	// member state. Both are empty when there is no address.
+	AddressCountryCode string `json:"addressCountryCode,omitempty"`
+	AddressCountry     string `json:"addressCountry,omitempty"`
+	// RawRequestDate is the requestDate exactly as VIES returned it, time
+	// zone suffix included (e.g. "2025-01-09+01:00"), for audit records
+	RawRequestDate string `json:"rawRequestDate,omitempty"`
+	// AssumedCountryCode is set when the input had no country prefix and
+	// the client's default country was prepended (see WithDefaultCountry)
+	AssumedCountryCode string `json:"assumedCountryCode,omitempty"`
+	// DurationMs is the time CheckVAT took in milliseconds, rate limit
+	// waits and enrichment included
+	DurationMs int64 `json:"durationMs"`
+	// Timing breaks the duration of a request sent to VIES down by phase;
+	// nil for results served from the cache
+	Timing *RequestTiming `json:"timing,omitempty"`
+	// Extensions holds the data attached by enrichers (see WithEnricher),
+	// keyed as the enrichers chose
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// RequestTiming breaks down the duration of a VIES request, in milliseconds
+type RequestTiming struct {
+	// WaitMs is spent waiting for the request budget and rate limit
+	WaitMs int64 `json:"waitMs"`
+	// RequestMs is the HTTP round trip, reading the response included
+	RequestMs int64 `json:"requestMs"`
+	// ParseMs is spent parsing the SOAP response
+	ParseMs int64 `json:"parseMs"`
+}
+
+// SOAPEnvelope represents the SOAP envelope wrapper
+type SOAPEnvelope struct {
+	XMLName      xml.Name `xml:"soapenv:Envelope"`
+	XmlnsSoapenv string   `xml:"xmlns:soapenv,attr"`
+	XmlnsUrn     string   `xml:"xmlns:urn,attr"`
+	Body         SOAPBody `xml:"soapenv:Body"`
+}
+
+// SOAPBody represents the SOAP body
+type SOAPBody struct {
+	CheckVat         *CheckVatRequest       `xml:"urn:checkVat,omitempty"`
+	CheckVatApprox   *CheckVatApproxRequest `xml:"urn:checkVatApprox,omitempty"`
+	CheckVatResponse *CheckVatResponse      `xml:"checkVatResponse,omitempty"`
+	Fault            *SOAPFault             `xml:"soapenv:Fault,omitempty"`
+}
+
+// SOAPFault represents a SOAP fault response
+type SOAPFault struct {
+	XMLName xml.Name    `xml:"soapenv:Fault"`
+	Code    string      `xml:"faultcode"`
+	String  string      `xml:"faultstring"`
+	Detail  FaultDetail `xml:"detail"`
+}
+
+// FaultDetail is the parsed <detail> element (SOAP 1.2: <Detail>) of a SOAP
+// fault. VIES itself sends none, but some member state services pass their
+// own error payload through it.
+type FaultDetail struct {
+	// Fields are the values of the leaf elements, in document order
+	Fields []FaultDetailField `xml:"-"`
+}
+
+// FaultDetailField is a leaf element of a fault detail
+type FaultDetailField struct {
+	// Path holds the local names of the elements below <detail>, separated
+	// by slashes (e.g. "msError/errorCode"); empty for a text-only detail
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// ValidationError represents VAT format validation errors
+type ValidationError struct {
+	Code        string
+	Message     string
+	VATNumber   string
+	Suggestions []string // likely corrections that pass format validation
+	Hint        string   // explanation of the likely mistake, if known
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the sentinel error for this error's code
+func (e *ValidationError) Is(target error) bool {
+	return target != nil && target == sentinelErrors[e.Code]
+}
+
+// ServiceError represents VIES service errors
+type ServiceError struct {
+	Code       string
+	Message    string
+	VATNumber  string
+	HTTPStatus int    // HTTP status of the VIES response, 0 if none was received
+	FaultCode  string // VIES fault identifier from the SOAP faultstring (e.g. MS_UNAVAILABLE)
+	RawBody    string // raw response body, for diagnostics
+	Err        error  // underlying cause, if any
+	// TestScenario labels a fault simulated by the acceptance service
+	TestScenario string
+	// FaultDetail is the parsed detail of a SOAP fault, nil if it had none
+	FaultDetail *FaultDetail
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the sentinel error for this error's code
+func (e *ServiceError) Is(target error) bool {
+	return target != nil && target == sentinelErrors[e.Code]
+}
+
+// Unwrap returns the underlying cause, such as a network or context error
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// Error codes for different types of failures
+const (
+	CodeInvalidFormat      = "INVALID_FORMAT"
+	CodeUnsupportedCountry = "UNSUPPORTED_COUNTRY"
+	CodeServiceError       = "SERVICE_ERROR"
+	CodeNetworkTimeout     = "NETWORK_TIMEOUT"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeSOAPFault          = "SOAP_FAULT"
+	CodeInvalidChecksum    = "INVALID_CHECKSUM"
+	CodeBudgetExceeded     = "BUDGET_EXCEEDED"
+	CodeInternalError      = "INTERNAL_ERROR"
+)
+
+// Sentinel errors matching the error codes, for use with errors.Is
+var (
+	ErrInvalidFormat      = errors.New("invalid VAT number format")
+	ErrUnsupportedCountry = errors.New("unsupported country code")
+	ErrServiceError       = errors.New("VIES service error")
+	ErrNetworkTimeout     = errors.New("VIES request timed out")
+	ErrServiceUnavailable = errors.New("VIES service unavailable")
+	ErrSOAPFault          = errors.New("VIES SOAP fault")
+	ErrInvalidChecksum    = errors.New("invalid VAT number check digit")
+	ErrBudgetExceeded     = errors.New("request budget exceeded")
+	ErrInternal           = errors.New("internal error")
+)
+
+// sentinelErrors maps error codes to their sentinel errors
+var sentinelErrors = map[string]error{
+	CodeInvalidFormat:      ErrInvalidFormat,
+	CodeUnsupportedCountry: ErrUnsupportedCountry,
+	CodeServiceError:       ErrServiceError,
+	CodeNetworkTimeout:     ErrNetworkTimeout,
+	CodeServiceUnavailable: ErrServiceUnavailable,
+	CodeSOAPFault:          ErrSOAPFault,
+	CodeInvalidChecksum:    ErrInvalidChecksum,
+	CodeBudgetExceeded:     ErrBudgetExceeded,
+	CodeInternalError:      ErrInternal,
+}
+
+// ClientOptions for configuring the VIES client
+type ClientOptions struct {
+	Timeout   time.Duration
+	UserAgent string
+	Verbose   bool
+	Endpoint  string
+	Redact    bool
+	Transport http.RoundTripper
+	// TestService targets the VIES acceptance service, accepting its
+	// documented test numbers (e.g. "DE100") that fail format validation
+	TestService bool
+	// ResponseDump receives every raw HTTP response (status, headers, body)
+	ResponseDump io.Writer
+	// SOAPVersion selects the envelope and content type (default SOAP11)
+	SOAPVersion SOAPVersion
+	// ValidateRequests checks each envelope against the checkVat schema
+	// before sending it
+	ValidateRequests bool
+	// InputCountryPrefix reports Greek results as GR when the input used GR,
+	// instead of the canonical EL
+	InputCountryPrefix bool
+	// DefaultCountry is prepended to VAT numbers given without a country
+	// prefix, e.g. "IT" for Italian 11-digit numbers
+	DefaultCountry string
+	// RateLimit is the maximum number of requests per second to each member
+	// state; 0 means unlimited
+	RateLimit float64
+	// CountryRateLimits overrides RateLimit for individual member states
+	CountryRateLimits map[string]float64
+	// Budget, if set, is charged for every request sent to VIES
+	Budget RequestBudget
+	// Cache, if set, stores results for CacheTTL (0: no expiry)
+	Cache    Cache
+	CacheTTL time.Duration
+	// CacheInvalidTTL, if set, replaces CacheTTL for invalid results
+	CacheInvalidTTL time.Duration
+	// CacheErrorTTL, if set, caches faults reported by VIES for this long;
+	// errors are not cached while it is 0
+	CacheErrorTTL time.Duration
+	// Enrichers are run in order on every successful result
+	Enrichers []Enricher
+	// Logger receives verbose logs (default: stderr with "[VIES] " prefix
+	// and timestamps)
+	Logger *log.Logger
+}
+
+// SOAPVersion identifies a SOAP protocol version
+type SOAPVersion string
+
+// Supported SOAP versions
+const (
+	SOAP11 SOAPVersion = "1.1"
+	SOAP12 SOAPVersion = "1.2"
+)
+
+// ClientOption is a function type for configuring client options
+type ClientOption func(*ClientOptions)
+
+// WithTimeout sets the request timeout
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header
+func WithUserAgent(userAgent string) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.UserAgent = userAgent
+	}
+}
+
+// WithVerbose enables verbose logging
+func WithVerbose(verbose bool) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Verbose = verbose
+	}
+}
+
+// WithEndpoint sets a custom endpoint (for testing)
+func WithEndpoint(endpoint string) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Endpoint = endpoint
+	}
+}
+
+// WithTransport replaces the HTTP transport, e.g. with a recording or
+// replaying RoundTripper in tests
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Transport = transport
+	}
+}
+
+// WithTestService points the client at the VIES acceptance service
+// (TestEndpoint) and accepts its test numbers such as "DE100" or "FR201"
+func WithTestService() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.TestService = true
+		opts.Endpoint = TestEndpoint
+	}
+}
+
+// WithResponseDump writes every raw VIES HTTP response to w, for debugging.
+// Trader data is masked if redaction is enabled.
+func WithResponseDump(w io.Writer) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.ResponseDump = w
+	}
+}
+
+// WithSOAPVersion selects the SOAP protocol version. SOAP12 sends SOAP 1.2
+// envelopes with an application/soap+xml content type, for networks whose
+// intermediaries mangle SOAP 1.1 requests.
+func WithSOAPVersion(version SOAPVersion) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.SOAPVersion = version
+	}
+}
+
+// WithRequestValidation checks every generated SOAP envelope against the
+// embedded checkVat schema before sending it (see ValidateEnvelope)
+func WithRequestValidation() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.ValidateRequests = true
+	}
+}
+
+// WithInputCountryPrefix reports results with the country prefix of the
+// input. VIES only knows Greece as EL, so by default a check of "GR..." is
+// reported with country code EL; with this option it keeps GR.
+func WithInputCountryPrefix() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.InputCountryPrefix = true
+	}
+}
+
+// WithDefaultCountry prepends a country code to VAT numbers given without
+// one, as found in national datasets. Results of such numbers report the
+// code in AssumedCountryCode. GR is accepted for EL.
+func WithDefaultCountry(countryCode string) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.DefaultCountry = countryCode
+	}
+}
+
+// WithRateLimit limits the requests per second sent to each member state.
+// Every country is paced independently.
+func WithRateLimit(perSecond float64) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.RateLimit = perSecond
+	}
+}
+
+// WithCountryRateLimit limits the requests per second sent to one member
+// state, overriding WithRateLimit for it. A limit of 0 makes it unlimited.
+func WithCountryRateLimit(countryCode string, perSecond float64) ClientOption {
+	return func(opts *ClientOptions) {
+		if opts.CountryRateLimits == nil {
+			opts.CountryRateLimits = make(map[string]float64)
+		}
+		opts.CountryRateLimits[countryCode] = perSecond
+	}
+}
+
+// WithRequestBudget charges every request sent to VIES to budget, and
+// refuses requests with BUDGET_EXCEEDED once it is used up
+func WithRequestBudget(budget RequestBudget) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Budget = budget
+	}
+}
+
+// WithCache serves repeated checks of the same VAT number from cache for
+// ttl. Errors (unless enabled with WithNegativeCache), checks on behalf of a
+// requester and calls with WithNoCache bypass the cache.
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Cache = cache
+		opts.CacheTTL = ttl
+	}
+}
+
+// WithNegativeCache stores invalid results for invalidTTL instead of the
+// WithCache TTL, and faults reported by VIES (e.g. MS_UNAVAILABLE) for
+// errorTTL. Invalid results often belong to freshly registered numbers that
+// become valid within days, so they should expire sooner. A zero invalidTTL
+// keeps the WithCache TTL; a zero errorTTL leaves errors uncached. Network
+// failures are never cached.
+func WithNegativeCache(invalidTTL, errorTTL time.Duration) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.CacheInvalidTTL = invalidTTL
+		opts.CacheErrorTTL = errorTTL
+	}
+}
+
+// WithEnricher adds an enricher run on every successful result, after the
+// result is cached, so enrichment data is always current
+func WithEnricher(enricher Enricher) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Enrichers = append(opts.Enrichers, enricher)
+	}
+}
+
+// WithLogger sends verbose logs to logger instead of stderr
+func WithLogger(logger *log.Logger) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Logger = logger
+	}
+}
+
+// WithRedact masks trader names and addresses in verbose logs
+func WithRedact(redact bool) ClientOption {
+	return func(opts *ClientOptions) {
+		opts.Redact = redact
+	}
+}
+
+// RequestOptions holds per-call overrides for CheckVAT
+type RequestOptions struct {
+	Timeout   time.Duration // replaces the client timeout for this call
+	Requester string        // requester VAT number, including country prefix
+	Endpoint  string        // replaces the client endpoint for this call
+	NoCache   bool          // bypass any configured result cache
+	Refresh   bool          // skip the cache lookup but store the result
+}
+
+// RequestOption is a function type for configuring a single CheckVAT call
+type RequestOption func(*RequestOptions)
+
+// WithRequestTimeout overrides the client timeout for a single call
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(opts *RequestOptions) {
+		opts.Timeout = timeout
+	}
+}
+
+// WithRequester performs the check on behalf of the given VAT number, so
+// VIES returns a consultation number as proof of the check
+func WithRequester(vatNumber string) RequestOption {
+	return func(opts *RequestOptions) {
+		opts.Requester = vatNumber
+	}
+}
+
+// WithRequestEndpoint overrides the client endpoint for a single call
+func WithRequestEndpoint(endpoint string) RequestOption {
+	return func(opts *RequestOptions) {
+		opts.Endpoint = endpoint
+	}
+}
+
+// WithNoCache bypasses the result cache for a single call
+func WithNoCache() RequestOption {
+	return func(opts *RequestOptions) {
+		opts.NoCache = true
+	}
+}
+
+// WithRefresh asks VIES even if a cached result exists, and replaces the
+// cached entry with the fresh result, e.g. when a supplier reports that
+// their number has just been registered
+func WithRefresh() RequestOption {
+	return func(opts *RequestOptions) {
+		opts.Refresh = true
+	}
+}
@@ -18,8 +18,8 @@ func TestSOAPRequestMarshaling(t *testing.T) {
 </soapenv:Envelope>`
 
 	// Create SOAP request
-	soapRequest := createSOAPRequest("DE", "266201128")
-	
+	soapRequest := createSOAPRequest(soap11Envelope, "DE", "266201128")
+
 	// Marshal to XML
 	requestBody, err := xml.Marshal(soapRequest)
 	if err != nil {
@@ -28,7 +28,7 @@ func TestSOAPRequestMarshaling(t *testing.T) {
 
 	actualXML := string(requestBody)
 	t.Logf("Actual XML:\n%s", actualXML)
-	
+
 	// Basic validation - check for key elements
 	if !strings.Contains(actualXML, "checkVat") {
 		t.Error("Missing checkVat element")
@@ -0,0 +1,521 @@
+package vies
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CountryValidator contains validation rules for a specific EU country
+type CountryValidator struct {
+	Code        string
+	Name        string
+	Pattern     *regexp.Regexp
+	MinLength   int
+	MaxLength   int
+	Description string
+	// Examples are fictitious numbers passing ValidateFormat, one per
+	// number shape (e.g. 9 and 10 digits for Bulgaria)
+	Examples []string
+	// LocalName is the national name of the VAT number, e.g. "Partita IVA"
+	LocalName string
+	// AuthorityURL is the website of the national tax authority issuing
+	// the numbers
+	AuthorityURL string
+}
+
+// EU member state VAT validation patterns
+var countryValidators = map[string]CountryValidator{
+	"AT": {
+		Code:         "AT",
+		Name:         "Austria",
+		Pattern:      regexp.MustCompile(`^ATU\d{8}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "ATU + 8 digits",
+		Examples:     []string{"ATU12345678"},
+		LocalName:    "UID-Nummer (Umsatzsteuer-Identifikationsnummer)",
+		AuthorityURL: "https://www.bmf.gv.at",
+	},
+	"BE": {
+		Code:         "BE",
+		Name:         "Belgium",
+		Pattern:      regexp.MustCompile(`^BE[01]\d{9}$`),
+		MinLength:    12,
+		MaxLength:    12,
+		Description:  "BE0 or BE1 + 9 digits",
+		Examples:     []string{"BE0123456749"},
+		LocalName:    "BTW-nummer / Numéro de TVA",
+		AuthorityURL: "https://finances.belgium.be",
+	},
+	"BG": {
+		Code:         "BG",
+		Name:         "Bulgaria",
+		Pattern:      regexp.MustCompile(`^BG\d{9,10}$`),
+		MinLength:    11,
+		MaxLength:    12,
+		Description:  "BG + 9 or 10 digits",
+		Examples:     []string{"BG123456789", "BG1234567890"},
+		LocalName:    "Идентификационен номер по ДДС",
+		AuthorityURL: "https://nra.bg",
+	},
+	"HR": {
+		Code:         "HR",
+		Name:         "Croatia",
+		Pattern:      regexp.MustCompile(`^HR\d{11}$`),
+		MinLength:    13,
+		MaxLength:    13,
+		Description:  "HR + 11 digits",
+		Examples:     []string{"HR12345678901"},
+		LocalName:    "PDV identifikacijski broj (OIB)",
+		AuthorityURL: "https://www.porezna-uprava.hr",
+	},
+	"CY": {
+		Code:         "CY",
+		Name:         "Cyprus",
+		Pattern:      regexp.MustCompile(`^CY\d{8}[A-Z]$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "CY + 8 digits + 1 letter",
+		Examples:     []string{"CY12345678X"},
+		LocalName:    "Αριθμός Εγγραφής Φ.Π.Α.",
+		AuthorityURL: "https://www.mof.gov.cy/mof/tax/taxdep.nsf",
+	},
+	"CZ": {
+		Code:         "CZ",
+		Name:         "Czech Republic",
+		Pattern:      regexp.MustCompile(`^CZ\d{8,10}$`),
+		MinLength:    10,
+		MaxLength:    12,
+		Description:  "CZ + 8, 9, or 10 digits",
+		Examples:     []string{"CZ12345678", "CZ1234567890"},
+		LocalName:    "DIČ (Daňové identifikační číslo)",
+		AuthorityURL: "https://www.financnisprava.cz",
+	},
+	"DK": {
+		Code:         "DK",
+		Name:         "Denmark",
+		Pattern:      regexp.MustCompile(`^DK\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "DK + 8 digits",
+		Examples:     []string{"DK12345674"},
+		LocalName:    "CVR-nummer / SE-nummer",
+		AuthorityURL: "https://skat.dk",
+	},
+	"EE": {
+		Code:         "EE",
+		Name:         "Estonia",
+		Pattern:      regexp.MustCompile(`^EE\d{9}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "EE + 9 digits",
+		Examples:     []string{"EE123456789"},
+		LocalName:    "KMKR number (Käibemaksukohustuslase number)",
+		AuthorityURL: "https://www.emta.ee",
+	},
+	"FI": {
+		Code:         "FI",
+		Name:         "Finland",
+		Pattern:      regexp.MustCompile(`^FI\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "FI + 8 digits",
+		Examples:     []string{"FI12345671"},
+		LocalName:    "ALV-numero (Arvonlisäverotunniste)",
+		AuthorityURL: "https://www.vero.fi",
+	},
+	"FR": {
+		Code:         "FR",
+		Name:         "France",
+		Pattern:      regexp.MustCompile(`^FR[A-HJ-NP-Z0-9]{2}\d{9}$`),
+		MinLength:    13,
+		MaxLength:    13,
+		Description:  "FR + 2 characters + 9 digits",
+		Examples:     []string{"FR12345678901"},
+		LocalName:    "Numéro de TVA intracommunautaire",
+		AuthorityURL: "https://www.impots.gouv.fr",
+	},
+	"DE": {
+		Code:         "DE",
+		Name:         "Germany",
+		Pattern:      regexp.MustCompile(`^DE\d{9}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "DE + 9 digits",
+		Examples:     []string{"DE123456788"},
+		LocalName:    "USt-IdNr. (Umsatzsteuer-Identifikationsnummer)",
+		AuthorityURL: "https://www.bzst.de",
+	},
+	"EL": {
+		Code:         "EL",
+		Name:         "Greece",
+		Pattern:      regexp.MustCompile(`^EL\d{9}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "EL + 9 digits",
+		Examples:     []string{"EL123456789"},
+		LocalName:    "ΑΦΜ (Αριθμός Φορολογικού Μητρώου)",
+		AuthorityURL: "https://www.aade.gr",
+	},
+	"GR": { // Alternative code for Greece
+		Code:         "GR",
+		Name:         "Greece",
+		Pattern:      regexp.MustCompile(`^GR\d{9}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "GR + 9 digits (alternative for EL)",
+		Examples:     []string{"GR123456789"},
+		LocalName:    "ΑΦΜ (Αριθμός Φορολογικού Μητρώου)",
+		AuthorityURL: "https://www.aade.gr",
+	},
+	"HU": {
+		Code:         "HU",
+		Name:         "Hungary",
+		Pattern:      regexp.MustCompile(`^HU\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "HU + 8 digits",
+		Examples:     []string{"HU12345678"},
+		LocalName:    "Közösségi adószám",
+		AuthorityURL: "https://nav.gov.hu",
+	},
+	"IE": {
+		Code:         "IE",
+		Name:         "Ireland",
+		Pattern:      regexp.MustCompile(`^IE[A-Z0-9]{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "IE + 8 alphanumeric characters",
+		Examples:     []string{"IE1234567X"},
+		LocalName:    "VAT number (Uimhir CBL)",
+		AuthorityURL: "https://www.revenue.ie",
+	},
+	"IT": {
+		Code:         "IT",
+		Name:         "Italy",
+		Pattern:      regexp.MustCompile(`^IT\d{11}$`),
+		MinLength:    13,
+		MaxLength:    13,
+		Description:  "IT + 11 digits",
+		Examples:     []string{"IT12345670017"},
+		LocalName:    "Partita IVA",
+		AuthorityURL: "https://www.agenziaentrate.gov.it",
+	},
+	"LV": {
+		Code:         "LV",
+		Name:         "Latvia",
+		Pattern:      regexp.MustCompile(`^LV\d{11}$`),
+		MinLength:    13,
+		MaxLength:    13,
+		Description:  "LV + 11 digits",
+		Examples:     []string{"LV12345678901"},
+		LocalName:    "PVN reģistrācijas numurs",
+		AuthorityURL: "https://www.vid.gov.lv",
+	},
+	"LT": {
+		Code:         "LT",
+		Name:         "Lithuania",
+		Pattern:      regexp.MustCompile(`^LT(\d{9}|\d{12})$`),
+		MinLength:    11,
+		MaxLength:    14,
+		Description:  "LT + 9 or 12 digits",
+		Examples:     []string{"LT123456789", "LT123456789012"},
+		LocalName:    "PVM mokėtojo kodas",
+		AuthorityURL: "https://www.vmi.lt",
+	},
+	"LU": {
+		Code:         "LU",
+		Name:         "Luxembourg",
+		Pattern:      regexp.MustCompile(`^LU\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "LU + 8 digits",
+		Examples:     []string{"LU12345678"},
+		LocalName:    "Numéro d'identification à la TVA",
+		AuthorityURL: "https://aed.public.lu",
+	},
+	"MT": {
+		Code:         "MT",
+		Name:         "Malta",
+		Pattern:      regexp.MustCompile(`^MT\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "MT + 8 digits",
+		Examples:     []string{"MT12345678"},
+		LocalName:    "VAT number (Numru tal-VAT)",
+		AuthorityURL: "https://cfr.gov.mt",
+	},
+	"NL": {
+		Code:         "NL",
+		Name:         "Netherlands",
+		Pattern:      regexp.MustCompile(`^NL\d{9}B\d{2}$`),
+		MinLength:    14,
+		MaxLength:    14,
+		Description:  "NL + 9 digits + B + 2 digits",
+		Examples:     []string{"NL123456782B01"},
+		LocalName:    "Btw-identificatienummer",
+		AuthorityURL: "https://www.belastingdienst.nl",
+	},
+	"PL": {
+		Code:         "PL",
+		Name:         "Poland",
+		Pattern:      regexp.MustCompile(`^PL\d{10}$`),
+		MinLength:    12,
+		MaxLength:    12,
+		Description:  "PL + 10 digits",
+		Examples:     []string{"PL1234563218"},
+		LocalName:    "NIP (Numer Identyfikacji Podatkowej)",
+		AuthorityURL: "https://www.podatki.gov.pl",
+	},
+	"PT": {
+		Code:         "PT",
+		Name:         "Portugal",
+		Pattern:      regexp.MustCompile(`^PT\d{9}$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "PT + 9 digits",
+		Examples:     []string{"PT123456789"},
+		LocalName:    "NIF (Número de Identificação Fiscal)",
+		AuthorityURL: "https://www.portaldasfinancas.gov.pt",
+	},
+	"RO": {
+		Code:         "RO",
+		Name:         "Romania",
+		Pattern:      regexp.MustCompile(`^RO\d{2,10}$`),
+		MinLength:    4,
+		MaxLength:    12,
+		Description:  "RO + 2 to 10 digits",
+		Examples:     []string{"RO12345678"},
+		LocalName:    "CIF (Cod de identificare fiscală)",
+		AuthorityURL: "https://www.anaf.ro",
+	},
+	"SK": {
+		Code:         "SK",
+		Name:         "Slovakia",
+		Pattern:      regexp.MustCompile(`^SK\d{10}$`),
+		MinLength:    12,
+		MaxLength:    12,
+		Description:  "SK + 10 digits",
+		Examples:     []string{"SK1234567890"},
+		LocalName:    "IČ DPH (Identifikačné číslo pre daň z pridanej hodnoty)",
+		AuthorityURL: "https://www.financnasprava.sk",
+	},
+	"SI": {
+		Code:         "SI",
+		Name:         "Slovenia",
+		Pattern:      regexp.MustCompile(`^SI\d{8}$`),
+		MinLength:    10,
+		MaxLength:    10,
+		Description:  "SI + 8 digits",
+		Examples:     []string{"SI12345678"},
+		LocalName:    "ID za DDV (Identifikacijska številka za DDV)",
+		AuthorityURL: "https://www.fu.gov.si",
+	},
+	"ES": {
+		Code:         "ES",
+		Name:         "Spain",
+		Pattern:      regexp.MustCompile(`^ES[A-Z0-9]\d{7}[A-Z0-9]$`),
+		MinLength:    11,
+		MaxLength:    11,
+		Description:  "ES + character + 7 digits + character",
+		Examples:     []string{"ESA00112235", "ES12345678Z"},
+		LocalName:    "NIF-IVA (Número de Identificación Fiscal)",
+		AuthorityURL: "https://sede.agenciatributaria.gob.es",
+	},
+	"SE": {
+		Code:         "SE",
+		Name:         "Sweden",
+		Pattern:      regexp.MustCompile(`^SE\d{12}$`),
+		MinLength:    14,
+		MaxLength:    14,
+		Description:  "SE + 12 digits",
+		Examples:     []string{"SE123456789701"},
+		LocalName:    "Momsregistreringsnummer",
+		AuthorityURL: "https://www.skatteverket.se",
+	},
+}
+
+// inputLabels are labels commonly written in front of VAT numbers, in their
+// form after NormalizeInput has removed separators; longest first
+var inputLabels = []string{"USTIDNR", "VATNUMBER", "USTID", "VATNO", "VATID", "MWST", "VAT", "TVA", "BTW", "IVA", "UID"}
+
+// NormalizeInput cleans up a VAT number as typed or pasted by a user: it
+// removes whitespace (including non-breaking spaces), dots, dashes and
+// colons, converts to upper case and strips labels such as "VAT:" or
+// "USt-IdNr." (e.g. "de 123.456.789" -> "DE123456789"). Other characters are
+// kept so that format validation can reject them.
+func NormalizeInput(vatNumber string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r), r == '.', r == ':', unicode.Is(unicode.Pd, r):
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, vatNumber)
+
+	for _, label := range inputLabels {
+		rest, ok := strings.CutPrefix(cleaned, label)
+		if ok && len(rest) >= 2 {
+			if _, known := countryValidators[rest[:2]]; known {
+				return rest
+			}
+		}
+	}
+	return cleaned
+}
+
+// ValidateFormat validates VAT number format according to EU country rules
+func ValidateFormat(vatNumber string) error {
+	err := validateFormat(vatNumber)
+	if validationErr, ok := err.(*ValidationError); ok {
+		validationErr.Suggestions, validationErr.Hint = suggestCorrections(validationErr.VATNumber)
+	}
+	return err
+}
+
+// validateFormat checks the format rules without computing suggestions
+func validateFormat(vatNumber string) error {
+	vatNumber = NormalizeInput(vatNumber)
+
+	if len(vatNumber) < 3 {
+		return &ValidationError{
+			Code:      CodeInvalidFormat,
+			Message:   "VAT number too short (minimum 3 characters)",
+			VATNumber: vatNumber,
+		}
+	}
+
+	// Extract country code (first 2 characters)
+	countryCode := vatNumber[:2]
+
+	// Special case: Some systems use GR instead of EL for Greece
+	if countryCode == "GR" {
+		countryCode = "EL"
+		vatNumber = "EL" + vatNumber[2:]
+	}
+
+	validator, exists := countryValidators[countryCode]
+	if !exists {
+		return &ValidationError{
+			Code:      CodeUnsupportedCountry,
+			Message:   fmt.Sprintf("Unsupported country code: %s", countryCode),
+			VATNumber: vatNumber,
+		}
+	}
+
+	// Check length
+	if len(vatNumber) < validator.MinLength || len(vatNumber) > validator.MaxLength {
+		return &ValidationError{
+			Code:      CodeInvalidFormat,
+			Message:   fmt.Sprintf("Invalid length for %s VAT number. Expected: %s", validator.Name, validator.Description),
+			VATNumber: vatNumber,
+		}
+	}
+
+	// Check pattern
+	if !validator.Pattern.MatchString(vatNumber) {
+		return &ValidationError{
+			Code:      CodeInvalidFormat,
+			Message:   fmt.Sprintf("Invalid format for %s VAT number. Expected: %s", validator.Name, validator.Description),
+			VATNumber: vatNumber,
+		}
+	}
+
+	// Check digits, where the country's algorithm is known
+	if !validChecksum(countryCode, vatNumber[2:]) {
+		return &ValidationError{
+			Code:      CodeInvalidChecksum,
+			Message:   fmt.Sprintf("Invalid check digit for %s VAT number", validator.Name),
+			VATNumber: vatNumber,
+		}
+	}
+
+	return nil
+}
+
+// ParseVATNumber extracts country code and VAT number from a full VAT number
+func ParseVATNumber(vatNumber string) (string, string, error) {
+	// Clean and validate format
+	if err := ValidateFormat(vatNumber); err != nil {
+		return "", "", err
+	}
+
+	vatNumber = NormalizeInput(vatNumber)
+
+	// Special case: Convert GR to EL for Greece
+	if strings.HasPrefix(vatNumber, "GR") {
+		vatNumber = "EL" + vatNumber[2:]
+	}
+
+	countryCode := vatNumber[:2]
+	number := vatNumber[2:]
+
+	// Some countries have prefix letters (like ATU for Austria)
+	// Remove them for the API call
+	switch countryCode {
+	case "AT":
+		if strings.HasPrefix(number, "U") {
+			number = number[1:] // Remove the 'U' prefix
+		}
+	}
+
+	return countryCode, number, nil
+}
+
+// ValidateAndNormalize validates a VAT number and returns the canonical form
+// sent to VIES: the input cleaned up by NormalizeInput, with GR mapped to EL
+// and the Austrian "U" prefix removed. It has no side effects.
+func ValidateAndNormalize(vatNumber string) (string, error) {
+	countryCode, number, err := ParseVATNumber(vatNumber)
+	if err != nil {
+		return "", err
+	}
+	return countryCode + number, nil
+}
+
+// GetSupportedCountries returns a list of all supported country codes
+func GetSupportedCountries() []string {
+	countries := make([]string, 0, len(countryValidators))
+	for code := range countryValidators {
+		if code != "GR" { // Skip GR as it's an alias for EL
+			countries = append(countries, code)
+		}
+	}
+	return countries
+}
+
+// isoCountryNames are ISO 3166-1 short names where they differ from the
+// validator name
+var isoCountryNames = map[string]string{
+	"CZ": "Czechia",
+}
+
+// ISOCountry returns the ISO 3166-1 alpha-2 code and English short name of a
+// member state given by its VIES country code, e.g. "GR" and "Greece" for
+// "EL". It returns false for unknown codes.
+func ISOCountry(countryCode string) (code, name string, ok bool) {
+	validator, exists := countryValidators[countryCode]
+	if !exists {
+		return "", "", false
+	}
+	code, name = countryCode, validator.Name
+	if code == "EL" {
+		code = "GR"
+	}
+	if isoName, ok := isoCountryNames[code]; ok {
+		name = isoName
+	}
+	return code, name, true
+}
+
+// GetCountryInfo returns information about a specific country's VAT format
+func GetCountryInfo(countryCode string) (*CountryValidator, error) {
+	validator, exists := countryValidators[countryCode]
+	if !exists {
+		return nil, fmt.Errorf("unsupported country code: %s", countryCode)
+	}
+	validator.Examples = append([]string(nil), validator.Examples...)
+	return &validator, nil
+}
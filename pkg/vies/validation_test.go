@@ -0,0 +1,70 @@
+package vies
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeInput(t *testing.T) {
+	tests := map[string]string{
+		"de 123.456.789":        "DE123456789",
+		"DE-123-456-789":        "DE123456789",
+		"VAT: DE123456789":      "DE123456789",
+		"USt-IdNr. DE123456789": "DE123456789",
+		"UID: ATU12345678":      "ATU12345678",
+		"nl 123456789b01":       "NL123456789B01",
+		"fr 12 345678901":       "FR12345678901",
+		"BTW BE0123456789":      "BE0123456789",
+		"DE12#3456789":          "DE12#3456789",
+		"VAT":                   "VAT",
+		"DE 123 456789":         "DE123456789",
+	}
+	for input, want := range tests {
+		if got := NormalizeInput(input); got != want {
+			t.Errorf("NormalizeInput(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestValidateFormatSuggestions(t *testing.T) {
+	tests := []struct {
+		input       string
+		suggestions []string
+		hasHint     bool
+	}{
+		{"AT12345678", []string{"ATU12345678"}, true},
+		{"DEI36695976", []string{"DE136695976"}, false},
+		{"NL00449544501", []string{"NL004495445B01"}, true},
+		{"DE12345678", nil, true},
+		{"GB123456789", nil, true},
+	}
+	for _, tt := range tests {
+		err := ValidateFormat(tt.input)
+		validationErr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("ValidateFormat(%q) = %v, want *ValidationError", tt.input, err)
+		}
+		if strings.Join(validationErr.Suggestions, ",") != strings.Join(tt.suggestions, ",") {
+			t.Errorf("ValidateFormat(%q) suggestions = %v, want %v", tt.input, validationErr.Suggestions, tt.suggestions)
+		}
+		if (validationErr.Hint != "") != tt.hasHint {
+			t.Errorf("ValidateFormat(%q) hint = %q", tt.input, validationErr.Hint)
+		}
+	}
+}
+
+func TestCountryInfoMetadata(t *testing.T) {
+	for code, validator := range countryValidators {
+		if len(validator.Examples) == 0 || validator.LocalName == "" || !strings.HasPrefix(validator.AuthorityURL, "https://") {
+			t.Errorf("%s: incomplete metadata: %+v", code, validator)
+		}
+		for _, example := range validator.Examples {
+			if !strings.HasPrefix(example, code) {
+				t.Errorf("%s: example %s has another country prefix", code, example)
+			}
+			if err := ValidateFormat(example); err != nil {
+				t.Errorf("%s: example %s fails validation: %v", code, example, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package vieshttp provides net/http middleware that validates a VAT number
+// carried by incoming requests and makes the outcome available to the
+// wrapped handler through the request context.
+package vieshttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Extractor returns the VAT number carried by a request, or "" if there is
+// none
+type Extractor func(r *http.Request) string
+
+// FromHeader reads the VAT number from a request header
+func FromHeader(name string) Extractor {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// FromQuery reads the VAT number from a URL query parameter
+func FromQuery(name string) Extractor {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(name)
+	}
+}
+
+// FromForm reads the VAT number from a form field of the request body or
+// URL, as parsed by http.Request.FormValue
+func FromForm(name string) Extractor {
+	return func(r *http.Request) string {
+		return r.FormValue(name)
+	}
+}
+
+// maxJSONBody limits how much of a body FromJSONField reads
+const maxJSONBody = 1 << 20
+
+// FromJSONField reads the VAT number from a top-level string field of a JSON
+// request body. Only the first 1 MiB is inspected; the bytes read are put
+// back in front of the rest of the body, so the wrapped handler reads the
+// complete body, whatever its size. Bodies that are not JSON objects, or
+// larger than 1 MiB, carry no number.
+func FromJSONField(name string) Extractor {
+	return func(r *http.Request) string {
+		if r.Body == nil || r.Body == http.NoBody {
+			return ""
+		}
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxJSONBody+1))
+		r.Body = restoredBody{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+		if err != nil || len(data) > maxJSONBody {
+			return ""
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return ""
+		}
+		var value string
+		if json.Unmarshal(fields[name], &value) != nil {
+			return ""
+		}
+		return value
+	}
+}
+
+// restoredBody is a request body with the bytes already read put back in
+// front; closing it closes the original body
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+// FirstOf tries several extractors in order and returns the first number
+// found
+func FirstOf(extractors ...Extractor) Extractor {
+	return func(r *http.Request) string {
+		for _, extract := range extractors {
+			if vatNumber := extract(r); vatNumber != "" {
+				return vatNumber
+			}
+		}
+		return ""
+	}
+}
+
+// Validation is the outcome of checking the VAT number of a request.
+// Exactly one of Result and Err is set.
+type Validation struct {
+	// VATNumber is the number as extracted from the request
+	VATNumber string
+	Result    *vies.CheckVatResult
+	// Err is a *vies.ValidationError for malformed numbers and a
+	// *vies.ServiceError when VIES could not be consulted
+	Err error
+}
+
+// Valid reports whether VIES confirmed the number as valid
+func (v *Validation) Valid() bool {
+	return v.Err == nil && v.Result != nil && v.Result.Valid
+}
+
+type contextKey struct{}
+
+// FromContext returns the validation stored by Middleware. It returns false
+// if the request carried no VAT number.
+func FromContext(ctx context.Context) (*Validation, bool) {
+	validation, ok := ctx.Value(contextKey{}).(*Validation)
+	return validation, ok
+}
+
+// NewContext returns a copy of ctx carrying validation, e.g. for testing
+// handlers without the middleware
+func NewContext(ctx context.Context, validation *Validation) context.Context {
+	return context.WithValue(ctx, contextKey{}, validation)
+}
+
+// Middleware checks the VAT number found by extract with checker and stores
+// the outcome in the request context, where handlers retrieve it with
+// FromContext. Requests without a number pass through unchanged. The
+// middleware never rejects a request; deciding what to do with invalid
+// numbers or VIES outages is left to the handler.
+func Middleware(checker vies.Checker, extract Extractor, options ...vies.RequestOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			vatNumber := extract(r)
+			if vatNumber == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			validation := &Validation{VATNumber: vatNumber}
+			// A panicking check is reported as CodeInternalError instead of
+			// taking down the connection
+			validation.Result, validation.Err = vies.CheckRecovered(r.Context(), checker, vatNumber, options...)
+			if validation.Err != nil {
+				validation.Result = nil
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), validation)))
+		})
+	}
+}
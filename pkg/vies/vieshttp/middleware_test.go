@@ -0,0 +1,90 @@
+package vieshttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+	"l22.io/viesquery/pkg/vies/viesmock"
+)
+
+func TestMiddleware(t *testing.T) {
+	checker := viesmock.New()
+	checker.SetValid("DE266201128", "Example GmbH", "Berlin")
+
+	var got *Validation
+	var found bool
+	var body string
+	handler := Middleware(checker, FirstOf(FromHeader("X-VAT-Number"), FromJSONField("vatNumber")))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, found = FromContext(r.Context())
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+		}))
+
+	tests := []struct {
+		name      string
+		req       *http.Request
+		wantFound bool
+		wantValid bool
+		wantErr   error
+	}{
+		{"header", withHeader(httptest.NewRequest("GET", "/", nil), "X-VAT-Number", "DE266201128"), true, true, nil},
+		{"json body", httptest.NewRequest("POST", "/", strings.NewReader(`{"vatNumber":"DE 266 201 128"}`)), true, true, nil},
+		{"unknown number", withHeader(httptest.NewRequest("GET", "/", nil), "X-VAT-Number", "DE136695976"), true, false, nil},
+		{"malformed", withHeader(httptest.NewRequest("GET", "/", nil), "X-VAT-Number", "DE12"), true, false, vies.ErrInvalidFormat},
+		{"no number", httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"x"}`)), false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found = nil, false
+			handler.ServeHTTP(httptest.NewRecorder(), tt.req)
+			if found != tt.wantFound {
+				t.Fatalf("FromContext found = %t, want %t", found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got.Valid() != tt.wantValid {
+				t.Errorf("Valid() = %t, want %t (%+v)", got.Valid(), tt.wantValid, got)
+			}
+			if tt.wantErr != nil && !errors.Is(got.Err, tt.wantErr) {
+				t.Errorf("Err = %v, want %v", got.Err, tt.wantErr)
+			}
+		})
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"vatNumber":"DE266201128"}`)))
+	if body != `{"vatNumber":"DE266201128"}` {
+		t.Errorf("handler read body %q, want the original body", body)
+	}
+}
+
+func TestFromJSONFieldLargeBody(t *testing.T) {
+	checker := viesmock.New()
+	var found bool
+	var received []byte
+	handler := Middleware(checker, FromJSONField("vatNumber"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, found = FromContext(r.Context())
+			received, _ = io.ReadAll(r.Body)
+		}))
+
+	body := `{"vatNumber":"DE266201128","data":"` + strings.Repeat("x", 2<<20) + `"}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if found {
+		t.Error("expected a body over 1 MiB to carry no number")
+	}
+	if string(received) != body {
+		t.Errorf("handler read %d bytes, want the complete body of %d bytes", len(received), len(body))
+	}
+}
+
+func withHeader(r *http.Request, name, value string) *http.Request {
+	r.Header.Set(name, value)
+	return r
+}
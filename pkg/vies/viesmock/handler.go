@@ -0,0 +1,158 @@
+package viesmock
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// soapRequest matches checkVat and checkVatApprox bodies regardless of prefix
+type soapRequest struct {
+	Body struct {
+		Operations []struct {
+			XMLName              xml.Name
+			CountryCode          string `xml:"countryCode"`
+			VatNumber            string `xml:"vatNumber"`
+			RequesterCountryCode string `xml:"requesterCountryCode"`
+			RequesterVatNumber   string `xml:"requesterVatNumber"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// Handler serves the checker as a VIES SOAP endpoint, so a real vies.Client
+// can be tested against the canned results, e.g. with
+// httptest.NewServer(m.Handler()) and vies.WithEndpoint. Errors are
+// answered with a SOAP fault: the FaultCode of a *vies.ServiceError if set,
+// else SERVICE_UNAVAILABLE, TIMEOUT or INVALID_INPUT by error code.
+// checkVatApprox requests are answered with the result's RequestIdentifier
+// or a generated consultation number.
+func (m *Checker) Handler() http.Handler {
+	return http.HandlerFunc(m.serveSOAP)
+}
+
+// Bodies returns the SOAP request bodies received by Handler, in order
+func (m *Checker) Bodies() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.bodies...)
+}
+
+func (m *Checker) serveSOAP(w http.ResponseWriter, r *http.Request) {
+	soap12 := strings.HasPrefix(r.Header.Get("Content-Type"), "application/soap+xml")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFault(w, soap12, "INVALID_INPUT")
+		return
+	}
+	m.mu.Lock()
+	m.bodies = append(m.bodies, string(body))
+	m.mu.Unlock()
+
+	var envelope soapRequest
+	if err := xml.Unmarshal(body, &envelope); err != nil || len(envelope.Body.Operations) != 1 {
+		writeFault(w, soap12, "INVALID_INPUT")
+		return
+	}
+	op := envelope.Body.Operations[0]
+	approx := op.XMLName.Local == "checkVatApprox"
+	vatNumber := strings.TrimSpace(op.CountryCode) + strings.TrimSpace(op.VatNumber)
+
+	result, err := m.CheckVAT(r.Context(), vatNumber)
+	if err != nil {
+		writeFault(w, soap12, faultFor(err))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<env:Envelope xmlns:env="%s"><env:Body>`, envelopeNamespace(soap12))
+	if approx {
+		identifier := result.RequestIdentifier
+		if identifier == "" {
+			identifier = fmt.Sprintf("WAPIMOCK%08d", len(m.Calls()))
+		}
+		b.WriteString(`<ns2:checkVatApproxResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+		writeCommon(&b, result)
+		writeElement(&b, "traderName", result.Name)
+		writeElement(&b, "traderAddress", result.Address)
+		writeElement(&b, "requestIdentifier", identifier)
+		b.WriteString(`</ns2:checkVatApproxResponse>`)
+	} else {
+		b.WriteString(`<ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+		writeCommon(&b, result)
+		writeElement(&b, "name", orDashes(result.Name))
+		writeElement(&b, "address", orDashes(result.Address))
+		b.WriteString(`</ns2:checkVatResponse>`)
+	}
+	b.WriteString(`</env:Body></env:Envelope>`)
+
+	w.Header().Set("Content-Type", contentType(soap12))
+	io.WriteString(w, b.String())
+}
+
+// faultFor returns the VIES fault identifier reporting err
+func faultFor(err error) string {
+	var serviceErr *vies.ServiceError
+	switch {
+	case errors.As(err, &serviceErr) && serviceErr.FaultCode != "":
+		return serviceErr.FaultCode
+	case errors.Is(err, vies.ErrNetworkTimeout):
+		return "TIMEOUT"
+	case serviceErr != nil:
+		return "SERVICE_UNAVAILABLE"
+	}
+	return "INVALID_INPUT"
+}
+
+// writeCommon writes the elements shared by checkVat and checkVatApprox responses
+func writeCommon(b *strings.Builder, result *vies.CheckVatResult) {
+	writeElement(b, "countryCode", result.CountryCode)
+	writeElement(b, "vatNumber", result.VatNumber)
+	writeElement(b, "requestDate", result.RequestDate.Format("2006-01-02-07:00")) // as VIES sends it
+	writeElement(b, "valid", fmt.Sprintf("%t", result.Valid))
+}
+
+func writeElement(b *strings.Builder, name, value string) {
+	fmt.Fprintf(b, "<ns2:%s>", name)
+	xml.EscapeText(b, []byte(value))
+	fmt.Fprintf(b, "</ns2:%s>", name)
+}
+
+// orDashes mirrors VIES, which reports undisclosed trader data as "---"
+func orDashes(s string) string {
+	if s == "" {
+		return "---"
+	}
+	return s
+}
+
+// envelopeNamespace returns the SOAP envelope namespace for the version
+func envelopeNamespace(soap12 bool) string {
+	if soap12 {
+		return "http://www.w3.org/2003/05/soap-envelope"
+	}
+	return "http://schemas.xmlsoap.org/soap/envelope/"
+}
+
+// contentType returns the response content type for the SOAP version
+func contentType(soap12 bool) string {
+	if soap12 {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "text/xml; charset=utf-8"
+}
+
+// writeFault writes a SOAP server fault with HTTP 500, as VIES does
+func writeFault(w http.ResponseWriter, soap12 bool, fault string) {
+	w.Header().Set("Content-Type", contentType(soap12))
+	w.WriteHeader(http.StatusInternalServerError)
+	if soap12 {
+		fmt.Fprintf(w, `<env:Envelope xmlns:env="%s"><env:Body><env:Fault><env:Code><env:Value>env:Receiver</env:Value></env:Code><env:Reason><env:Text xml:lang="en">%s</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`, envelopeNamespace(true), fault)
+		return
+	}
+	fmt.Fprintf(w, `<env:Envelope xmlns:env="%s"><env:Body><env:Fault><faultcode>soap:Server</faultcode><faultstring>%s</faultstring></env:Fault></env:Body></env:Envelope>`, envelopeNamespace(false), fault)
+}
@@ -0,0 +1,170 @@
+// Package viesmock provides a programmable fake vies.Checker for unit tests
+// that must not depend on the VIES service. Its Handler serves the same
+// canned results over SOAP for tests of a real vies.Client.
+package viesmock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Checker is a fake vies.Checker. Results and errors are keyed by the
+// normalized VAT number (country prefix plus number, e.g. "DE123456789").
+// Numbers without a canned result are reported as invalid.
+type Checker struct {
+	mu      sync.Mutex
+	results map[string]*vies.CheckVatResult
+	errors  map[string]error
+	err     error
+	latency time.Duration
+	calls   []string
+	bodies  []string // SOAP requests received by Handler
+}
+
+var _ vies.Checker = (*Checker)(nil)
+
+// New creates an empty fake checker
+func New() *Checker {
+	return &Checker{
+		results: make(map[string]*vies.CheckVatResult),
+		errors:  make(map[string]error),
+	}
+}
+
+// SetResult returns result for vatNumber. CountryCode and VatNumber are
+// filled in from vatNumber when empty, RequestDate defaults to today and
+// RawRequestDate to RequestDate as an xsd:date.
+func (m *Checker) SetResult(vatNumber string, result *vies.CheckVatResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[normalize(vatNumber)] = result
+}
+
+// SetValid is a shorthand for SetResult with a valid result
+func (m *Checker) SetValid(vatNumber, name, address string) {
+	m.SetResult(vatNumber, &vies.CheckVatResult{Valid: true, Name: name, Address: address})
+}
+
+// SetError returns err for vatNumber
+func (m *Checker) SetError(vatNumber string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[normalize(vatNumber)] = err
+}
+
+// FailAll returns err for every call until it is cleared with FailAll(nil)
+func (m *Checker) FailAll(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// SetLatency delays every call by d, or until the context is done
+func (m *Checker) SetLatency(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency = d
+}
+
+// Calls returns the VAT numbers checked so far, in call order
+func (m *Checker) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// CheckVAT implements vies.Checker. Format validation behaves like the real
+// client, so malformed numbers yield a *vies.ValidationError.
+func (m *Checker) CheckVAT(ctx context.Context, vatNumber string, options ...vies.RequestOption) (*vies.CheckVatResult, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, vatNumber)
+	latency := m.latency
+	m.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, contextError(ctx, vatNumber)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, contextError(ctx, vatNumber)
+	}
+
+	countryCode, number, err := vies.ParseVATNumber(vatNumber)
+	if err != nil {
+		return nil, err
+	}
+	key := countryCode + number
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	if err, ok := m.errors[key]; ok {
+		return nil, err
+	}
+
+	result := &vies.CheckVatResult{}
+	if canned, ok := m.results[key]; ok {
+		*result = *canned
+	}
+	if result.CountryCode == "" {
+		result.CountryCode = countryCode
+	}
+	if result.VatNumber == "" {
+		result.VatNumber = number
+	}
+	if result.NormalizedVATNumber == "" {
+		result.NormalizedVATNumber = vies.NormalizeInput(vatNumber)
+	}
+	if result.CanonicalVATNumber == "" {
+		result.CanonicalVATNumber = key
+	}
+	if result.Address != "" && result.AddressCountryCode == "" {
+		result.AddressCountryCode, result.AddressCountry, _ = vies.ISOCountry(countryCode)
+	}
+	if result.RequestDate.IsZero() {
+		now := time.Now().UTC()
+		result.RequestDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if result.RawRequestDate == "" {
+		result.RawRequestDate = result.RequestDate.Format("2006-01-02Z07:00") // xsd:date
+	}
+	return result, nil
+}
+
+// normalize returns the key used for canned results and errors
+func normalize(vatNumber string) string {
+	countryCode, number, err := vies.ParseVATNumber(vatNumber)
+	if err != nil {
+		return vatNumber
+	}
+	return countryCode + number
+}
+
+// contextError maps a cancelled call to the error the real client returns
+func contextError(ctx context.Context, vatNumber string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &vies.ServiceError{
+			Code:      vies.CodeNetworkTimeout,
+			Message:   "Request timeout exceeded",
+			VATNumber: vatNumber,
+			Err:       ctx.Err(),
+		}
+	}
+	return &vies.ServiceError{
+		Code:      vies.CodeServiceError,
+		Message:   "Request cancelled",
+		VATNumber: vatNumber,
+		Err:       ctx.Err(),
+	}
+}
@@ -0,0 +1,75 @@
+package viesmock
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestCheckerCannedResults(t *testing.T) {
+	m := New()
+	m.SetValid("DE 266201128", "Example GmbH", "Berlin")
+	m.SetError("FR12345678901", &vies.ServiceError{Code: vies.CodeServiceUnavailable, Message: "down"})
+
+	result, err := m.CheckVAT(context.Background(), "de266201128")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || result.Name != "Example GmbH" || result.CountryCode != "DE" || result.VatNumber != "266201128" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := m.CheckVAT(context.Background(), "FR12345678901"); !errors.Is(err, vies.ErrServiceUnavailable) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+
+	result, err = m.CheckVAT(context.Background(), "NL004495445B01")
+	if err != nil || result.Valid {
+		t.Errorf("expected invalid result for unknown number, got %+v, %v", result, err)
+	}
+
+	if _, err := m.CheckVAT(context.Background(), "DE123"); !errors.Is(err, vies.ErrInvalidFormat) {
+		t.Errorf("expected format error, got %v", err)
+	}
+
+	if got := len(m.Calls()); got != 4 {
+		t.Errorf("expected 4 recorded calls, got %d", got)
+	}
+}
+
+func TestCheckerLatencyHonoursContext(t *testing.T) {
+	m := New()
+	m.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.CheckVAT(ctx, "DE266201128"); !errors.Is(err, vies.ErrNetworkTimeout) {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestCheckerHandler(t *testing.T) {
+	m := New()
+	m.SetValid("DE266201128", "Example & Co GmbH", "Berlin")
+	m.SetError("FR40303265045", &vies.ServiceError{Code: vies.CodeSOAPFault, FaultCode: "MS_UNAVAILABLE"})
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+	client := vies.NewClient(vies.WithEndpoint(srv.URL))
+
+	result, err := client.CheckVAT(context.Background(), "DE266201128")
+	if err != nil || !result.Valid || result.Name != "Example & Co GmbH" {
+		t.Errorf("CheckVAT() = %+v, %v", result, err)
+	}
+	var serviceErr *vies.ServiceError
+	if _, err := client.CheckVAT(context.Background(), "FR40303265045"); !errors.As(err, &serviceErr) || serviceErr.FaultCode != "MS_UNAVAILABLE" {
+		t.Errorf("expected the MS_UNAVAILABLE fault, got %v", err)
+	}
+	if bodies := m.Bodies(); len(bodies) != 2 || !strings.Contains(bodies[0], "266201128") {
+		t.Errorf("Bodies() = %q", bodies)
+	}
+}
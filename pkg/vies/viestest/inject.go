@@ -0,0 +1,86 @@
+package viestest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Injection configures failures injected into the server's answers, on top
+// of the programmed results, so consumers can test their retry, timeout and
+// circuit-breaker logic end to end. The zero value injects nothing.
+type Injection struct {
+	// Latency delays every answer; Jitter adds a random extra delay of up
+	// to its value
+	Latency time.Duration
+	Jitter  time.Duration
+	// FaultRate is the probability, from 0 to 1, of answering a request
+	// with Fault instead of its programmed answer
+	FaultRate float64
+	// Fault is the injected fault; FaultServiceUnavailable if empty
+	Fault string
+	// MalformedRate is the probability of answering with a truncated XML
+	// document and HTTP 200
+	MalformedRate float64
+	// MaxConcurrent, if positive, answers requests arriving while this many
+	// are already in progress with GLOBAL_MAX_CONCURRENT_REQ, as VIES does
+	// when it throttles
+	MaxConcurrent int
+	// Seed makes the random choices reproducible; 0 picks a random seed
+	Seed uint64
+}
+
+// Inject replaces the failures injected into subsequent requests
+func (s *Server) Inject(injection Injection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injection = injection
+	seed := injection.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	s.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// injected is the failure chosen for one request
+type injected struct {
+	delay     time.Duration
+	fault     string
+	malformed bool
+}
+
+// chooseInjection decides the injected failure of a request, given the
+// number of requests in progress including it. The caller holds s.mu.
+func (s *Server) chooseInjection(inFlight int) injected {
+	in := s.injection
+	var choice injected
+	if s.rng == nil {
+		return choice
+	}
+	choice.delay = in.Latency
+	if in.Jitter > 0 {
+		choice.delay += time.Duration(s.rng.Int64N(int64(in.Jitter) + 1))
+	}
+	if in.MaxConcurrent > 0 && inFlight > in.MaxConcurrent {
+		choice.fault = FaultGlobalMaxConcurrentReq
+		return choice
+	}
+	switch p := s.rng.Float64(); {
+	case p < in.MalformedRate:
+		choice.malformed = true
+	case p < in.MalformedRate+in.FaultRate:
+		choice.fault = in.Fault
+		if choice.fault == "" {
+			choice.fault = FaultServiceUnavailable
+		}
+	}
+	return choice
+}
+
+// writeMalformed writes a response that breaks off in the middle of the
+// checkVat answer
+func writeMalformed(w http.ResponseWriter, soap12 bool) {
+	w.Header().Set("Content-Type", contentType(soap12))
+	fmt.Fprintf(w, `<env:Envelope xmlns:env="%s"><env:Body><ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types"><ns2:countryCode>`, envelopeNamespace(soap12))
+}
@@ -0,0 +1,77 @@
+package viestest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestServerInjection(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", Result{Valid: true})
+	client := vies.NewClient(vies.WithEndpoint(srv.URL))
+	ctx := context.Background()
+
+	srv.Inject(Injection{Latency: 50 * time.Millisecond})
+	start := time.Now()
+	if _, err := client.CheckVAT(ctx, "DE266201128"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("answer took %s, want at least the injected latency", elapsed)
+	}
+
+	srv.Inject(Injection{FaultRate: 1, Fault: FaultMSUnavailable})
+	var serviceErr *vies.ServiceError
+	if _, err := client.CheckVAT(ctx, "DE266201128"); !errors.As(err, &serviceErr) || serviceErr.FaultCode != FaultMSUnavailable {
+		t.Errorf("expected the injected MS_UNAVAILABLE fault, got %v", err)
+	}
+
+	srv.Inject(Injection{MalformedRate: 1})
+	if _, err := client.CheckVAT(ctx, "DE266201128"); !errors.As(err, &serviceErr) || serviceErr.Code != vies.CodeServiceError {
+		t.Errorf("expected a parse error for the malformed answer, got %v", err)
+	}
+
+	// Half of the requests fail, reproducibly for a given seed
+	failures := func() int {
+		srv.Inject(Injection{FaultRate: 0.5, Seed: 42})
+		n := 0
+		for i := 0; i < 20; i++ {
+			if _, err := client.CheckVAT(ctx, "DE266201128"); err != nil {
+				n++
+			}
+		}
+		return n
+	}
+	first := failures()
+	if first == 0 || first == 20 || failures() != first {
+		t.Errorf("expected a reproducible share of failures, got %d", first)
+	}
+
+	// A second request arriving while one is in progress is throttled
+	srv.Inject(Injection{Latency: 100 * time.Millisecond, MaxConcurrent: 1})
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = client.CheckVAT(ctx, "DE266201128")
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	wg.Wait()
+	if errs[0] != nil || !errors.As(errs[1], &serviceErr) || serviceErr.FaultCode != FaultGlobalMaxConcurrentReq {
+		t.Errorf("expected the second request to be throttled, got %v and %v", errs[0], errs[1])
+	}
+
+	srv.Inject(Injection{})
+	if _, err := client.CheckVAT(ctx, "DE266201128"); err != nil {
+		t.Errorf("expected no injected failures after reset, got %v", err)
+	}
+}
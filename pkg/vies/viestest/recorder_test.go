@@ -0,0 +1,40 @@
+package viestest
+
+import (
+	"context"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestRecorderRecordsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := NewServer()
+	srv.SetResult("DE266201128", Result{Valid: true, Name: "Example GmbH"})
+	recording := vies.NewClient(
+		vies.WithEndpoint(srv.URL),
+		vies.WithTransport(NewRecorder(dir, ModeRecord, nil)),
+	)
+	if _, err := recording.CheckVAT(context.Background(), "DE266201128"); err != nil {
+		t.Fatalf("recording failed: %v", err)
+	}
+	srv.Close()
+
+	// The server is gone; the same request must be served from the fixture
+	replaying := vies.NewClient(
+		vies.WithEndpoint(srv.URL),
+		vies.WithTransport(NewRecorder(dir, ModeReplay, nil)),
+	)
+	result, err := replaying.CheckVAT(context.Background(), "DE266201128")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if !result.Valid || result.Name != "Example GmbH" {
+		t.Errorf("unexpected replayed result: %+v", result)
+	}
+
+	if _, err := replaying.CheckVAT(context.Background(), "DE136695976"); err == nil {
+		t.Error("expected an error for a request without fixture")
+	}
+}
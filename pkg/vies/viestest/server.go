@@ -0,0 +1,304 @@
+// Package viestest provides an httptest-based fake VIES SOAP service for
+// integration tests of code built on the vies client.
+//
+// The server understands checkVat and checkVatApprox requests. Besides
+// programmed results and faults it answers the numbers of the official VIES
+// test service (checkVatTestService), matched on the number part alone:
+//
+//	100 valid                  200 invalid
+//	201 INVALID_INPUT          202 INVALID_REQUESTER_INFO
+//	300 SERVICE_UNAVAILABLE    301 MS_UNAVAILABLE
+//	302 TIMEOUT                400 VAT_BLOCKED
+//	401 IP_BLOCKED             500 GLOBAL_MAX_CONCURRENT_REQ
+//	501 GLOBAL_MAX_CONCURRENT_REQ_TIME
+//	600 MS_MAX_CONCURRENT_REQ  601 MS_MAX_CONCURRENT_REQ_TIME
+//
+// Any other number is reported as invalid. Inject adds latency, random
+// faults, malformed responses and throttling on top.
+//
+// Recorder complements the server with record/replay of live exchanges.
+package viestest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+// Fault identifiers returned by VIES in the SOAP faultstring
+const (
+	FaultInvalidInput               = "INVALID_INPUT"
+	FaultInvalidRequesterInfo       = "INVALID_REQUESTER_INFO"
+	FaultServiceUnavailable         = "SERVICE_UNAVAILABLE"
+	FaultMSUnavailable              = "MS_UNAVAILABLE"
+	FaultTimeout                    = "TIMEOUT"
+	FaultVATBlocked                 = "VAT_BLOCKED"
+	FaultIPBlocked                  = "IP_BLOCKED"
+	FaultGlobalMaxConcurrentReq     = "GLOBAL_MAX_CONCURRENT_REQ"
+	FaultGlobalMaxConcurrentReqTime = "GLOBAL_MAX_CONCURRENT_REQ_TIME"
+	FaultMSMaxConcurrentReq         = "MS_MAX_CONCURRENT_REQ"
+	FaultMSMaxConcurrentReqTime     = "MS_MAX_CONCURRENT_REQ_TIME"
+)
+
+// Result is a programmed checkVat answer
+type Result struct {
+	Valid   bool
+	Name    string
+	Address string
+}
+
+// Request records a request received by the server
+type Request struct {
+	Operation            string // checkVat or checkVatApprox
+	CountryCode          string
+	VatNumber            string
+	RequesterCountryCode string
+	RequesterVatNumber   string
+}
+
+// Server is a fake VIES SOAP endpoint. Pass Server.URL to vies.WithEndpoint.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	results   map[string]Result
+	faults    map[string]string
+	requests  []Request
+	consulted int
+	inFlight  int
+	injection Injection
+	rng       *rand.Rand // set by Inject
+}
+
+// NewServer starts a fake VIES server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		results: make(map[string]Result),
+		faults:  make(map[string]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SetResult programs the answer for a VAT number given as country code plus
+// number, e.g. "DE123456789"
+func (s *Server) SetResult(vatNumber string, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key(vatNumber)] = result
+}
+
+// SetFault makes requests for vatNumber fail with the given fault, e.g.
+// FaultMSUnavailable
+func (s *Server) SetFault(vatNumber, fault string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[key(vatNumber)] = fault
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// soapRequest matches checkVat and checkVatApprox bodies regardless of prefix
+type soapRequest struct {
+	Body struct {
+		Operations []struct {
+			XMLName              xml.Name
+			CountryCode          string `xml:"countryCode"`
+			VatNumber            string `xml:"vatNumber"`
+			RequesterCountryCode string `xml:"requesterCountryCode"`
+			RequesterVatNumber   string `xml:"requesterVatNumber"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	s.inFlight++
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	// SOAP 1.2 clients get SOAP 1.2 responses
+	soap12 := strings.HasPrefix(r.Header.Get("Content-Type"), "application/soap+xml")
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFault(w, soap12, false, FaultInvalidInput)
+		return
+	}
+
+	// Reject envelopes that do not conform to the checkVat schema, so
+	// integration tests catch namespace regressions in the client
+	var envelope soapRequest
+	if err := vies.ValidateEnvelope(body); err != nil {
+		writeFault(w, soap12, false, FaultInvalidInput)
+		return
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil || len(envelope.Body.Operations) != 1 {
+		writeFault(w, soap12, false, FaultInvalidInput)
+		return
+	}
+	op := envelope.Body.Operations[0]
+	req := Request{
+		Operation:            op.XMLName.Local,
+		CountryCode:          strings.TrimSpace(op.CountryCode),
+		VatNumber:            strings.TrimSpace(op.VatNumber),
+		RequesterCountryCode: strings.TrimSpace(op.RequesterCountryCode),
+		RequesterVatNumber:   strings.TrimSpace(op.RequesterVatNumber),
+	}
+	if req.Operation != "checkVat" && req.Operation != "checkVatApprox" {
+		writeFault(w, soap12, false, FaultInvalidInput)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	fault, hasFault := s.faults[req.CountryCode+req.VatNumber]
+	result, hasResult := s.results[req.CountryCode+req.VatNumber]
+	if !hasFault && !hasResult {
+		if tn, ok := vies.LookupTestNumber(req.VatNumber); ok {
+			fault, hasFault = tn.Fault, tn.Fault != ""
+			result.Valid = tn.Valid
+		}
+	}
+	if req.Operation == "checkVatApprox" && !hasFault && (req.RequesterCountryCode == "" || req.RequesterVatNumber == "") {
+		fault, hasFault = FaultInvalidRequesterInfo, true
+	}
+	s.consulted++
+	identifier := fmt.Sprintf("WAPIAAAA%08d", s.consulted)
+	injection := s.chooseInjection(s.inFlight)
+	s.mu.Unlock()
+
+	if injection.delay > 0 {
+		timer := time.NewTimer(injection.delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+	if injection.malformed {
+		writeMalformed(w, soap12)
+		return
+	}
+	if injection.fault != "" {
+		fault, hasFault = injection.fault, true
+	}
+
+	if req.CountryCode == "" || req.VatNumber == "" {
+		fault, hasFault = FaultInvalidInput, true
+	}
+	if hasFault {
+		writeFault(w, soap12, true, fault)
+		return
+	}
+
+	date := time.Now().UTC().Format("2006-01-02") + "+01:00"
+	var b strings.Builder
+	fmt.Fprintf(&b, `<env:Envelope xmlns:env="%s"><env:Header/><env:Body>`, envelopeNamespace(soap12))
+	if req.Operation == "checkVatApprox" {
+		b.WriteString(`<ns2:checkVatApproxResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+		writeCommon(&b, req, date, result.Valid)
+		writeElement(&b, "traderName", result.Name)
+		writeElement(&b, "traderAddress", result.Address)
+		writeElement(&b, "requestIdentifier", identifier)
+		b.WriteString(`</ns2:checkVatApproxResponse>`)
+	} else {
+		b.WriteString(`<ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+		writeCommon(&b, req, date, result.Valid)
+		writeElement(&b, "name", orDashes(result.Name))
+		writeElement(&b, "address", orDashes(result.Address))
+		b.WriteString(`</ns2:checkVatResponse>`)
+	}
+	b.WriteString(`</env:Body></env:Envelope>`)
+
+	w.Header().Set("Content-Type", contentType(soap12))
+	io.WriteString(w, b.String())
+}
+
+// writeCommon writes the elements shared by checkVat and checkVatApprox responses
+func writeCommon(b *strings.Builder, req Request, date string, valid bool) {
+	writeElement(b, "countryCode", req.CountryCode)
+	writeElement(b, "vatNumber", req.VatNumber)
+	writeElement(b, "requestDate", date)
+	writeElement(b, "valid", fmt.Sprintf("%t", valid))
+}
+
+func writeElement(b *strings.Builder, name, value string) {
+	fmt.Fprintf(b, "<ns2:%s>", name)
+	xml.EscapeText(b, []byte(value))
+	fmt.Fprintf(b, "</ns2:%s>", name)
+}
+
+// orDashes mirrors VIES, which reports undisclosed trader data as "---"
+func orDashes(s string) string {
+	if s == "" {
+		return "---"
+	}
+	return s
+}
+
+// envelopeNamespace returns the SOAP envelope namespace for the version
+func envelopeNamespace(soap12 bool) string {
+	if soap12 {
+		return "http://www.w3.org/2003/05/soap-envelope"
+	}
+	return "http://schemas.xmlsoap.org/soap/envelope/"
+}
+
+// contentType returns the response content type for the SOAP version
+func contentType(soap12 bool) string {
+	if soap12 {
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "text/xml; charset=utf-8"
+}
+
+// writeFault writes a SOAP fault with HTTP 500, as VIES does. server
+// selects the Server (SOAP 1.2: Receiver) fault code over Client (Sender).
+func writeFault(w http.ResponseWriter, soap12, server bool, fault string) {
+	w.Header().Set("Content-Type", contentType(soap12))
+	w.WriteHeader(http.StatusInternalServerError)
+	if soap12 {
+		code := "env:Sender"
+		if server {
+			code = "env:Receiver"
+		}
+		fmt.Fprintf(w, `<env:Envelope xmlns:env="%s"><env:Header/><env:Body><env:Fault><env:Code><env:Value>%s</env:Value></env:Code><env:Reason><env:Text xml:lang="en">%s</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`, envelopeNamespace(true), code, fault)
+		return
+	}
+	code := "soap:Client"
+	if server {
+		code = "soap:Server"
+	}
+	fmt.Fprintf(w, `<env:Envelope xmlns:env="%s"><env:Header/><env:Body><env:Fault><faultcode>%s</faultcode><faultstring>%s</faultstring></env:Fault></env:Body></env:Envelope>`, envelopeNamespace(false), code, fault)
+}
+
+// key normalizes a programmed VAT number to the country code and number
+// the client sends, falling back to a plain cleanup for malformed input
+func key(vatNumber string) string {
+	if countryCode, number, err := vies.ParseVATNumber(vatNumber); err == nil {
+		return countryCode + number
+	}
+	return strings.ToUpper(strings.ReplaceAll(vatNumber, " ", ""))
+}
@@ -0,0 +1,99 @@
+package viestest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"l22.io/viesquery/pkg/vies"
+)
+
+func TestServerWithClient(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", Result{Valid: true, Name: "Example GmbH", Address: "Berlin"})
+	srv.SetFault("FR12345678901", FaultMSUnavailable)
+
+	client := vies.NewClient(vies.WithEndpoint(srv.URL))
+
+	result, err := client.CheckVAT(context.Background(), "DE266201128")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || result.Name != "Example GmbH" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if want := result.RequestDate.Format("2006-01-02") + "+01:00"; result.RawRequestDate != want {
+		t.Errorf("RawRequestDate = %q, want %q as sent by the server", result.RawRequestDate, want)
+	}
+
+	_, err = client.CheckVAT(context.Background(), "FR12345678901")
+	var serviceErr *vies.ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.FaultCode != FaultMSUnavailable || serviceErr.HTTPStatus != 500 {
+		t.Errorf("expected MS_UNAVAILABLE fault, got %v", err)
+	}
+
+	result, err = client.CheckVAT(context.Background(), "DE266201128", vies.WithRequester("DE136695976"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequestIdentifier == "" {
+		t.Error("expected a consultation number for checkVatApprox")
+	}
+
+	if got := len(srv.Requests()); got != 3 {
+		t.Errorf("expected 3 recorded requests, got %d", got)
+	}
+}
+
+func TestServerTestNumbers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := vies.NewClient(vies.WithTestService(), vies.WithEndpoint(srv.URL))
+
+	result, err := client.CheckVAT(context.Background(), "DE100")
+	if err != nil || !result.Valid || !strings.HasPrefix(result.TestScenario, "100:") {
+		t.Errorf("expected DE100 to be a labeled valid result, got %+v, %v", result, err)
+	}
+
+	_, err = client.CheckVAT(context.Background(), "FR301")
+	var serviceErr *vies.ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.FaultCode != FaultMSUnavailable || !strings.HasPrefix(serviceErr.TestScenario, "301:") {
+		t.Errorf("expected MS_UNAVAILABLE fault for FR301, got %v", err)
+	}
+
+	// Outside test mode the numbers fail format validation
+	if _, err := vies.NewClient(vies.WithEndpoint(srv.URL)).CheckVAT(context.Background(), "DE100"); !errors.Is(err, vies.ErrInvalidFormat) {
+		t.Errorf("expected format error without test mode, got %v", err)
+	}
+}
+
+func TestServerSOAP12(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SetResult("DE266201128", Result{Valid: true})
+	srv.SetFault("FR12345678901", FaultMSUnavailable)
+
+	client := vies.NewClient(vies.WithEndpoint(srv.URL), vies.WithSOAPVersion(vies.SOAP12))
+
+	req, err := client.NewRequest(context.Background(), "DE266201128")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ct := req.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/soap+xml") || req.Header.Get("SOAPAction") != "" {
+		t.Errorf("unexpected SOAP 1.2 headers: %v", req.Header)
+	}
+
+	result, err := client.CheckVAT(context.Background(), "DE266201128")
+	if err != nil || !result.Valid {
+		t.Errorf("expected a valid result over SOAP 1.2, got %+v, %v", result, err)
+	}
+
+	_, err = client.CheckVAT(context.Background(), "FR12345678901")
+	var serviceErr *vies.ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.FaultCode != FaultMSUnavailable {
+		t.Errorf("expected MS_UNAVAILABLE fault, got %v", err)
+	}
+}
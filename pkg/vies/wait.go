@@ -0,0 +1,118 @@
+package vies
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// unavailableFaults are the VIES faults signalling a temporary outage of
+// VIES or of a member state service, e.g. during evening maintenance
+var unavailableFaults = map[string]bool{
+	"SERVICE_UNAVAILABLE": true,
+	"MS_UNAVAILABLE":      true,
+}
+
+// Backoff between attempts of a ServiceWaiter: it starts at
+// waitInitialBackoff and doubles up to waitMaxBackoff
+var (
+	waitInitialBackoff = 30 * time.Second
+	waitMaxBackoff     = 10 * time.Minute
+)
+
+// isUnavailable reports whether err means VIES or the member state could
+// not be consulted right now
+func isUnavailable(err error) bool {
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) {
+		return false
+	}
+	return serviceErr.Code == CodeServiceUnavailable || unavailableFaults[serviceErr.FaultCode]
+}
+
+// ServiceWaiter is a Checker that rides out VIES outages: while VIES or the
+// member state service is unavailable it retries with exponential backoff
+// (30s, doubling up to 10m) until MaxWait has passed, MaxRetries retries
+// were made, or the next wait would outlast the context's deadline, then
+// returns the last error. Other errors are returned immediately.
+type ServiceWaiter struct {
+	Checker Checker
+	// MaxWait and MaxRetries bound the retries; zero means no limit
+	MaxWait    time.Duration
+	MaxRetries int
+	// InitialBackoff and MaxBackoff replace the default backoff of 30s,
+	// doubling up to 10m, when set
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Maintenance, if set, makes the waiter retry once a known maintenance
+	// window of the member state has ended, instead of probing it with
+	// backoff
+	Maintenance MaintenanceSchedule
+	// OnRetry, if set, is called before each wait with the error that
+	// caused it
+	OnRetry func(err error, delay time.Duration)
+}
+
+var _ Checker = (*ServiceWaiter)(nil)
+
+// CheckVAT implements Checker
+func (w *ServiceWaiter) CheckVAT(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+	deadline := time.Now().Add(w.MaxWait)
+	delay, maxDelay := waitInitialBackoff, waitMaxBackoff
+	if w.InitialBackoff > 0 {
+		delay = w.InitialBackoff
+	}
+	if w.MaxBackoff > 0 {
+		maxDelay = w.MaxBackoff
+	}
+	delay = min(delay, maxDelay)
+	for retries := 0; ; retries++ {
+		result, err := w.Checker.CheckVAT(ctx, vatNumber, options...)
+		if err == nil || !isUnavailable(err) {
+			return result, err
+		}
+		if w.MaxRetries > 0 && retries >= w.MaxRetries {
+			return nil, err
+		}
+		wait := delay
+		if end, ok := w.Maintenance.Active(w.maintenanceCountry(vatNumber), time.Now()); ok {
+			wait = max(time.Until(end), delay)
+		}
+		if w.MaxWait > 0 && time.Now().Add(wait).After(deadline) {
+			return nil, err
+		}
+		if ctxDeadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(ctxDeadline) {
+			return nil, err // the context would expire during the wait
+		}
+		if w.OnRetry != nil {
+			w.OnRetry(err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// maintenanceCountry returns the member state a VAT number is checked with.
+// A Checker that resolves it itself, like Client with a default country,
+// is asked; otherwise the input's country prefix is used.
+func (w *ServiceWaiter) maintenanceCountry(vatNumber string) string {
+	if resolver, ok := w.Checker.(interface {
+		CountryCode(vatNumber string) (string, error)
+	}); ok {
+		if country, err := resolver.CountryCode(vatNumber); err == nil {
+			return country
+		}
+	}
+	normalized := NormalizeInput(vatNumber)
+	if len(normalized) < 2 {
+		return ""
+	}
+	return normalized[:2]
+}
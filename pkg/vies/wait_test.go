@@ -0,0 +1,112 @@
+package vies
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// checkerFunc adapts a function to the Checker interface
+type checkerFunc func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error)
+
+func (f checkerFunc) CheckVAT(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+	return f(ctx, vatNumber, options...)
+}
+
+func TestServiceWaiter(t *testing.T) {
+	defer func(initial, max time.Duration) {
+		waitInitialBackoff, waitMaxBackoff = initial, max
+	}(waitInitialBackoff, waitMaxBackoff)
+	waitInitialBackoff, waitMaxBackoff = time.Millisecond, 2*time.Millisecond
+
+	unavailable := &ServiceError{Code: CodeSOAPFault, FaultCode: "MS_UNAVAILABLE"}
+	calls := 0
+	flaky := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		calls++
+		if calls < 3 {
+			return nil, unavailable
+		}
+		return &CheckVatResult{Valid: true}, nil
+	})
+
+	var retries []time.Duration
+	waiter := &ServiceWaiter{Checker: flaky, MaxWait: time.Minute, OnRetry: func(err error, delay time.Duration) {
+		retries = append(retries, delay)
+	}}
+	result, err := waiter.CheckVAT(context.Background(), "DE266201128")
+	if err != nil || !result.Valid {
+		t.Fatalf("CheckVAT() = %+v, %v; want valid result", result, err)
+	}
+	if len(retries) != 2 || retries[0] != time.Millisecond || retries[1] != 2*time.Millisecond {
+		t.Errorf("retry delays = %v, want [1ms 2ms]", retries)
+	}
+
+	// Past the deadline the outage is reported
+	calls = -100
+	waiter = &ServiceWaiter{Checker: flaky, MaxWait: 5 * time.Millisecond}
+	if _, err := waiter.CheckVAT(context.Background(), "DE266201128"); !errors.Is(err, ErrSOAPFault) {
+		t.Errorf("CheckVAT() error = %v, want the MS_UNAVAILABLE fault", err)
+	}
+
+	// Other errors are not retried
+	calls = 0
+	invalid := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		calls++
+		return nil, &ValidationError{Code: CodeInvalidFormat}
+	})
+	waiter = &ServiceWaiter{Checker: invalid, MaxWait: time.Minute}
+	if _, err := waiter.CheckVAT(context.Background(), "DE1"); !errors.Is(err, ErrInvalidFormat) || calls != 1 {
+		t.Errorf("CheckVAT() = %v after %d calls, want one INVALID_FORMAT", err, calls)
+	}
+}
+
+func TestServiceWaiterContextDeadline(t *testing.T) {
+	calls := 0
+	unavailable := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		calls++
+		return nil, &ServiceError{Code: CodeServiceUnavailable}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The first backoff of 30s would outlast the context, so the waiter
+	// gives up at once instead of sleeping until the context expires
+	start := time.Now()
+	waiter := &ServiceWaiter{Checker: unavailable, MaxWait: time.Hour}
+	if _, err := waiter.CheckVAT(ctx, "DE266201128"); !errors.Is(err, ErrServiceUnavailable) || calls != 1 {
+		t.Errorf("CheckVAT() = %v after %d calls, want SERVICE_UNAVAILABLE after one call", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("CheckVAT() took %s, want an immediate return", elapsed)
+	}
+}
+
+func TestServiceWaiterRetryOptions(t *testing.T) {
+	calls := 0
+	unavailable := checkerFunc(func(ctx context.Context, vatNumber string, options ...RequestOption) (*CheckVatResult, error) {
+		calls++
+		return nil, &ServiceError{Code: CodeServiceUnavailable}
+	})
+
+	var retries []time.Duration
+	waiter := &ServiceWaiter{
+		Checker:        unavailable,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     3 * time.Millisecond,
+		OnRetry: func(err error, delay time.Duration) {
+			retries = append(retries, delay)
+		},
+	}
+	if _, err := waiter.CheckVAT(context.Background(), "DE266201128"); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("CheckVAT() error = %v, want SERVICE_UNAVAILABLE", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 1 attempt and 3 retries, got %d calls", calls)
+	}
+	if want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}; !slices.Equal(retries, want) {
+		t.Errorf("retry delays = %v, want %v", retries, want)
+	}
+}